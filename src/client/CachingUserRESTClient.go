@@ -0,0 +1,275 @@
+package client
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"posts-ms/src/dto/response"
+	"sync"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var userCacheRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "user_cache_requests_total",
+	Help: "Total number of user lookups served by the user cache.",
+}, []string{"result"})
+
+type cachedUser struct {
+	id        int
+	user      response.UserResponseDTO
+	expiresAt time.Time
+}
+
+type userCall struct {
+	done        chan struct{}
+	user        *response.UserResponseDTO
+	err         error
+	invalidated bool
+}
+
+// CachingUserRESTClient keeps recently fetched users in an LRU cache and
+// makes concurrent lookups of the same user share one request to users-ms.
+type CachingUserRESTClient struct {
+	client   IUserRESTClient
+	capacity int
+	ttl      time.Duration
+	timeout  time.Duration
+	now      func() time.Time
+
+	mutex   sync.Mutex
+	entries map[int]*list.Element
+	order   *list.List
+	calls   map[int]*userCall
+}
+
+func NewCachingUserRESTClient(client IUserRESTClient, capacity int, ttl time.Duration, timeout time.Duration) *CachingUserRESTClient {
+	return &CachingUserRESTClient{
+		client:   client,
+		capacity: capacity,
+		ttl:      ttl,
+		timeout:  timeout,
+		now:      time.Now,
+		entries:  map[int]*list.Element{},
+		order:    list.New(),
+		calls:    map[int]*userCall{},
+	}
+}
+
+// GetUser returns a cached user or waits for the shared lookup of it. The
+// lookup does not depend on the context of the caller that started it, so
+// every caller stops waiting only when its own context is done.
+func (c *CachingUserRESTClient) GetUser(id int, ctx context.Context) (*response.UserResponseDTO, error) {
+	c.mutex.Lock()
+
+	if user, ok := c.get(id); ok {
+		c.mutex.Unlock()
+		userCacheRequests.WithLabelValues("hit").Inc()
+
+		return &user, nil
+	}
+
+	call, inFlight := c.calls[id]
+
+	if inFlight {
+		userCacheRequests.WithLabelValues("coalesced").Inc()
+	} else {
+		call = &userCall{done: make(chan struct{})}
+		c.calls[id] = call
+
+		userCacheRequests.WithLabelValues("miss").Inc()
+
+		go c.fetch(id, call, opentracing.SpanFromContext(ctx))
+	}
+
+	c.mutex.Unlock()
+
+	select {
+	case <-call.done:
+		return copyUser(call.user), call.err
+	case <-ctx.Done():
+		return nil, fmt.Errorf("%w: %v", ErrUnavailable, ctx.Err())
+	}
+}
+
+// fetch looks up a user for every caller waiting on call, bounded by the
+// client timeout instead of the context of any single caller.
+func (c *CachingUserRESTClient) fetch(id int, call *userCall, span opentracing.Span) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+
+	defer cancel()
+
+	if span != nil {
+		ctx = opentracing.ContextWithSpan(ctx, span)
+	}
+
+	user, err := c.client.GetUser(id, ctx)
+
+	c.mutex.Lock()
+
+	call.user, call.err = user, err
+
+	delete(c.calls, id)
+
+	if call.err == nil && !call.invalidated {
+		c.set(id, *call.user)
+	}
+
+	c.mutex.Unlock()
+
+	close(call.done)
+}
+
+// GetUsers serves cached users and fetches the rest with one batched request.
+// The fetched ids are registered as in-flight calls, so that lookups of the
+// same users wait for the batch and invalidations during it are not lost.
+func (c *CachingUserRESTClient) GetUsers(ids []int, ctx context.Context) ([]*response.UserResponseDTO, error) {
+	users := []*response.UserResponseDTO{}
+	missing := []int{}
+	calls := map[int]*userCall{}
+	seen := map[int]bool{}
+
+	c.mutex.Lock()
+
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+
+		seen[id] = true
+
+		if user, ok := c.get(id); ok {
+			users = append(users, copyUser(&user))
+
+			continue
+		}
+
+		missing = append(missing, id)
+
+		if _, inFlight := c.calls[id]; !inFlight {
+			calls[id] = &userCall{done: make(chan struct{})}
+			c.calls[id] = calls[id]
+		}
+	}
+
+	c.mutex.Unlock()
+
+	userCacheRequests.WithLabelValues("hit").Add(float64(len(users)))
+
+	if len(missing) == 0 {
+		return users, nil
+	}
+
+	userCacheRequests.WithLabelValues("miss").Add(float64(len(missing)))
+
+	fetched, err := c.client.GetUsers(missing, ctx)
+
+	c.mutex.Lock()
+
+	for _, user := range fetched {
+		if call, ok := calls[user.ID]; ok {
+			call.user = copyUser(user)
+		}
+	}
+
+	for id, call := range calls {
+		delete(c.calls, id)
+
+		switch {
+		case err != nil:
+			call.err = err
+		case call.user == nil:
+			call.err = fmt.Errorf("%w: user %d", ErrNotFound, id)
+		case !call.invalidated:
+			c.set(id, *call.user)
+		}
+
+		close(call.done)
+	}
+
+	c.mutex.Unlock()
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, user := range fetched {
+		users = append(users, copyUser(user))
+	}
+
+	return users, nil
+}
+
+func (c *CachingUserRESTClient) Invalidate(id int) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if element, ok := c.entries[id]; ok {
+		c.order.Remove(element)
+		delete(c.entries, id)
+	}
+
+	if call, ok := c.calls[id]; ok {
+		call.invalidated = true
+	}
+}
+
+func (c *CachingUserRESTClient) HandleUserUpdated(user response.UserUpdatedDto, ctx context.Context) error {
+	c.Invalidate(user.ID)
+
+	return nil
+}
+
+func (c *CachingUserRESTClient) get(id int) (response.UserResponseDTO, bool) {
+	element, ok := c.entries[id]
+
+	if !ok {
+		return response.UserResponseDTO{}, false
+	}
+
+	entry := element.Value.(*cachedUser)
+
+	if c.now().After(entry.expiresAt) {
+		c.order.Remove(element)
+		delete(c.entries, id)
+
+		return response.UserResponseDTO{}, false
+	}
+
+	c.order.MoveToFront(element)
+
+	return entry.user, true
+}
+
+func (c *CachingUserRESTClient) set(id int, user response.UserResponseDTO) {
+	entry := &cachedUser{id: id, user: user, expiresAt: c.now().Add(c.ttl)}
+
+	if element, ok := c.entries[id]; ok {
+		element.Value = entry
+		c.order.MoveToFront(element)
+
+		return
+	}
+
+	c.entries[id] = c.order.PushFront(entry)
+
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cachedUser).id)
+	}
+}
+
+func copyUser(user *response.UserResponseDTO) *response.UserResponseDTO {
+	if user == nil {
+		return nil
+	}
+
+	copied := *user
+
+	return &copied
+}
@@ -0,0 +1,305 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"posts-ms/src/dto/response"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type countingUserRESTClient struct {
+	calls        int32
+	release      chan struct{}
+	batchRelease chan struct{}
+}
+
+func (c *countingUserRESTClient) GetUser(id int, ctx context.Context) (*response.UserResponseDTO, error) {
+	atomic.AddInt32(&c.calls, 1)
+
+	if c.release != nil {
+		<-c.release
+	}
+
+	if id == 503 {
+		return nil, ErrUnavailable
+	}
+
+	return &response.UserResponseDTO{ID: id, Username: "user"}, nil
+}
+
+func (c *countingUserRESTClient) GetUsers(ids []int, ctx context.Context) ([]*response.UserResponseDTO, error) {
+	atomic.AddInt32(&c.calls, 1)
+
+	if c.batchRelease != nil {
+		<-c.batchRelease
+	}
+
+	users := []*response.UserResponseDTO{}
+
+	for _, id := range ids {
+		if id == 503 {
+			return nil, ErrUnavailable
+		}
+
+		users = append(users, &response.UserResponseDTO{ID: id, Username: "user"})
+	}
+
+	return users, nil
+}
+
+type CachingUserRESTClientUnitTestSuite struct {
+	suite.Suite
+	now    time.Time
+	users  *countingUserRESTClient
+	client *CachingUserRESTClient
+}
+
+func TestCachingUserRESTClientUnitTestSuite(t *testing.T) {
+	suite.Run(t, new(CachingUserRESTClientUnitTestSuite))
+}
+
+func (suite *CachingUserRESTClientUnitTestSuite) SetupTest() {
+	suite.now = time.Now()
+	suite.users = &countingUserRESTClient{}
+	suite.client = NewCachingUserRESTClient(suite.users, 2, time.Minute, time.Second)
+	suite.client.now = func() time.Time { return suite.now }
+}
+
+func (suite *CachingUserRESTClientUnitTestSuite) TestCachingUserRESTClient_GetUser_ServesRepeatedLookupsFromCache() {
+	first, _ := suite.client.GetUser(1, context.TODO())
+	second, err := suite.client.GetUser(1, context.TODO())
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+	assert.Equal(suite.T(), int32(1), suite.users.calls, "Number of calls to users-ms is not 1")
+	assert.Equal(suite.T(), first, second, "Cached user is not equal to fetched user")
+
+	second.Username = "changed"
+
+	third, _ := suite.client.GetUser(1, context.TODO())
+
+	assert.Equal(suite.T(), "user", third.Username, "Cached user is modified by caller")
+}
+
+func (suite *CachingUserRESTClientUnitTestSuite) TestCachingUserRESTClient_GetUser_ExpiredEntryIsFetchedAgain() {
+	suite.client.GetUser(1, context.TODO())
+
+	suite.now = suite.now.Add(2 * time.Minute)
+
+	suite.client.GetUser(1, context.TODO())
+
+	assert.Equal(suite.T(), int32(2), suite.users.calls, "Number of calls to users-ms is not 2")
+}
+
+func (suite *CachingUserRESTClientUnitTestSuite) TestCachingUserRESTClient_GetUser_EvictsLeastRecentlyUsed() {
+	suite.client.GetUser(1, context.TODO())
+	suite.client.GetUser(2, context.TODO())
+	suite.client.GetUser(1, context.TODO())
+	suite.client.GetUser(3, context.TODO())
+
+	suite.client.GetUser(1, context.TODO())
+
+	assert.Equal(suite.T(), int32(3), suite.users.calls, "Recently used user is evicted")
+
+	suite.client.GetUser(2, context.TODO())
+
+	assert.Equal(suite.T(), int32(4), suite.users.calls, "Least recently used user is not evicted")
+}
+
+func (suite *CachingUserRESTClientUnitTestSuite) TestCachingUserRESTClient_GetUser_ErrorsAreNotCached() {
+	_, err := suite.client.GetUser(503, context.TODO())
+
+	assert.Equal(suite.T(), ErrUnavailable, err, "Error is not unavailable")
+
+	suite.client.GetUser(503, context.TODO())
+
+	assert.Equal(suite.T(), int32(2), suite.users.calls, "Error is cached")
+}
+
+func (suite *CachingUserRESTClientUnitTestSuite) TestCachingUserRESTClient_GetUser_CoalescesConcurrentLookups() {
+	suite.users.release = make(chan struct{})
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			user, err := suite.client.GetUser(1, context.TODO())
+
+			assert.Nil(suite.T(), err, "Error is not nil")
+			assert.Equal(suite.T(), 1, user.ID, "User id is not 1")
+		}()
+	}
+
+	assert.Eventually(suite.T(), func() bool {
+		suite.client.mutex.Lock()
+		defer suite.client.mutex.Unlock()
+
+		return atomic.LoadInt32(&suite.users.calls) == 1 && len(suite.client.calls) == 1
+	}, time.Second, time.Millisecond, "Lookup is not in flight")
+
+	time.Sleep(10 * time.Millisecond)
+	close(suite.users.release)
+	wg.Wait()
+
+	assert.Equal(suite.T(), int32(1), suite.users.calls, "Concurrent lookups are not coalesced")
+}
+
+func (suite *CachingUserRESTClientUnitTestSuite) TestCachingUserRESTClient_GetUser_WaiterStopsWhenContextIsDone() {
+	suite.users.release = make(chan struct{})
+	leader := make(chan struct{})
+
+	go func() {
+		defer close(leader)
+
+		suite.client.GetUser(1, context.TODO())
+	}()
+
+	assert.Eventually(suite.T(), func() bool {
+		return atomic.LoadInt32(&suite.users.calls) == 1
+	}, time.Second, time.Millisecond, "Lookup is not in flight")
+
+	ctx, cancel := context.WithCancel(context.TODO())
+
+	cancel()
+
+	user, err := suite.client.GetUser(1, ctx)
+
+	assert.Nil(suite.T(), user, "User is not nil")
+	assert.True(suite.T(), errors.Is(err, ErrUnavailable), "Error is not unavailable")
+
+	close(suite.users.release)
+	<-leader
+}
+
+func (suite *CachingUserRESTClientUnitTestSuite) TestCachingUserRESTClient_GetUser_LeaderCancelDoesNotFailWaiters() {
+	suite.users.release = make(chan struct{})
+	ctx, cancel := context.WithCancel(context.TODO())
+	leader := make(chan error)
+
+	go func() {
+		_, err := suite.client.GetUser(1, ctx)
+
+		leader <- err
+	}()
+
+	assert.Eventually(suite.T(), func() bool {
+		return atomic.LoadInt32(&suite.users.calls) == 1
+	}, time.Second, time.Millisecond, "Lookup is not in flight")
+
+	waiter := make(chan *response.UserResponseDTO)
+
+	go func() {
+		user, _ := suite.client.GetUser(1, context.TODO())
+
+		waiter <- user
+	}()
+
+	cancel()
+
+	assert.True(suite.T(), errors.Is(<-leader, ErrUnavailable), "Leader does not stop when its context is done")
+
+	close(suite.users.release)
+
+	user := <-waiter
+
+	assert.NotNil(suite.T(), user, "Waiter fails with the context of the leader")
+	assert.Equal(suite.T(), int32(1), suite.users.calls, "Lookup is started again")
+}
+
+func (suite *CachingUserRESTClientUnitTestSuite) TestCachingUserRESTClient_HandleUserUpdated_DecodesPublishedId() {
+	var user response.UserUpdatedDto
+
+	json.Unmarshal([]byte(`{"ID":7,"Username":"renamed"}`), &user)
+
+	assert.Equal(suite.T(), 7, user.ID, "User id is not decoded")
+}
+
+func (suite *CachingUserRESTClientUnitTestSuite) TestCachingUserRESTClient_HandleUserUpdated_InvalidatesUser() {
+	suite.client.GetUser(1, context.TODO())
+
+	err := suite.client.HandleUserUpdated(response.UserUpdatedDto{ID: 1}, context.TODO())
+
+	suite.client.GetUser(1, context.TODO())
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+	assert.Equal(suite.T(), int32(2), suite.users.calls, "User is not invalidated")
+}
+
+func (suite *CachingUserRESTClientUnitTestSuite) TestCachingUserRESTClient_GetUsers_FetchesOnlyMissingUsers() {
+	suite.client.GetUser(1, context.TODO())
+
+	users, err := suite.client.GetUsers([]int{1, 2, 2}, context.TODO())
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+	assert.Equal(suite.T(), 2, len(users), "Number of users is not 2")
+	assert.Equal(suite.T(), int32(2), suite.users.calls, "Number of calls to users-ms is not 2")
+
+	suite.client.GetUsers([]int{1, 2}, context.TODO())
+
+	assert.Equal(suite.T(), int32(2), suite.users.calls, "Cached users are fetched again")
+}
+
+func (suite *CachingUserRESTClientUnitTestSuite) TestCachingUserRESTClient_GetUsers_InvalidationDuringFetchIsKept() {
+	suite.users.batchRelease = make(chan struct{})
+	batch := make(chan struct{})
+
+	go func() {
+		defer close(batch)
+
+		suite.client.GetUsers([]int{1, 2}, context.TODO())
+	}()
+
+	assert.Eventually(suite.T(), func() bool {
+		return atomic.LoadInt32(&suite.users.calls) == 1
+	}, time.Second, time.Millisecond, "Batch is not in flight")
+
+	suite.client.Invalidate(1)
+
+	close(suite.users.batchRelease)
+	<-batch
+
+	suite.client.GetUsers([]int{1, 2}, context.TODO())
+
+	assert.Equal(suite.T(), int32(2), suite.users.calls, "Invalidated user is cached")
+
+	suite.client.GetUser(2, context.TODO())
+
+	assert.Equal(suite.T(), int32(2), suite.users.calls, "User fetched in batch is not cached")
+}
+
+func (suite *CachingUserRESTClientUnitTestSuite) TestCachingUserRESTClient_GetUser_WaitsForBatchFetch() {
+	suite.users.batchRelease = make(chan struct{})
+	batch := make(chan struct{})
+
+	go func() {
+		defer close(batch)
+
+		suite.client.GetUsers([]int{1}, context.TODO())
+	}()
+
+	assert.Eventually(suite.T(), func() bool {
+		return atomic.LoadInt32(&suite.users.calls) == 1
+	}, time.Second, time.Millisecond, "Batch is not in flight")
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		close(suite.users.batchRelease)
+	}()
+
+	user, err := suite.client.GetUser(1, context.TODO())
+	<-batch
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+	assert.Equal(suite.T(), 1, user.ID, "User id is not 1")
+	assert.Equal(suite.T(), int32(1), suite.users.calls, "Lookup is not coalesced with the batch")
+}
@@ -0,0 +1,66 @@
+package client
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type CircuitBreakerUnitTestSuite struct {
+	suite.Suite
+	now     time.Time
+	breaker *CircuitBreaker
+}
+
+func TestCircuitBreakerUnitTestSuite(t *testing.T) {
+	suite.Run(t, new(CircuitBreakerUnitTestSuite))
+}
+
+func (suite *CircuitBreakerUnitTestSuite) SetupTest() {
+	suite.now = time.Now()
+	suite.breaker = NewCircuitBreaker(2, time.Minute)
+	suite.breaker.now = func() time.Time { return suite.now }
+}
+
+func (suite *CircuitBreakerUnitTestSuite) TestCircuitBreaker_OpensAfterThreshold() {
+	suite.breaker.Failure()
+
+	assert.True(suite.T(), suite.breaker.Allow(), "Circuit is open after one failure")
+
+	suite.breaker.Failure()
+
+	assert.False(suite.T(), suite.breaker.Allow(), "Circuit is not open after two failures")
+}
+
+func (suite *CircuitBreakerUnitTestSuite) TestCircuitBreaker_SuccessResetsFailures() {
+	suite.breaker.Failure()
+	suite.breaker.Success()
+	suite.breaker.Failure()
+
+	assert.True(suite.T(), suite.breaker.Allow(), "Circuit is open")
+}
+
+func (suite *CircuitBreakerUnitTestSuite) TestCircuitBreaker_HalfOpenAllowsSingleProbe() {
+	suite.breaker.Failure()
+	suite.breaker.Failure()
+
+	suite.now = suite.now.Add(time.Minute)
+
+	assert.True(suite.T(), suite.breaker.Allow(), "Probe is not allowed")
+	assert.False(suite.T(), suite.breaker.Allow(), "Second probe is allowed")
+
+	suite.breaker.Failure()
+
+	assert.False(suite.T(), suite.breaker.Allow(), "Circuit is not open after failed probe")
+
+	suite.now = suite.now.Add(time.Minute)
+
+	assert.True(suite.T(), suite.breaker.Allow(), "Probe is not allowed")
+
+	suite.breaker.Success()
+
+	assert.True(suite.T(), suite.breaker.Allow(), "Circuit is not closed after successful probe")
+	assert.True(suite.T(), suite.breaker.Allow(), "Circuit is not closed after successful probe")
+}
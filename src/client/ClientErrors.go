@@ -0,0 +1,14 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrNotFound           = errors.New("resource not found")
+	ErrUnavailable        = errors.New("service unavailable")
+	ErrCircuitOpen        = fmt.Errorf("%w: circuit breaker is open", ErrUnavailable)
+	ErrRejected           = errors.New("request rejected")
+	ErrUnexpectedResponse = errors.New("unexpected response")
+)
@@ -0,0 +1,109 @@
+package client
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"posts-ms/src/dto/request"
+	"sync"
+)
+
+// eventSpool keeps undelivered system events in a file, one JSON document
+// per line, so that they survive a restart.
+type eventSpool struct {
+	path  string
+	mutex sync.Mutex
+}
+
+// newEventSpool returns nil when path is empty, which disables spooling.
+func newEventSpool(path string) (*eventSpool, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+
+	return &eventSpool{path: path}, nil
+}
+
+func (s *eventSpool) append(events ...request.EventRequestDTO) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(file)
+
+	for _, event := range events {
+		if err := encoder.Encode(&event); err != nil {
+			file.Close()
+
+			return err
+		}
+	}
+
+	return file.Close()
+}
+
+// claim moves the spooled events aside for a replay and returns them, while
+// new events go to an empty spool. The claimed events stay on disk until
+// release, so a crash during the replay only means they are sent again. A
+// claim left behind by a crash is returned before anything newer. Lines that
+// are not valid events, for example one cut off by a crash, are skipped.
+func (s *eventSpool) claim() ([]request.EventRequestDTO, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, err := os.Stat(s.claimPath()); os.IsNotExist(err) {
+		if err := os.Rename(s.path, s.claimPath()); os.IsNotExist(err) {
+			return nil, nil
+		} else if err != nil {
+			return nil, err
+		}
+	}
+
+	file, err := os.Open(s.claimPath())
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	var events []request.EventRequestDTO
+
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		var event request.EventRequestDTO
+
+		if json.Unmarshal(scanner.Bytes(), &event) == nil {
+			events = append(events, event)
+		}
+	}
+
+	return events, scanner.Err()
+}
+
+// release forgets the claimed events once they were sent or spooled again.
+func (s *eventSpool) release() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := os.Remove(s.claimPath()); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+func (s *eventSpool) claimPath() string {
+	return s.path + ".replay"
+}
@@ -0,0 +1,260 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"posts-ms/src/dto/request"
+	"posts-ms/src/utils"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+type IEventsDispatcher interface {
+	Publish(request.EventRequestDTO, context.Context)
+}
+
+type EventsDispatcherConfig struct {
+	QueueSize      int
+	Workers        int
+	Retry          RetryConfig
+	SpoolFile      string
+	ReplayInterval time.Duration
+}
+
+// EventsDispatcher sends system events to events-ms in the background, so
+// that requests never wait for events-ms. Events that cannot be delivered,
+// because events-ms is down or the queue is full, are written to a spool
+// file and sent again every ReplayInterval.
+type EventsDispatcher struct {
+	client IEventsRESTClient
+	config EventsDispatcherConfig
+	queue  chan request.EventRequestDTO
+	spool  *eventSpool
+	logger *logrus.Entry
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewEventsDispatcher(client IEventsRESTClient, config EventsDispatcherConfig, logger *logrus.Entry) (*EventsDispatcher, error) {
+	spool, err := newEventSpool(config.SpoolFile)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &EventsDispatcher{
+		client: client,
+		config: config,
+		queue:  make(chan request.EventRequestDTO, config.QueueSize),
+		spool:  spool,
+		logger: logger,
+	}, nil
+}
+
+// Publish completes the event and queues it without blocking.
+func (d *EventsDispatcher) Publish(event request.EventRequestDTO, ctx context.Context) {
+	event.EventId = uuid.Must(uuid.NewV4()).String()
+	event.Timestamp = time.Now().Format("2006-01-02 15:04:05")
+	event.RequestId = utils.RequestId(ctx)
+
+	if event.Message == "" {
+		event.Message = describeEvent(event)
+	}
+
+	select {
+	case d.queue <- event:
+	default:
+		d.logger.WithContext(ctx).Warn("System event queue is full, spooling event")
+
+		d.spoolEvents(event)
+	}
+}
+
+// Start runs the workers and the replay of spooled events until Close.
+func (d *EventsDispatcher) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	d.cancel = cancel
+
+	for i := 0; i < d.config.Workers; i++ {
+		d.wg.Add(1)
+
+		go func() {
+			defer d.wg.Done()
+
+			d.work(ctx)
+		}()
+	}
+
+	d.wg.Add(1)
+
+	go func() {
+		defer d.wg.Done()
+
+		d.replay(ctx)
+	}()
+}
+
+// Close stops the workers and spools the events that were not sent yet, so
+// that they are delivered after the next start.
+func (d *EventsDispatcher) Close() error {
+	if d.cancel != nil {
+		d.cancel()
+	}
+
+	d.wg.Wait()
+
+	var pending []request.EventRequestDTO
+
+	for {
+		select {
+		case event := <-d.queue:
+			pending = append(pending, event)
+		default:
+			if len(pending) > 0 {
+				d.logger.Infof("Spooling %d unsent system events", len(pending))
+
+				d.spoolEvents(pending...)
+			}
+
+			return nil
+		}
+	}
+}
+
+func (d *EventsDispatcher) work(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-d.queue:
+			d.deliver(event, ctx)
+		}
+	}
+}
+
+func (d *EventsDispatcher) deliver(event request.EventRequestDTO, ctx context.Context) {
+	err := retry(ctx, d.config.Retry, func() error {
+		return d.client.Send(event, ctx)
+	})
+
+	switch {
+	case err == nil:
+	case errors.Is(err, ErrUnavailable) || ctx.Err() != nil:
+		d.logger.WithError(err).Warn("Error occured in sending system event, spooling event")
+
+		d.spoolEvents(event)
+	default:
+		d.logger.WithError(err).Error("Error occured in sending system event, event is dropped")
+	}
+}
+
+// replay sends spooled events again. It stops at the first event events-ms
+// is unavailable for and puts the rest back for the next round.
+func (d *EventsDispatcher) replay(ctx context.Context) {
+	ticker := time.NewTicker(d.config.ReplayInterval)
+
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			d.replaySpool(ctx)
+		}
+	}
+}
+
+func (d *EventsDispatcher) replaySpool(ctx context.Context) {
+	if d.spool == nil {
+		return
+	}
+
+	events, err := d.spool.claim()
+
+	if err != nil {
+		d.logger.WithError(err).Error("Error occured in reading spooled system events")
+
+		return
+	}
+
+	sent := 0
+
+	for _, event := range events {
+		err := d.client.Send(event, ctx)
+
+		if errors.Is(err, ErrUnavailable) || ctx.Err() != nil {
+			break
+		}
+
+		if err != nil {
+			d.logger.WithError(err).Error("Error occured in sending spooled system event, event is dropped")
+		}
+
+		sent++
+	}
+
+	if sent < len(events) {
+		if err := d.spool.append(events[sent:]...); err != nil {
+			// The claim is kept and replayed as a whole, events that were
+			// already sent are sent again.
+			d.logger.WithError(err).Error("Error occured in spooling system events again")
+
+			return
+		}
+	}
+
+	if err := d.spool.release(); err != nil {
+		d.logger.WithError(err).Error("Error occured in releasing replayed system events")
+	}
+
+	if sent > 0 {
+		d.logger.Infof("Sent %d spooled system events", sent)
+	}
+}
+
+func (d *EventsDispatcher) spoolEvents(events ...request.EventRequestDTO) {
+	if d.spool == nil {
+		d.logger.Errorf("No spool file configured, %d system events are dropped", len(events))
+
+		return
+	}
+
+	if err := d.spool.append(events...); err != nil {
+		d.logger.WithError(err).Errorf("Error occured in spooling system events, %d events are dropped", len(events))
+	}
+}
+
+// describeEvent writes the summary events-ms shows, for example "Post with
+// id 5 successfully deleted by user with id 2".
+func describeEvent(event request.EventRequestDTO) string {
+	subject := "Entity"
+
+	if event.EntityType != "" {
+		subject = strings.ToUpper(event.EntityType[:1]) + event.EntityType[1:]
+	}
+
+	if event.EntityId != 0 {
+		subject = fmt.Sprintf("%s with id %d", subject, event.EntityId)
+	}
+
+	outcome := "successfully"
+
+	if event.Outcome == request.FailedOutcome {
+		outcome = "unsuccessfully"
+	}
+
+	message := fmt.Sprintf("%s %s %s", subject, outcome, event.Action)
+
+	if event.ActorId != 0 {
+		message = fmt.Sprintf("%s by user with id %d", message, event.ActorId)
+	}
+
+	return message
+}
@@ -0,0 +1,256 @@
+package client
+
+import (
+	"context"
+	"path/filepath"
+	"posts-ms/src/dto/request"
+	"posts-ms/src/utils"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+// fakeEventsClient records the events it is sent and answers with the
+// errors queued in responses, succeeding once they are used up.
+type fakeEventsClient struct {
+	mutex     sync.Mutex
+	responses []error
+	fallback  error
+	attempts  int
+	received  []request.EventRequestDTO
+}
+
+func (c *fakeEventsClient) Send(event request.EventRequestDTO, ctx context.Context) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.attempts++
+
+	err := c.fallback
+
+	if len(c.responses) > 0 {
+		err, c.responses = c.responses[0], c.responses[1:]
+	}
+
+	if err == nil {
+		c.received = append(c.received, event)
+	}
+
+	return err
+}
+
+func (c *fakeEventsClient) setFallback(err error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.fallback = err
+}
+
+func (c *fakeEventsClient) delivered() []request.EventRequestDTO {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return append([]request.EventRequestDTO{}, c.received...)
+}
+
+func (c *fakeEventsClient) attempted() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.attempts
+}
+
+type EventsDispatcherUnitTestSuite struct {
+	suite.Suite
+	client     *fakeEventsClient
+	config     EventsDispatcherConfig
+	dispatcher *EventsDispatcher
+}
+
+func TestEventsDispatcherUnitTestSuite(t *testing.T) {
+	suite.Run(t, new(EventsDispatcherUnitTestSuite))
+}
+
+func (suite *EventsDispatcherUnitTestSuite) SetupTest() {
+	suite.client = &fakeEventsClient{}
+	suite.config = EventsDispatcherConfig{
+		QueueSize:      10,
+		Workers:        1,
+		Retry:          RetryConfig{MaxAttempts: 3, BaseBackoff: time.Millisecond, MaxBackoff: time.Millisecond},
+		SpoolFile:      filepath.Join(suite.T().TempDir(), "spool", "events.jsonl"),
+		ReplayInterval: time.Hour,
+	}
+}
+
+func (suite *EventsDispatcherUnitTestSuite) TearDownTest() {
+	if suite.dispatcher != nil {
+		suite.dispatcher.Close()
+
+		suite.dispatcher = nil
+	}
+}
+
+func (suite *EventsDispatcherUnitTestSuite) newDispatcher() *EventsDispatcher {
+	dispatcher, err := NewEventsDispatcher(suite.client, suite.config, utils.Logger())
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+
+	suite.dispatcher = dispatcher
+
+	return dispatcher
+}
+
+func (suite *EventsDispatcherUnitTestSuite) spooled() []request.EventRequestDTO {
+	events, err := suite.dispatcher.spool.claim()
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+	assert.Nil(suite.T(), suite.dispatcher.spool.release(), "Spool is not released")
+
+	return events
+}
+
+func postCreated() request.EventRequestDTO {
+	return request.EventRequestDTO{
+		Action:     request.CreatedAction,
+		Outcome:    request.SucceededOutcome,
+		EntityType: request.PostEntity,
+		EntityId:   5,
+		ActorId:    2,
+	}
+}
+
+func (suite *EventsDispatcherUnitTestSuite) TestEventsDispatcher_Publish_DeliversCompletedEvent() {
+	dispatcher := suite.newDispatcher()
+
+	dispatcher.Start()
+
+	ctx := utils.WithRequestInfo(context.Background(), &utils.RequestInfo{RequestId: "request"})
+
+	dispatcher.Publish(postCreated(), ctx)
+
+	assert.Eventually(suite.T(), func() bool { return len(suite.client.delivered()) == 1 }, time.Second, time.Millisecond, "Event is not delivered")
+
+	event := suite.client.delivered()[0]
+
+	assert.NotEmpty(suite.T(), event.EventId, "Event id is empty")
+	assert.NotEmpty(suite.T(), event.Timestamp, "Timestamp is empty")
+	assert.Equal(suite.T(), "request", event.RequestId, "Request id is not set")
+	assert.Equal(suite.T(), "Post with id 5 successfully created by user with id 2", event.Message, "Message is not derived")
+	assert.Equal(suite.T(), uint(2), event.ActorId, "Actor is not kept")
+}
+
+func (suite *EventsDispatcherUnitTestSuite) TestEventsDispatcher_Publish_RetriesUnavailableEventsMs() {
+	suite.client.responses = []error{ErrUnavailable, ErrUnavailable}
+
+	dispatcher := suite.newDispatcher()
+
+	dispatcher.Start()
+	dispatcher.Publish(postCreated(), context.Background())
+
+	assert.Eventually(suite.T(), func() bool { return len(suite.client.delivered()) == 1 }, time.Second, time.Millisecond, "Event is not delivered")
+	assert.Equal(suite.T(), 3, suite.client.attempted(), "Event is not retried")
+}
+
+func (suite *EventsDispatcherUnitTestSuite) TestEventsDispatcher_Publish_SpoolsAndReplaysWhenEventsMsIsBack() {
+	suite.client.fallback = ErrUnavailable
+	suite.config.ReplayInterval = 10 * time.Millisecond
+
+	dispatcher := suite.newDispatcher()
+
+	dispatcher.Start()
+	dispatcher.Publish(postCreated(), context.Background())
+
+	assert.Eventually(suite.T(), func() bool { return suite.client.attempted() > suite.config.Retry.MaxAttempts }, time.Second, time.Millisecond, "Spooled event is not replayed")
+	assert.Empty(suite.T(), suite.client.delivered(), "Event is delivered while events-ms is down")
+
+	suite.client.setFallback(nil)
+
+	assert.Eventually(suite.T(), func() bool { return len(suite.client.delivered()) == 1 }, time.Second, time.Millisecond, "Spooled event is not delivered")
+	assert.Empty(suite.T(), suite.spooled(), "Delivered event is still spooled")
+}
+
+func (suite *EventsDispatcherUnitTestSuite) TestEventsDispatcher_Publish_DropsRejectedEvent() {
+	suite.client.fallback = ErrRejected
+
+	dispatcher := suite.newDispatcher()
+
+	dispatcher.Start()
+	dispatcher.Publish(postCreated(), context.Background())
+
+	assert.Eventually(suite.T(), func() bool { return suite.client.attempted() == 1 }, time.Second, time.Millisecond, "Event is not sent")
+
+	dispatcher.Close()
+
+	assert.Equal(suite.T(), 1, suite.client.attempted(), "Rejected event is retried")
+	assert.Empty(suite.T(), suite.spooled(), "Rejected event is spooled")
+}
+
+func (suite *EventsDispatcherUnitTestSuite) TestEventsDispatcher_Publish_SpoolsWhenQueueIsFull() {
+	suite.config.QueueSize = 1
+
+	dispatcher := suite.newDispatcher()
+
+	dispatcher.Publish(postCreated(), context.Background())
+	dispatcher.Publish(postCreated(), context.Background())
+
+	assert.Len(suite.T(), suite.spooled(), 1, "Overflowing event is not spooled")
+}
+
+func (suite *EventsDispatcherUnitTestSuite) TestEventsDispatcher_Close_SpoolsQueuedEvents() {
+	dispatcher := suite.newDispatcher()
+
+	dispatcher.Publish(postCreated(), context.Background())
+	dispatcher.Publish(postCreated(), context.Background())
+
+	dispatcher.Close()
+
+	assert.Len(suite.T(), suite.spooled(), 2, "Queued events are not spooled")
+	assert.Equal(suite.T(), 0, suite.client.attempted(), "Events are sent after close")
+}
+
+func (suite *EventsDispatcherUnitTestSuite) TestEventsDispatcher_DescribeEvent_FailedWithoutEntity() {
+	event := request.EventRequestDTO{Action: request.CreatedAction, Outcome: request.FailedOutcome, EntityType: request.CommentEntity}
+
+	assert.Equal(suite.T(), "Comment unsuccessfully created", describeEvent(event), "Message is not derived")
+}
+
+func (suite *EventsDispatcherUnitTestSuite) TestEventsDispatcher_DescribeEvent_WithoutEntityType() {
+	event := request.EventRequestDTO{Action: request.DeletedAction, Outcome: request.SucceededOutcome}
+
+	assert.Equal(suite.T(), "Entity successfully deleted", describeEvent(event), "Message is not derived")
+}
+
+func (suite *EventsDispatcherUnitTestSuite) TestEventsDispatcher_ReplaySpool_ResendsEventsOfInterruptedReplay() {
+	dispatcher := suite.newDispatcher()
+
+	dispatcher.spool.append(postCreated(), postCreated())
+
+	claimed, _ := dispatcher.spool.claim()
+
+	assert.Len(suite.T(), claimed, 2, "Events are not claimed")
+
+	dispatcher.spool.append(postCreated())
+	dispatcher.replaySpool(context.Background())
+
+	assert.Len(suite.T(), suite.client.delivered(), 2, "Events of the interrupted replay are not sent")
+
+	dispatcher.replaySpool(context.Background())
+
+	assert.Len(suite.T(), suite.client.delivered(), 3, "Event spooled during the replay is not sent")
+	assert.Empty(suite.T(), suite.spooled(), "Delivered events are still spooled")
+}
+
+func (suite *EventsDispatcherUnitTestSuite) TestEventsDispatcher_ReplaySpool_KeepsEventsNotSent() {
+	suite.client.responses = []error{nil, ErrUnavailable}
+
+	dispatcher := suite.newDispatcher()
+
+	dispatcher.spool.append(postCreated(), postCreated(), postCreated())
+	dispatcher.replaySpool(context.Background())
+
+	assert.Len(suite.T(), suite.client.delivered(), 1, "Replay does not stop when events-ms is unavailable")
+	assert.Len(suite.T(), suite.spooled(), 2, "Events that were not sent are lost")
+}
@@ -0,0 +1,68 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"posts-ms/src/dto/request"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+)
+
+type IEventsRESTClient interface {
+	Send(request.EventRequestDTO, context.Context) error
+}
+
+type EventsRESTClient struct {
+	endpoint   string
+	httpClient *http.Client
+}
+
+func NewEventsRESTClient(endpoint string, timeout time.Duration) EventsRESTClient {
+	return EventsRESTClient{endpoint: endpoint, httpClient: NewTracingHTTPClient("events-ms", timeout)}
+}
+
+// Send posts the event to events-ms. It returns ErrUnavailable when events-ms
+// could not be reached or failed, so that the event can be sent again, and
+// ErrRejected when events-ms refused the event.
+func (c EventsRESTClient) Send(event request.EventRequestDTO, ctx context.Context) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Third service - Send system event to events-ms")
+
+	defer span.Finish()
+
+	body, err := json.Marshal(&event)
+
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.endpoint, bytes.NewReader(body))
+
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := c.httpClient.Do(req)
+
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+
+	defer res.Body.Close()
+
+	switch {
+	case res.StatusCode == http.StatusTooManyRequests || res.StatusCode >= http.StatusInternalServerError:
+		return fmt.Errorf("%w: events-ms responded with status %d", ErrUnavailable, res.StatusCode)
+	case res.StatusCode >= http.StatusBadRequest:
+		return fmt.Errorf("%w: events-ms responded with status %d", ErrRejected, res.StatusCode)
+	case res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusMultipleChoices:
+		return fmt.Errorf("%w: events-ms responded with status %d", ErrUnexpectedResponse, res.StatusCode)
+	}
+
+	return nil
+}
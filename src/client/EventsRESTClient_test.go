@@ -0,0 +1,79 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"posts-ms/src/dto/request"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type EventsRESTClientUnitTestSuite struct {
+	suite.Suite
+	status   int
+	received request.EventRequestDTO
+	server   *httptest.Server
+	client   EventsRESTClient
+}
+
+func TestEventsRESTClientUnitTestSuite(t *testing.T) {
+	suite.Run(t, new(EventsRESTClientUnitTestSuite))
+}
+
+func (suite *EventsRESTClientUnitTestSuite) SetupTest() {
+	suite.status = http.StatusCreated
+	suite.received = request.EventRequestDTO{}
+
+	suite.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&suite.received)
+
+		w.WriteHeader(suite.status)
+	}))
+
+	suite.client = NewEventsRESTClient(suite.server.URL, time.Second)
+}
+
+func (suite *EventsRESTClientUnitTestSuite) TearDownTest() {
+	suite.server.Close()
+}
+
+func (suite *EventsRESTClientUnitTestSuite) TestEventsRESTClient_Send_PostsEvent() {
+	event := request.EventRequestDTO{EventId: "event", Action: request.CreatedAction, EntityType: request.PostEntity, EntityId: 5, ActorId: 2}
+
+	err := suite.client.Send(event, context.TODO())
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+	assert.Equal(suite.T(), event, suite.received, "Event is not sent")
+}
+
+func (suite *EventsRESTClientUnitTestSuite) TestEventsRESTClient_Send_MapsErrorResponses() {
+	statuses := map[int]error{
+		http.StatusBadRequest:          ErrRejected,
+		http.StatusTooManyRequests:     ErrUnavailable,
+		http.StatusInternalServerError: ErrUnavailable,
+		http.StatusServiceUnavailable:  ErrUnavailable,
+		http.StatusNotModified:         ErrUnexpectedResponse,
+	}
+
+	for status, expected := range statuses {
+		suite.status = status
+
+		err := suite.client.Send(request.EventRequestDTO{}, context.TODO())
+
+		assert.True(suite.T(), errors.Is(err, expected), "Status %d is not mapped to %v", status, expected)
+	}
+}
+
+func (suite *EventsRESTClientUnitTestSuite) TestEventsRESTClient_Send_UnreachableIsUnavailable() {
+	suite.server.Close()
+
+	err := suite.client.Send(request.EventRequestDTO{}, context.TODO())
+
+	assert.True(suite.T(), errors.Is(err, ErrUnavailable), "Error is not ErrUnavailable")
+}
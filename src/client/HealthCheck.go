@@ -0,0 +1,35 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// NewReachabilityCheck reports a service as down when it cannot be reached
+// or answers with a server error. Any other response means it is reachable.
+func NewReachabilityCheck(endpoint string) func(context.Context) error {
+	httpClient := &http.Client{}
+
+	return func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+
+		if err != nil {
+			return err
+		}
+
+		res, err := httpClient.Do(req)
+
+		if err != nil {
+			return err
+		}
+
+		res.Body.Close()
+
+		if res.StatusCode >= http.StatusInternalServerError {
+			return fmt.Errorf("unexpected status %d", res.StatusCode)
+		}
+
+		return nil
+	}
+}
@@ -1,61 +1,105 @@
 package client
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"io"
 	"mime/multipart"
 	"net/http"
+	"net/textproto"
 	"posts-ms/src/dto/response"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/opentracing/opentracing-go"
 )
 
 type IMediaClient interface {
-	Upload(multipart.File, context.Context) (uint, error)
+	Upload(*multipart.FileHeader, context.Context) (uint, error)
+	Submit(uint, *multipart.FileHeader, context.Context) error
 }
 
 type MediaRESTClient struct {
 	endpoint string
 }
 
-func NewMediaRESTClient() MediaRESTClient {
-	return MediaRESTClient{endpoint: "http://medias-server:8082"}
+func NewMediaRESTClient(endpoint string) MediaRESTClient {
+	return MediaRESTClient{endpoint: strings.TrimSuffix(endpoint, "/")}
 }
 
-func (c MediaRESTClient) Upload(image multipart.File, ctx context.Context) (uint, error) {
-	span, _ := opentracing.StartSpanFromContext(ctx, "Third service - Send request to media-ms for uploading media")
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// Upload streams the image to media-ms without buffering it in memory,
+// keeping its original file name and content type.
+func (c MediaRESTClient) Upload(image *multipart.FileHeader, ctx context.Context) (uint, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Third service - Send request to media-ms for uploading media")
 
 	defer span.Finish()
 
-	client := &http.Client{
-		Timeout: time.Second * 10,
+	res, err := c.send("/api/medias", nil, image, ctx)
+
+	if err != nil {
+		return 0, err
 	}
 
-	body := &bytes.Buffer{}
+	defer res.Body.Close()
+
+	var media response.MediaDto
+
+	if err := json.NewDecoder(res.Body).Decode(&media); err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrUnexpectedResponse, err)
+	}
+
+	return media.Id, nil
+}
+
+// Submit streams the image of a post to media-ms for processing in the
+// background. media-ms accepts it right away and reports the outcome for the
+// post with a MediaProcessed message.
+func (c MediaRESTClient) Submit(postId uint, image *multipart.FileHeader, ctx context.Context) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Third service - Send request to media-ms for processing media")
 
-	writer := multipart.NewWriter(body)
+	defer span.Finish()
 
-	fw, err := writer.CreateFormFile("files", "image.png")
+	res, err := c.send("/api/medias/jobs", map[string]string{"postId": strconv.FormatUint(uint64(postId), 10)}, image, ctx)
 
 	if err != nil {
-		return 0, err
+		return err
 	}
 
-	_, err = io.Copy(fw, image)
+	res.Body.Close()
+
+	return nil
+}
+
+// send posts the fields and the image to media-ms as a multipart form and
+// returns the response when its status is successful.
+func (c MediaRESTClient) send(path string, fields map[string]string, image *multipart.FileHeader, ctx context.Context) (*http.Response, error) {
+	client := NewTracingHTTPClient("media-ms", time.Second*10)
+
+	file, err := image.Open()
 
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 
-	writer.Close()
+	defer file.Close()
+
+	body, bodyWriter := io.Pipe()
+	writer := multipart.NewWriter(bodyWriter)
+
+	go func() {
+		bodyWriter.CloseWithError(writeForm(writer, fields, image, file))
+	}()
 
-	req, err := http.NewRequest("POST", c.endpoint+"/api/medias", bytes.NewReader(body.Bytes()))
+	req, err := http.NewRequestWithContext(ctx, "POST", c.endpoint+path, body)
 
 	if err != nil {
-		return 0, err
+		body.Close()
+
+		return nil, err
 	}
 
 	req.Header.Set("Content-Type", writer.FormDataContentType())
@@ -63,12 +107,59 @@ func (c MediaRESTClient) Upload(image multipart.File, ctx context.Context) (uint
 	res, err := client.Do(req)
 
 	if err != nil {
-		return 0, err
+		return nil, fmt.Errorf("%w: %v", ErrUnavailable, err)
 	}
 
-	var media response.MediaDto
+	switch {
+	case res.StatusCode == http.StatusNotFound:
+		err = fmt.Errorf("%w: media-ms responded with status %d", ErrNotFound, res.StatusCode)
+	case res.StatusCode == http.StatusTooManyRequests || res.StatusCode >= http.StatusInternalServerError:
+		err = fmt.Errorf("%w: media-ms responded with status %d", ErrUnavailable, res.StatusCode)
+	case res.StatusCode >= http.StatusBadRequest:
+		err = fmt.Errorf("%w: media-ms responded with status %d", ErrRejected, res.StatusCode)
+	case res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusMultipleChoices:
+		err = fmt.Errorf("%w: media-ms responded with status %d", ErrUnexpectedResponse, res.StatusCode)
+	}
+
+	if err != nil {
+		res.Body.Close()
+
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func writeForm(writer *multipart.Writer, fields map[string]string, image *multipart.FileHeader, file io.Reader) error {
+	for name, value := range fields {
+		if err := writer.WriteField(name, value); err != nil {
+			return err
+		}
+	}
 
-	json.NewDecoder(res.Body).Decode(&media)
+	return writeImage(writer, image, file)
+}
+
+func writeImage(writer *multipart.Writer, image *multipart.FileHeader, file io.Reader) error {
+	contentType := image.Header.Get("Content-Type")
+
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="files"; filename="%s"`, quoteEscaper.Replace(image.Filename)))
+	header.Set("Content-Type", contentType)
+
+	part, err := writer.CreatePart(header)
+
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(part, file); err != nil {
+		return err
+	}
 
-	return media.Id, err
+	return writer.Close()
 }
@@ -2,6 +2,7 @@ package client
 
 import (
 	"context"
+	"fmt"
 	"mime/multipart"
 
 	"github.com/stretchr/testify/mock"
@@ -11,6 +12,22 @@ type MediaRestClientMock struct {
 	mock.Mock
 }
 
-func (m MediaRestClientMock) Upload(file multipart.File, ctx context.Context) (uint, error) {
+func (m MediaRestClientMock) Upload(image *multipart.FileHeader, ctx context.Context) (uint, error) {
+	if image.Filename == "unavailable" {
+		return 0, fmt.Errorf("%w: media-ms responded with status 503", ErrUnavailable)
+	}
+
+	if image.Filename == "missing" {
+		return 0, fmt.Errorf("%w: media-ms responded with status 404", ErrNotFound)
+	}
+
 	return uint(1), nil
 }
+
+func (m MediaRestClientMock) Submit(postId uint, image *multipart.FileHeader, ctx context.Context) error {
+	if image.Filename == "unavailable" {
+		return fmt.Errorf("%w: media-ms responded with status 503", ErrUnavailable)
+	}
+
+	return nil
+}
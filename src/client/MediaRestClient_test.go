@@ -0,0 +1,188 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type receivedMedia struct {
+	method      string
+	path        string
+	field       string
+	fields      map[string]string
+	fileName    string
+	contentType string
+	content     []byte
+}
+
+type MediaRESTClientUnitTestSuite struct {
+	suite.Suite
+	status   int
+	body     string
+	received receivedMedia
+	server   *httptest.Server
+	client   MediaRESTClient
+}
+
+func TestMediaRESTClientUnitTestSuite(t *testing.T) {
+	suite.Run(t, new(MediaRESTClientUnitTestSuite))
+}
+
+func (suite *MediaRESTClientUnitTestSuite) SetupTest() {
+	suite.status = http.StatusCreated
+	suite.body = `{"id":7,"url":"http://medias/7"}`
+	suite.received = receivedMedia{fields: map[string]string{}}
+
+	suite.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		suite.received.method = r.Method
+		suite.received.path = r.URL.Path
+
+		reader, err := r.MultipartReader()
+
+		for err == nil {
+			var part *multipart.Part
+
+			if part, err = reader.NextPart(); err != nil {
+				break
+			}
+
+			if part.FileName() == "" {
+				value, _ := io.ReadAll(part)
+
+				suite.received.fields[part.FormName()] = string(value)
+
+				continue
+			}
+
+			suite.received.field = part.FormName()
+			suite.received.fileName = part.FileName()
+			suite.received.contentType = part.Header.Get("Content-Type")
+			suite.received.content, _ = io.ReadAll(part)
+		}
+
+		w.WriteHeader(suite.status)
+		w.Write([]byte(suite.body))
+	}))
+
+	suite.client = NewMediaRESTClient(suite.server.URL + "/")
+}
+
+func (suite *MediaRESTClientUnitTestSuite) TearDownTest() {
+	suite.server.Close()
+}
+
+func newFileHeader(fileName string, contentType string, content []byte) *multipart.FileHeader {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", `form-data; name="files"; filename="`+fileName+`"`)
+
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+
+	part, _ := writer.CreatePart(header)
+	part.Write(content)
+	writer.Close()
+
+	form, _ := multipart.NewReader(body, writer.Boundary()).ReadForm(1 << 20)
+
+	return form.File["files"][0]
+}
+
+func (suite *MediaRESTClientUnitTestSuite) TestMediaRESTClient_Upload_SendsOriginalFile() {
+	image := newFileHeader("holiday.jpeg", "image/jpeg", []byte("jpeg-bytes"))
+
+	id, err := suite.client.Upload(image, context.TODO())
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+	assert.Equal(suite.T(), uint(7), id, "Media id is not 7")
+	assert.Equal(suite.T(), "POST", suite.received.method, "Method is not POST")
+	assert.Equal(suite.T(), "/api/medias", suite.received.path, "Path is not /api/medias")
+	assert.Equal(suite.T(), "files", suite.received.field, "Form field is not files")
+	assert.Equal(suite.T(), "holiday.jpeg", suite.received.fileName, "File name is not preserved")
+	assert.Equal(suite.T(), "image/jpeg", suite.received.contentType, "Content type is not preserved")
+	assert.Equal(suite.T(), []byte("jpeg-bytes"), suite.received.content, "Content is not sent")
+}
+
+func (suite *MediaRESTClientUnitTestSuite) TestMediaRESTClient_Upload_WithoutContentTypeSendsOctetStream() {
+	image := newFileHeader("image", "", []byte("bytes"))
+
+	suite.client.Upload(image, context.TODO())
+
+	assert.Equal(suite.T(), "application/octet-stream", suite.received.contentType, "Content type is not octet stream")
+}
+
+func (suite *MediaRESTClientUnitTestSuite) TestMediaRESTClient_Upload_MapsErrorResponses() {
+	image := newFileHeader("image.png", "image/png", []byte("png-bytes"))
+
+	statuses := map[int]error{
+		http.StatusUnsupportedMediaType:  ErrRejected,
+		http.StatusRequestEntityTooLarge: ErrRejected,
+		http.StatusNotFound:              ErrNotFound,
+		http.StatusServiceUnavailable:    ErrUnavailable,
+	}
+
+	for status, expected := range statuses {
+		suite.status = status
+
+		id, err := suite.client.Upload(image, context.TODO())
+
+		assert.True(suite.T(), errors.Is(err, expected), "Status %d is not mapped to %v", status, expected)
+		assert.Equal(suite.T(), uint(0), id, "Media id is not 0")
+	}
+}
+
+func (suite *MediaRESTClientUnitTestSuite) TestMediaRESTClient_Upload_InvalidResponseBody() {
+	suite.body = "not json"
+
+	image := newFileHeader("image.png", "image/png", []byte("png-bytes"))
+
+	_, err := suite.client.Upload(image, context.TODO())
+
+	assert.True(suite.T(), errors.Is(err, ErrUnexpectedResponse), "Error is not unexpected response")
+}
+
+func (suite *MediaRESTClientUnitTestSuite) TestMediaRESTClient_Upload_ServiceDown() {
+	suite.server.Close()
+
+	image := newFileHeader("image.png", "image/png", []byte("png-bytes"))
+
+	_, err := suite.client.Upload(image, context.TODO())
+
+	assert.True(suite.T(), errors.Is(err, ErrUnavailable), "Error is not unavailable")
+}
+
+func (suite *MediaRESTClientUnitTestSuite) TestMediaRESTClient_Submit_SendsPostIdAndFile() {
+	suite.status = http.StatusAccepted
+	suite.body = ""
+
+	image := newFileHeader("holiday.jpeg", "image/jpeg", []byte("jpeg-bytes"))
+
+	err := suite.client.Submit(4, image, context.TODO())
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+	assert.Equal(suite.T(), "/api/medias/jobs", suite.received.path, "Path is not /api/medias/jobs")
+	assert.Equal(suite.T(), "4", suite.received.fields["postId"], "Post id is not sent")
+	assert.Equal(suite.T(), "holiday.jpeg", suite.received.fileName, "File name is not preserved")
+	assert.Equal(suite.T(), []byte("jpeg-bytes"), suite.received.content, "Content is not sent")
+}
+
+func (suite *MediaRESTClientUnitTestSuite) TestMediaRESTClient_Submit_MapsErrorResponses() {
+	suite.status = http.StatusServiceUnavailable
+
+	err := suite.client.Submit(4, newFileHeader("image.png", "image/png", []byte("png-bytes")), context.TODO())
+
+	assert.True(suite.T(), errors.Is(err, ErrUnavailable), "Error is not unavailable")
+}
@@ -0,0 +1,53 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+)
+
+type RetryConfig struct {
+	MaxAttempts int
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+}
+
+// retry calls fn until it succeeds, returns an error that is not ErrUnavailable,
+// the circuit breaker is open or MaxAttempts is reached. Waits between attempts
+// use exponential backoff with full jitter.
+func retry(ctx context.Context, config RetryConfig, fn func() error) error {
+	var err error
+
+	for attempt := 0; attempt < config.MaxAttempts; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return err
+			case <-time.After(backoff(config, attempt)):
+			}
+		}
+
+		err = fn()
+
+		if err == nil || !errors.Is(err, ErrUnavailable) || errors.Is(err, ErrCircuitOpen) {
+			return err
+		}
+	}
+
+	return err
+}
+
+func backoff(config RetryConfig, attempt int) time.Duration {
+	delay := config.BaseBackoff << uint(attempt-1)
+
+	if delay <= 0 || delay > config.MaxBackoff {
+		delay = config.MaxBackoff
+	}
+
+	if delay <= 0 {
+		return 0
+	}
+
+	return time.Duration(rand.Int63n(int64(delay) + 1))
+}
@@ -0,0 +1,84 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"posts-ms/src/utils"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var outboundDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "outbound_request_duration_seconds",
+	Help:    "Duration of HTTP requests to other services.",
+	Buckets: prometheus.DefBuckets,
+}, []string{"dependency", "outcome"})
+
+type tracingTransport struct {
+	dependency string
+	transport  http.RoundTripper
+}
+
+// NewTracingHTTPClient returns a client that traces its requests and records
+// their latency under dependency, the name of the called service.
+func NewTracingHTTPClient(dependency string, timeout time.Duration) *http.Client {
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: tracingTransport{dependency: dependency, transport: http.DefaultTransport},
+	}
+}
+
+func (t tracingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	span, _ := opentracing.StartSpanFromContext(req.Context(), "HTTP "+req.Method)
+
+	defer span.Finish()
+
+	ext.SpanKindRPCClient.Set(span)
+	ext.HTTPMethod.Set(span, req.Method)
+	ext.HTTPUrl.Set(span, req.URL.String())
+	ext.PeerHostname.Set(span, req.URL.Hostname())
+	ext.Component.Set(span, "net/http")
+
+	// RoundTrippers must not modify the caller's request.
+	req = req.Clone(req.Context())
+
+	span.Tracer().Inject(span.Context(), opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(req.Header))
+
+	if requestId := utils.RequestId(req.Context()); requestId != "" {
+		req.Header.Set(utils.RequestIdHeader, requestId)
+	}
+
+	start := time.Now()
+
+	res, err := t.transport.RoundTrip(req)
+
+	outboundDuration.WithLabelValues(t.dependency, outcome(res, err)).Observe(time.Since(start).Seconds())
+
+	if err != nil {
+		ext.Error.Set(span, true)
+		span.SetTag("error.message", err.Error())
+
+		return nil, err
+	}
+
+	ext.HTTPStatusCode.Set(span, uint16(res.StatusCode))
+
+	if res.StatusCode >= http.StatusInternalServerError {
+		ext.Error.Set(span, true)
+	}
+
+	return res, nil
+}
+
+// outcome is "error" when no response arrived and the status class otherwise.
+func outcome(res *http.Response, err error) string {
+	if err != nil {
+		return "error"
+	}
+
+	return fmt.Sprintf("%dxx", res.StatusCode/100)
+}
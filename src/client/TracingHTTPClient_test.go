@@ -0,0 +1,106 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/mocktracer"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type TracingHTTPClientUnitTestSuite struct {
+	suite.Suite
+	tracer *mocktracer.MockTracer
+}
+
+func TestTracingHTTPClientUnitTestSuite(t *testing.T) {
+	suite.Run(t, new(TracingHTTPClientUnitTestSuite))
+}
+
+func (suite *TracingHTTPClientUnitTestSuite) SetupTest() {
+	suite.tracer = mocktracer.New()
+
+	opentracing.SetGlobalTracer(suite.tracer)
+}
+
+func (suite *TracingHTTPClientUnitTestSuite) TearDownTest() {
+	opentracing.SetGlobalTracer(opentracing.NoopTracer{})
+}
+
+func (suite *TracingHTTPClientUnitTestSuite) TestTracingHTTPClient_Do_InjectsSpanContext() {
+	var received opentracing.SpanContext
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received, _ = suite.tracer.Extract(opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(r.Header))
+
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	defer server.Close()
+
+	parent := suite.tracer.StartSpan("parent")
+	ctx := opentracing.ContextWithSpan(context.Background(), parent)
+
+	req, _ := http.NewRequestWithContext(ctx, "GET", server.URL, nil)
+
+	res, err := NewTracingHTTPClient("test", 0).Do(req)
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+	res.Body.Close()
+
+	spans := suite.tracer.FinishedSpans()
+
+	assert.Equal(suite.T(), 1, len(spans), "Number of finished spans is not 1")
+	assert.NotNil(suite.T(), received, "Span context is not injected")
+
+	outbound := spans[0]
+
+	assert.Equal(suite.T(), parent.(*mocktracer.MockSpan).SpanContext.SpanID, outbound.ParentID, "Outbound span is not child of parent span")
+	assert.Equal(suite.T(), outbound.SpanContext.SpanID, received.(mocktracer.MockSpanContext).SpanID, "Injected span is not outbound span")
+	assert.Empty(suite.T(), req.Header, "Caller request is modified")
+}
+
+func (suite *TracingHTTPClientUnitTestSuite) TestTracingHTTPClient_Do_TagsServerErrors() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+
+	defer server.Close()
+
+	req, _ := http.NewRequest("GET", server.URL, nil)
+
+	res, err := NewTracingHTTPClient("test", 0).Do(req)
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+	res.Body.Close()
+
+	span := suite.tracer.FinishedSpans()[0]
+
+	assert.Equal(suite.T(), true, span.Tag("error"), "Span is not tagged as error")
+	assert.Equal(suite.T(), uint16(http.StatusServiceUnavailable), span.Tag("http.status_code"), "Status code is not tagged")
+}
+
+func (suite *TracingHTTPClientUnitTestSuite) TestTracingHTTPClient_Do_RecordsLatencyPerDependency() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+
+	defer server.Close()
+
+	before := testutil.CollectAndCount(outboundDuration, "outbound_request_duration_seconds")
+
+	req, _ := http.NewRequest("GET", server.URL, nil)
+
+	res, err := NewTracingHTTPClient("latency-test", 0).Do(req)
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+
+	res.Body.Close()
+
+	assert.Equal(suite.T(), before+1, testutil.CollectAndCount(outboundDuration, "outbound_request_duration_seconds"), "Latency is not recorded for latency-test 5xx")
+}
@@ -3,50 +3,199 @@ package client
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"net/http"
-	"os"
 	"posts-ms/src/dto/response"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/opentracing/opentracing-go"
 )
 
 type IUserRESTClient interface {
 	GetUser(int, context.Context) (*response.UserResponseDTO, error)
+	GetUsers([]int, context.Context) ([]*response.UserResponseDTO, error)
 }
 
-type UserRESTClient struct{}
+type UserRESTClient struct {
+	endpoint       string
+	timeout        time.Duration
+	retry          RetryConfig
+	circuitBreaker *CircuitBreaker
+	httpClient     *http.Client
+}
 
-func NewUserRESTClient() UserRESTClient {
-	return UserRESTClient{}
+func NewUserRESTClient(endpoint string, timeout time.Duration) UserRESTClient {
+	return UserRESTClient{
+		endpoint: endpoint,
+		timeout:  timeout,
+		retry: RetryConfig{
+			MaxAttempts: 3,
+			BaseBackoff: 100 * time.Millisecond,
+			MaxBackoff:  time.Second,
+		},
+		circuitBreaker: NewCircuitBreaker(5, 30*time.Second),
+		httpClient:     NewTracingHTTPClient("users-ms", 0),
+	}
 }
 
+// GetUser returns ErrNotFound when users-ms does not know the user and
+// ErrUnavailable when it could not be reached, timed out or the circuit
+// breaker is open.
 func (c UserRESTClient) GetUser(id int, ctx context.Context) (*response.UserResponseDTO, error) {
-	span, _ := opentracing.StartSpanFromContext(ctx, "Third service - Send request to fetch user by id form user-ms")
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Third service - Send request to fetch user by id form user-ms")
 
 	defer span.Finish()
 
-	user := response.UserResponseDTO{}
-	endpoint := fmt.Sprintf("http://%s/users/%d", os.Getenv("USER_SERVICE_DOMAIN"), id)
+	var user *response.UserResponseDTO
+
+	err := retry(ctx, c.retry, func() error {
+		if !c.circuitBreaker.Allow() {
+			return ErrCircuitOpen
+		}
 
-	req, _ := http.NewRequest("GET", endpoint, nil)
+		var err error
+
+		user, err = c.getUser(id, ctx)
+
+		if errors.Is(err, ErrUnavailable) {
+			c.circuitBreaker.Failure()
+		} else {
+			c.circuitBreaker.Success()
+		}
+
+		return err
+	})
 
-	res, err := http.DefaultClient.Do(req)
 	if err != nil {
+		span.SetTag("error", true)
+
 		return nil, err
 	}
-	defer res.Body.Close()
 
-	if res.StatusCode != 200 {
-		fmt.Println(res.StatusCode)
-		return nil, err
-	} else {
-		b, _ := io.ReadAll(res.Body)
-		errr := json.Unmarshal(b, &user)
-		if errr != nil {
-			fmt.Println(errr.Error())
+	return user, nil
+}
+
+// GetUsers fetches all requested users in a single request. Users unknown to
+// users-ms are left out of the result.
+func (c UserRESTClient) GetUsers(ids []int, ctx context.Context) ([]*response.UserResponseDTO, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Third service - Send request to fetch users by ids form user-ms")
+
+	defer span.Finish()
+
+	if len(ids) == 0 {
+		return []*response.UserResponseDTO{}, nil
+	}
+
+	var users []*response.UserResponseDTO
+
+	err := retry(ctx, c.retry, func() error {
+		if !c.circuitBreaker.Allow() {
+			return ErrCircuitOpen
+		}
+
+		var err error
+
+		users, err = c.getUsers(ids, ctx)
+
+		if errors.Is(err, ErrUnavailable) {
+			c.circuitBreaker.Failure()
+		} else {
+			c.circuitBreaker.Success()
 		}
+
+		return err
+	})
+
+	if err != nil {
+		span.SetTag("error", true)
+
+		return nil, err
+	}
+
+	return users, nil
+}
+
+func (c UserRESTClient) getUser(id int, ctx context.Context) (*response.UserResponseDTO, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+
+	defer cancel()
+
+	endpoint := fmt.Sprintf("%s/users/%d", c.endpoint, id)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := c.httpClient.Do(req)
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnavailable, err)
 	}
+
+	defer res.Body.Close()
+
+	switch {
+	case res.StatusCode == http.StatusNotFound:
+		return nil, fmt.Errorf("%w: user %d", ErrNotFound, id)
+	case res.StatusCode == http.StatusTooManyRequests || res.StatusCode >= http.StatusInternalServerError:
+		return nil, fmt.Errorf("%w: users-ms responded with status %d", ErrUnavailable, res.StatusCode)
+	case res.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("%w: users-ms responded with status %d", ErrUnexpectedResponse, res.StatusCode)
+	}
+
+	user := response.UserResponseDTO{}
+
+	if err := json.NewDecoder(res.Body).Decode(&user); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnexpectedResponse, err)
+	}
+
 	return &user, nil
 }
+
+func (c UserRESTClient) getUsers(ids []int, ctx context.Context) ([]*response.UserResponseDTO, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+
+	defer cancel()
+
+	values := make([]string, len(ids))
+
+	for i, id := range ids {
+		values[i] = strconv.Itoa(id)
+	}
+
+	endpoint := fmt.Sprintf("%s/users?ids=%s", c.endpoint, strings.Join(values, ","))
+
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := c.httpClient.Do(req)
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+
+	defer res.Body.Close()
+
+	switch {
+	case res.StatusCode == http.StatusTooManyRequests || res.StatusCode >= http.StatusInternalServerError:
+		return nil, fmt.Errorf("%w: users-ms responded with status %d", ErrUnavailable, res.StatusCode)
+	case res.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("%w: users-ms responded with status %d", ErrUnexpectedResponse, res.StatusCode)
+	}
+
+	users := []*response.UserResponseDTO{}
+
+	if err := json.NewDecoder(res.Body).Decode(&users); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnexpectedResponse, err)
+	}
+
+	return users, nil
+}
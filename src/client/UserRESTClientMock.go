@@ -2,6 +2,7 @@ package client
 
 import (
 	"context"
+	"fmt"
 	"posts-ms/src/dto/response"
 
 	"github.com/stretchr/testify/mock"
@@ -12,5 +13,40 @@ type UserRESTClientMock struct {
 }
 
 func (m UserRESTClientMock) GetUser(id int, ctx context.Context) (*response.UserResponseDTO, error) {
+	switch id {
+	case 404:
+		return nil, fmt.Errorf("%w: user %d", ErrNotFound, id)
+	case 503:
+		return nil, ErrUnavailable
+	}
+
+	enabled, disabled := true, false
+
+	switch id {
+	case 21:
+		return &response.UserResponseDTO{ID: id, Auth0ID: "21", Username: "Username", LikeNotifications: &disabled, CommentNotifications: &enabled}, nil
+	case 22:
+		return &response.UserResponseDTO{ID: id, Auth0ID: "22", Username: "Username", LikeNotifications: &enabled, CommentNotifications: &disabled}, nil
+	case 23:
+		return &response.UserResponseDTO{ID: id, Auth0ID: "23", Username: "Username", LikeNotifications: &enabled, CommentNotifications: &enabled}, nil
+	}
+
 	return &response.UserResponseDTO{Auth0ID: "1", Username: "Username"}, nil
 }
+
+func (m UserRESTClientMock) GetUsers(ids []int, ctx context.Context) ([]*response.UserResponseDTO, error) {
+	users := []*response.UserResponseDTO{}
+
+	for _, id := range ids {
+		switch id {
+		case 404:
+			continue
+		case 503:
+			return nil, ErrUnavailable
+		}
+
+		users = append(users, &response.UserResponseDTO{ID: id, Auth0ID: "1", Username: "Username", FirstName: "First", LastName: "Last"})
+	}
+
+	return users, nil
+}
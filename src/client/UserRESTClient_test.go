@@ -0,0 +1,167 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type UserRESTClientUnitTestSuite struct {
+	suite.Suite
+	requests int32
+	status   int
+	query    string
+	body     string
+	server   *httptest.Server
+	client   UserRESTClient
+}
+
+func TestUserRESTClientUnitTestSuite(t *testing.T) {
+	suite.Run(t, new(UserRESTClientUnitTestSuite))
+}
+
+func (suite *UserRESTClientUnitTestSuite) SetupTest() {
+	suite.requests = 0
+	suite.status = http.StatusOK
+	suite.body = `{"ID":1,"Auth0ID":"auth0|1","Username":"admin"}`
+
+	suite.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&suite.requests, 1)
+
+		if suite.status == http.StatusGatewayTimeout {
+			time.Sleep(50 * time.Millisecond)
+		}
+
+		w.WriteHeader(suite.status)
+
+		if r.URL.Path == "/users" {
+			suite.query = r.URL.Query().Get("ids")
+			w.Write([]byte(`[{"ID":1,"Username":"admin"},{"ID":2,"Username":"admin2"}]`))
+
+			return
+		}
+
+		w.Write([]byte(suite.body))
+	}))
+
+	suite.client = UserRESTClient{
+		endpoint: suite.server.URL,
+		timeout:  20 * time.Millisecond,
+		retry: RetryConfig{
+			MaxAttempts: 3,
+			BaseBackoff: time.Millisecond,
+			MaxBackoff:  5 * time.Millisecond,
+		},
+		circuitBreaker: NewCircuitBreaker(5, time.Minute),
+		httpClient:     NewTracingHTTPClient("users-ms", 0),
+	}
+}
+
+func (suite *UserRESTClientUnitTestSuite) TearDownTest() {
+	suite.server.Close()
+}
+
+func (suite *UserRESTClientUnitTestSuite) TestUserRESTClient_GetUser_ReturnsUser() {
+	user, err := suite.client.GetUser(1, context.TODO())
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+	assert.Equal(suite.T(), "admin", user.Username, "Username is not admin")
+	assert.Equal(suite.T(), int32(1), suite.requests, "Number of requests is not 1")
+}
+
+func (suite *UserRESTClientUnitTestSuite) TestUserRESTClient_GetUser_DecodesNotificationPreferences() {
+	suite.body = `{"ID":1,"Auth0ID":"auth0|1","Username":"admin","like_notifications":false,"comment_notifications":true}`
+
+	user, err := suite.client.GetUser(1, context.TODO())
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+	assert.NotNil(suite.T(), user.LikeNotifications, "Like notifications are not decoded")
+	assert.False(suite.T(), *user.LikeNotifications, "Like notifications are not off")
+	assert.NotNil(suite.T(), user.CommentNotifications, "Comment notifications are not decoded")
+	assert.True(suite.T(), *user.CommentNotifications, "Comment notifications are not on")
+}
+
+func (suite *UserRESTClientUnitTestSuite) TestUserRESTClient_GetUser_MissingPreferencesAreNil() {
+	user, err := suite.client.GetUser(1, context.TODO())
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+	assert.Nil(suite.T(), user.LikeNotifications, "Missing like notifications are not nil")
+	assert.Nil(suite.T(), user.CommentNotifications, "Missing comment notifications are not nil")
+}
+
+func (suite *UserRESTClientUnitTestSuite) TestUserRESTClient_GetUser_NotFoundIsNotRetried() {
+	suite.status = http.StatusNotFound
+
+	user, err := suite.client.GetUser(1, context.TODO())
+
+	assert.True(suite.T(), errors.Is(err, ErrNotFound), "Error is not not found")
+	assert.Nil(suite.T(), user, "User is not nil")
+	assert.Equal(suite.T(), int32(1), suite.requests, "Number of requests is not 1")
+}
+
+func (suite *UserRESTClientUnitTestSuite) TestUserRESTClient_GetUser_ServerErrorIsRetried() {
+	suite.status = http.StatusServiceUnavailable
+
+	user, err := suite.client.GetUser(1, context.TODO())
+
+	assert.True(suite.T(), errors.Is(err, ErrUnavailable), "Error is not unavailable")
+	assert.Nil(suite.T(), user, "User is not nil")
+	assert.Equal(suite.T(), int32(3), suite.requests, "Number of requests is not 3")
+}
+
+func (suite *UserRESTClientUnitTestSuite) TestUserRESTClient_GetUser_TimeoutIsUnavailable() {
+	suite.status = http.StatusGatewayTimeout
+	suite.client.retry.MaxAttempts = 1
+
+	_, err := suite.client.GetUser(1, context.TODO())
+
+	assert.True(suite.T(), errors.Is(err, ErrUnavailable), "Error is not unavailable")
+}
+
+func (suite *UserRESTClientUnitTestSuite) TestUserRESTClient_GetUser_OpenCircuitSkipsRequests() {
+	suite.status = http.StatusInternalServerError
+	suite.client.circuitBreaker = NewCircuitBreaker(2, time.Minute)
+
+	suite.client.GetUser(1, context.TODO())
+
+	requests := suite.requests
+
+	_, err := suite.client.GetUser(1, context.TODO())
+
+	assert.True(suite.T(), errors.Is(err, ErrCircuitOpen), "Error is not circuit open")
+	assert.True(suite.T(), errors.Is(err, ErrUnavailable), "Error is not unavailable")
+	assert.Equal(suite.T(), int32(2), requests, "Number of requests is not 2")
+	assert.Equal(suite.T(), requests, suite.requests, "Request is sent while circuit is open")
+}
+
+func (suite *UserRESTClientUnitTestSuite) TestUserRESTClient_GetUsers_SendsSingleRequest() {
+	users, err := suite.client.GetUsers([]int{1, 2}, context.TODO())
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+	assert.Equal(suite.T(), 2, len(users), "Number of users is not 2")
+	assert.Equal(suite.T(), "1,2", suite.query, "Ids are not sent")
+	assert.Equal(suite.T(), int32(1), suite.requests, "Number of requests is not 1")
+}
+
+func (suite *UserRESTClientUnitTestSuite) TestUserRESTClient_GetUsers_WithoutIdsSendsNoRequest() {
+	users, err := suite.client.GetUsers([]int{}, context.TODO())
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+	assert.Equal(suite.T(), 0, len(users), "Number of users is not 0")
+	assert.Equal(suite.T(), int32(0), suite.requests, "Request is sent")
+}
+
+func (suite *UserRESTClientUnitTestSuite) TestUserRESTClient_GetUsers_ServerErrorIsUnavailable() {
+	suite.status = http.StatusBadGateway
+
+	_, err := suite.client.GetUsers([]int{1, 2}, context.TODO())
+
+	assert.True(suite.T(), errors.Is(err, ErrUnavailable), "Error is not unavailable")
+}
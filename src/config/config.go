@@ -0,0 +1,672 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	setupJaeger "posts-ms/src/config/jaeger"
+	"posts-ms/src/service"
+	"posts-ms/src/utils"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	SyncMediaUpload  = "sync"
+	AsyncMediaUpload = "async"
+)
+
+const (
+	PostgresStorage = "postgres"
+	MemoryStorage   = "memory"
+)
+
+// Config holds every setting of the service. It is loaded once at startup by
+// Load and handed to the components that need it.
+type Config struct {
+	Server       ServerConfig              `yaml:"server"`
+	Startup      StartupConfig             `yaml:"startup"`
+	Storage      string                    `yaml:"storage"`
+	Database     DatabaseConfig            `yaml:"database"`
+	RabbitMQ     RabbitMQConfig            `yaml:"rabbitmq"`
+	UserService  UserServiceConfig         `yaml:"userService"`
+	MediaService MediaServiceConfig        `yaml:"mediaService"`
+	Events       EventsConfig              `yaml:"events"`
+	Idempotency  IdempotencyConfig         `yaml:"idempotency"`
+	RateLimit    RateLimitConfig           `yaml:"rateLimit"`
+	Notification NotificationConfig        `yaml:"notification"`
+	Moderation   ModerationConfig          `yaml:"moderation"`
+	Health       HealthConfig              `yaml:"health"`
+	Tracing      setupJaeger.TracingConfig `yaml:"tracing"`
+	Logging      utils.LogConfig           `yaml:"logging"`
+}
+
+// ServerConfig configures the HTTP server. On shutdown the server keeps
+// serving for DrainDelay while readiness reports it as down, so that load
+// balancers stop sending requests, and then waits up to ShutdownTimeout for
+// the requests in flight.
+type ServerConfig struct {
+	Port            string        `yaml:"port"`
+	ReadTimeout     time.Duration `yaml:"readTimeout"`
+	WriteTimeout    time.Duration `yaml:"writeTimeout"`
+	IdleTimeout     time.Duration `yaml:"idleTimeout"`
+	DrainDelay      time.Duration `yaml:"drainDelay"`
+	ShutdownTimeout time.Duration `yaml:"shutdownTimeout"`
+}
+
+// StartupConfig controls how long the service waits for the database and
+// the broker to become available before giving up.
+type StartupConfig struct {
+	Attempts int           `yaml:"attempts"`
+	Backoff  time.Duration `yaml:"backoff"`
+}
+
+// DatabaseConfig connects to Postgres. With AutoMigrate set, pending
+// migrations are applied on startup instead of through the migrate command.
+type DatabaseConfig struct {
+	Host        string `yaml:"host"`
+	Port        string `yaml:"port"`
+	Username    string `yaml:"username"`
+	Password    string `yaml:"password"`
+	Schema      string `yaml:"schema"`
+	AutoMigrate bool   `yaml:"autoMigrate"`
+}
+
+type RabbitMQConfig struct {
+	URL string `yaml:"url"`
+}
+
+type UserServiceConfig struct {
+	Domain        string        `yaml:"domain"`
+	Timeout       time.Duration `yaml:"timeout"`
+	CacheSize     int           `yaml:"cacheSize"`
+	CacheTTL      time.Duration `yaml:"cacheTtl"`
+	UpdatedEvents bool          `yaml:"updatedEvents"`
+}
+
+type MediaServiceConfig struct {
+	URL        string `yaml:"url"`
+	UploadMode string `yaml:"uploadMode"`
+}
+
+// EventsConfig controls the delivery of system events to events-ms. Events
+// that could not be delivered are kept in SpoolFile and sent again every
+// ReplayInterval.
+type EventsConfig struct {
+	URL            string        `yaml:"url"`
+	Timeout        time.Duration `yaml:"timeout"`
+	QueueSize      int           `yaml:"queueSize"`
+	Workers        int           `yaml:"workers"`
+	RetryAttempts  int           `yaml:"retryAttempts"`
+	RetryBackoff   time.Duration `yaml:"retryBackoff"`
+	SpoolFile      string        `yaml:"spoolFile"`
+	ReplayInterval time.Duration `yaml:"replayInterval"`
+}
+
+// IdempotencyConfig keeps idempotency keys for KeyTTL. A request in progress
+// holds its key for at most LockTimeout.
+type IdempotencyConfig struct {
+	KeyTTL      time.Duration `yaml:"keyTtl"`
+	LockTimeout time.Duration `yaml:"lockTimeout"`
+}
+
+// RateLimitConfig limits routes keyed by method and route template, for
+// example "POST /api/likes". Limits are written as <requests>/<duration>.
+type RateLimitConfig struct {
+	Enabled    bool                        `yaml:"enabled"`
+	TrustProxy bool                        `yaml:"trustProxy"`
+	Routes     map[string]RouteLimitConfig `yaml:"routes"`
+}
+
+type RouteLimitConfig struct {
+	User string `yaml:"user"`
+	IP   string `yaml:"ip"`
+}
+
+// NotificationConfig batches the reactions to a post that arrive within
+// AggregationWindow into one notification and does not notify a user's
+// repeated reactions to the same post within DedupeWindow. Messages are
+// written in the recipient's locale, or in DefaultLocale when there are no
+// templates for it.
+type NotificationConfig struct {
+	AggregationWindow time.Duration `yaml:"aggregationWindow"`
+	DedupeWindow      time.Duration `yaml:"dedupeWindow"`
+	DefaultLocale     string        `yaml:"defaultLocale"`
+}
+
+// ModerationConfig lists the words and regular expressions that hold posts
+// and comments for review or reject them. Words match whole words regardless
+// of case.
+type ModerationConfig struct {
+	Hold   ModerationRulesConfig `yaml:"hold"`
+	Reject ModerationRulesConfig `yaml:"reject"`
+}
+
+type ModerationRulesConfig struct {
+	Words    []string `yaml:"words"`
+	Patterns []string `yaml:"patterns"`
+}
+
+// HealthConfig bounds every readiness check by Timeout. users-ms and media-ms
+// are only probed when CheckDependencies is set.
+type HealthConfig struct {
+	Timeout           time.Duration `yaml:"timeout"`
+	CheckDependencies bool          `yaml:"checkDependencies"`
+}
+
+// Limits parses the configured route limits.
+func (c RateLimitConfig) Limits() (map[string]service.RouteRateLimit, error) {
+	limits := map[string]service.RouteRateLimit{}
+
+	for route, config := range c.Routes {
+		if len(strings.Fields(route)) != 2 {
+			return nil, fmt.Errorf("rate limited route %q is not <method> <route>", route)
+		}
+
+		var limit service.RouteRateLimit
+		var err error
+
+		if config.User != "" {
+			if limit.User, err = service.ParseRateLimit(config.User); err != nil {
+				return nil, fmt.Errorf("%s: %w", route, err)
+			}
+		}
+
+		if config.IP != "" {
+			if limit.IP, err = service.ParseRateLimit(config.IP); err != nil {
+				return nil, fmt.Errorf("%s: %w", route, err)
+			}
+		}
+
+		limits[route] = limit
+	}
+
+	return limits, nil
+}
+
+// Moderator builds the moderation pipeline of the built-in word and pattern
+// filter.
+func (c ModerationConfig) Moderator() (service.IModerator, error) {
+	filter, err := service.NewFilterModerator(service.FilterRules{
+		HoldWords:      c.Hold.Words,
+		HoldPatterns:   c.Hold.Patterns,
+		RejectWords:    c.Reject.Words,
+		RejectPatterns: c.Reject.Patterns,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return service.ModerationPipeline{filter}, nil
+}
+
+func (c UserServiceConfig) URL() string {
+	return fmt.Sprintf("http://%s", c.Domain)
+}
+
+func Default() Config {
+	return Config{
+		Server: ServerConfig{
+			ReadTimeout:     15 * time.Second,
+			WriteTimeout:    30 * time.Second,
+			IdleTimeout:     60 * time.Second,
+			DrainDelay:      5 * time.Second,
+			ShutdownTimeout: 20 * time.Second,
+		},
+		Startup: StartupConfig{
+			Attempts: 10,
+			Backoff:  2 * time.Second,
+		},
+		Storage: PostgresStorage,
+		Database: DatabaseConfig{
+			Port: "5432",
+		},
+		UserService: UserServiceConfig{
+			Timeout:   2 * time.Second,
+			CacheSize: 1000,
+			CacheTTL:  5 * time.Minute,
+		},
+		MediaService: MediaServiceConfig{
+			URL:        "http://medias-server:8082",
+			UploadMode: SyncMediaUpload,
+		},
+		Events: EventsConfig{
+			Timeout:        2 * time.Second,
+			QueueSize:      1000,
+			Workers:        2,
+			RetryAttempts:  3,
+			RetryBackoff:   200 * time.Millisecond,
+			SpoolFile:      "./spool/events.jsonl",
+			ReplayInterval: 30 * time.Second,
+		},
+		Idempotency: IdempotencyConfig{
+			KeyTTL:      24 * time.Hour,
+			LockTimeout: 2 * time.Minute,
+		},
+		RateLimit: RateLimitConfig{
+			Enabled: true,
+			Routes: map[string]RouteLimitConfig{
+				"POST /api/posts":        {User: "10/1m", IP: "60/1m"},
+				"DELETE /api/posts/{id}": {IP: "60/1m"},
+				"POST /api/likes":        {User: "30/1m", IP: "120/1m"},
+				"DELETE /api/likes/users/{userId}/posts/{postId}": {User: "30/1m", IP: "120/1m"},
+				"POST /api/comments":                              {User: "20/1m", IP: "120/1m"},
+				"DELETE /api/comments/{id}":                       {IP: "120/1m"},
+			},
+		},
+		Notification: NotificationConfig{
+			AggregationWindow: 30 * time.Second,
+			DedupeWindow:      10 * time.Minute,
+			DefaultLocale:     "en",
+		},
+		Health: HealthConfig{
+			Timeout: 2 * time.Second,
+		},
+		Tracing: setupJaeger.DefaultTracingConfig(),
+		Logging: utils.DefaultLogConfig(),
+	}
+}
+
+// Load builds the configuration from the defaults, the YAML file named by
+// CONFIG_FILE when it is set and the environment, in that order of
+// precedence, and validates the result.
+func Load() (Config, error) {
+	cfg, env, err := read()
+
+	if err != nil {
+		return cfg, err
+	}
+
+	env.string("SERVER_PORT", &cfg.Server.Port)
+	env.duration("SERVER_READ_TIMEOUT", &cfg.Server.ReadTimeout)
+	env.duration("SERVER_WRITE_TIMEOUT", &cfg.Server.WriteTimeout)
+	env.duration("SERVER_IDLE_TIMEOUT", &cfg.Server.IdleTimeout)
+	env.duration("SERVER_DRAIN_DELAY", &cfg.Server.DrainDelay)
+	env.duration("SERVER_SHUTDOWN_TIMEOUT", &cfg.Server.ShutdownTimeout)
+	env.string("STORAGE_BACKEND", &cfg.Storage)
+	env.string("AMQP_SERVER_URL", &cfg.RabbitMQ.URL)
+	env.string("USER_SERVICE_DOMAIN", &cfg.UserService.Domain)
+	env.duration("USER_SERVICE_TIMEOUT", &cfg.UserService.Timeout)
+	env.int("USER_CACHE_SIZE", &cfg.UserService.CacheSize)
+	env.duration("USER_CACHE_TTL", &cfg.UserService.CacheTTL)
+	env.bool("USER_UPDATED_EVENTS", &cfg.UserService.UpdatedEvents)
+	env.string("MEDIA_SERVICE_URL", &cfg.MediaService.URL)
+	env.string("MEDIA_UPLOAD_MODE", &cfg.MediaService.UploadMode)
+	env.string("EVENTS_MS", &cfg.Events.URL)
+	env.duration("EVENTS_TIMEOUT", &cfg.Events.Timeout)
+	env.int("EVENTS_QUEUE_SIZE", &cfg.Events.QueueSize)
+	env.int("EVENTS_WORKERS", &cfg.Events.Workers)
+	env.int("EVENTS_RETRY_ATTEMPTS", &cfg.Events.RetryAttempts)
+	env.duration("EVENTS_RETRY_BACKOFF", &cfg.Events.RetryBackoff)
+	env.string("EVENTS_SPOOL_FILE", &cfg.Events.SpoolFile)
+	env.duration("EVENTS_REPLAY_INTERVAL", &cfg.Events.ReplayInterval)
+	env.duration("IDEMPOTENCY_KEY_TTL", &cfg.Idempotency.KeyTTL)
+	env.duration("IDEMPOTENCY_LOCK_TIMEOUT", &cfg.Idempotency.LockTimeout)
+	env.bool("RATE_LIMIT_ENABLED", &cfg.RateLimit.Enabled)
+	env.bool("RATE_LIMIT_TRUST_PROXY", &cfg.RateLimit.TrustProxy)
+	env.routeLimits("RATE_LIMIT_ROUTES", &cfg.RateLimit.Routes)
+	env.duration("NOTIFICATION_AGGREGATION_WINDOW", &cfg.Notification.AggregationWindow)
+	env.duration("NOTIFICATION_DEDUPE_WINDOW", &cfg.Notification.DedupeWindow)
+	env.string("NOTIFICATION_DEFAULT_LOCALE", &cfg.Notification.DefaultLocale)
+	env.list("MODERATION_HOLD_WORDS", &cfg.Moderation.Hold.Words)
+	env.list("MODERATION_REJECT_WORDS", &cfg.Moderation.Reject.Words)
+	env.pattern("MODERATION_HOLD_PATTERN", &cfg.Moderation.Hold.Patterns)
+	env.pattern("MODERATION_REJECT_PATTERN", &cfg.Moderation.Reject.Patterns)
+	env.duration("HEALTH_CHECK_TIMEOUT", &cfg.Health.Timeout)
+	env.bool("HEALTH_CHECK_DEPENDENCIES", &cfg.Health.CheckDependencies)
+	env.string("LOG_LEVEL", &cfg.Logging.Level)
+	env.string("LOG_OUTPUT", &cfg.Logging.Output)
+	env.string("LOG_FILE", &cfg.Logging.File)
+	env.int("LOG_MAX_SIZE_MB", &cfg.Logging.MaxSizeMB)
+	env.int("LOG_MAX_BACKUPS", &cfg.Logging.MaxBackups)
+
+	if err := cfg.Tracing.LoadFromEnv(); err != nil {
+		env.errors = append(env.errors, err.Error())
+	}
+
+	return cfg, invalid(append(env.errors, cfg.validate()...))
+}
+
+// LoadMigration builds the configuration like Load, but reads and validates
+// only the database and startup settings the migrate command uses.
+func LoadMigration() (Config, error) {
+	cfg, env, err := read()
+
+	if err != nil {
+		return cfg, err
+	}
+
+	problems := append(env.errors, cfg.Startup.validate()...)
+
+	return cfg, invalid(append(problems, cfg.Database.validate()...))
+}
+
+// read applies the config file and the startup and database environment
+// variables to the defaults.
+func read() (Config, envReader, error) {
+	cfg := Default()
+	env := envReader{}
+
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		content, err := ioutil.ReadFile(path)
+
+		if err != nil {
+			return cfg, env, fmt.Errorf("reading config file: %w", err)
+		}
+
+		if err := yaml.Unmarshal(content, &cfg); err != nil {
+			return cfg, env, fmt.Errorf("parsing config file %s: %w", path, err)
+		}
+	}
+
+	env.int("STARTUP_RETRY_ATTEMPTS", &cfg.Startup.Attempts)
+	env.duration("STARTUP_RETRY_BACKOFF", &cfg.Startup.Backoff)
+	env.string("DATABASE_DOMAIN", &cfg.Database.Host)
+	env.string("DATABASE_PORT", &cfg.Database.Port)
+	env.string("DATABASE_USERNAME", &cfg.Database.Username)
+	env.string("DATABASE_PASSWORD", &cfg.Database.Password)
+	env.string("DATABASE_SCHEMA", &cfg.Database.Schema)
+	env.bool("DATABASE_AUTO_MIGRATE", &cfg.Database.AutoMigrate)
+
+	return cfg, env, nil
+}
+
+func invalid(problems []string) error {
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid configuration: %s", strings.Join(problems, "; "))
+	}
+
+	return nil
+}
+
+func (c Config) validate() []string {
+	var problems []string
+
+	required := []struct {
+		name  string
+		value string
+	}{
+		{"SERVER_PORT", c.Server.Port},
+		{"AMQP_SERVER_URL", c.RabbitMQ.URL},
+		{"USER_SERVICE_DOMAIN", c.UserService.Domain},
+		{"MEDIA_SERVICE_URL", c.MediaService.URL},
+		{"EVENTS_MS", c.Events.URL},
+	}
+
+	switch c.Storage {
+	case PostgresStorage:
+		problems = append(problems, c.Database.validate()...)
+	case MemoryStorage:
+	default:
+		problems = append(problems, fmt.Sprintf("STORAGE_BACKEND must be %q or %q", PostgresStorage, MemoryStorage))
+	}
+
+	for _, field := range required {
+		if strings.TrimSpace(field.value) == "" {
+			problems = append(problems, fmt.Sprintf("%s is required", field.name))
+		}
+	}
+
+	if c.MediaService.UploadMode != SyncMediaUpload && c.MediaService.UploadMode != AsyncMediaUpload {
+		problems = append(problems, fmt.Sprintf("MEDIA_UPLOAD_MODE must be %q or %q", SyncMediaUpload, AsyncMediaUpload))
+	}
+
+	if _, err := c.Moderation.Moderator(); err != nil {
+		problems = append(problems, fmt.Sprintf("moderation rules are invalid: %v", err))
+	}
+
+	if _, err := service.LoadNotificationTemplates(c.Notification.DefaultLocale); err != nil {
+		problems = append(problems, fmt.Sprintf("NOTIFICATION_DEFAULT_LOCALE is invalid: %v", err))
+	}
+
+	problems = append(problems, c.Startup.validate()...)
+
+	if c.Server.DrainDelay < 0 {
+		problems = append(problems, "SERVER_DRAIN_DELAY must not be negative")
+	}
+
+	positive := []struct {
+		name  string
+		value time.Duration
+	}{
+		{"SERVER_READ_TIMEOUT", c.Server.ReadTimeout},
+		{"SERVER_WRITE_TIMEOUT", c.Server.WriteTimeout},
+		{"SERVER_IDLE_TIMEOUT", c.Server.IdleTimeout},
+		{"SERVER_SHUTDOWN_TIMEOUT", c.Server.ShutdownTimeout},
+		{"USER_SERVICE_TIMEOUT", c.UserService.Timeout},
+		{"USER_CACHE_TTL", c.UserService.CacheTTL},
+		{"IDEMPOTENCY_KEY_TTL", c.Idempotency.KeyTTL},
+		{"IDEMPOTENCY_LOCK_TIMEOUT", c.Idempotency.LockTimeout},
+		{"HEALTH_CHECK_TIMEOUT", c.Health.Timeout},
+		{"EVENTS_TIMEOUT", c.Events.Timeout},
+		{"EVENTS_RETRY_BACKOFF", c.Events.RetryBackoff},
+		{"EVENTS_REPLAY_INTERVAL", c.Events.ReplayInterval},
+		{"NOTIFICATION_AGGREGATION_WINDOW", c.Notification.AggregationWindow},
+		{"NOTIFICATION_DEDUPE_WINDOW", c.Notification.DedupeWindow},
+	}
+
+	for _, field := range positive {
+		if field.value <= 0 {
+			problems = append(problems, fmt.Sprintf("%s must be positive", field.name))
+		}
+	}
+
+	counts := []struct {
+		name  string
+		value int
+	}{
+		{"EVENTS_QUEUE_SIZE", c.Events.QueueSize},
+		{"EVENTS_WORKERS", c.Events.Workers},
+		{"EVENTS_RETRY_ATTEMPTS", c.Events.RetryAttempts},
+	}
+
+	for _, field := range counts {
+		if field.value <= 0 {
+			problems = append(problems, fmt.Sprintf("%s must be positive", field.name))
+		}
+	}
+
+	if _, err := logrus.ParseLevel(c.Logging.Level); err != nil {
+		problems = append(problems, fmt.Sprintf("LOG_LEVEL %q is not a log level", c.Logging.Level))
+	}
+
+	switch c.Logging.Output {
+	case utils.StdoutLogOutput:
+	case utils.FileLogOutput:
+		if strings.TrimSpace(c.Logging.File) == "" {
+			problems = append(problems, "LOG_FILE is required")
+		}
+
+		if c.Logging.MaxSizeMB <= 0 {
+			problems = append(problems, "LOG_MAX_SIZE_MB must be positive")
+		}
+
+		if c.Logging.MaxBackups < 0 {
+			problems = append(problems, "LOG_MAX_BACKUPS must not be negative")
+		}
+	default:
+		problems = append(problems, fmt.Sprintf("LOG_OUTPUT must be %q or %q", utils.StdoutLogOutput, utils.FileLogOutput))
+	}
+
+	if c.RateLimit.Enabled {
+		if _, err := c.RateLimit.Limits(); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+
+	if c.UserService.CacheSize <= 0 {
+		problems = append(problems, "USER_CACHE_SIZE must be positive")
+	}
+
+	return problems
+}
+
+func (c StartupConfig) validate() []string {
+	var problems []string
+
+	if c.Attempts <= 0 {
+		problems = append(problems, "STARTUP_RETRY_ATTEMPTS must be positive")
+	}
+
+	if c.Backoff <= 0 {
+		problems = append(problems, "STARTUP_RETRY_BACKOFF must be positive")
+	}
+
+	return problems
+}
+
+func (c DatabaseConfig) validate() []string {
+	var problems []string
+
+	required := []struct {
+		name  string
+		value string
+	}{
+		{"DATABASE_DOMAIN", c.Host},
+		{"DATABASE_PORT", c.Port},
+		{"DATABASE_USERNAME", c.Username},
+		{"DATABASE_SCHEMA", c.Schema},
+	}
+
+	for _, field := range required {
+		if strings.TrimSpace(field.value) == "" {
+			problems = append(problems, fmt.Sprintf("%s is required", field.name))
+		}
+	}
+
+	return problems
+}
+
+// envReader overrides configuration values with the environment variables
+// that are set, collecting parse errors instead of stopping at the first one.
+type envReader struct {
+	errors []string
+}
+
+func (r *envReader) string(key string, target *string) {
+	if value := os.Getenv(key); value != "" {
+		*target = strings.TrimSpace(value)
+	}
+}
+
+func (r *envReader) int(key string, target *int) {
+	r.parse(key, func(value string) error {
+		parsed, err := strconv.Atoi(value)
+
+		if err == nil {
+			*target = parsed
+		}
+
+		return err
+	})
+}
+
+func (r *envReader) bool(key string, target *bool) {
+	r.parse(key, func(value string) error {
+		parsed, err := strconv.ParseBool(value)
+
+		if err == nil {
+			*target = parsed
+		}
+
+		return err
+	})
+}
+
+func (r *envReader) duration(key string, target *time.Duration) {
+	r.parse(key, func(value string) error {
+		parsed, err := time.ParseDuration(value)
+
+		if err == nil {
+			*target = parsed
+		}
+
+		return err
+	})
+}
+
+// list reads a comma separated list. It replaces the configured one.
+func (r *envReader) list(key string, target *[]string) {
+	r.parse(key, func(value string) error {
+		var items []string
+
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+
+		*target = items
+
+		return nil
+	})
+}
+
+// pattern reads a single regular expression. It replaces the configured
+// ones, alternatives are combined with |.
+func (r *envReader) pattern(key string, target *[]string) {
+	r.parse(key, func(value string) error {
+		*target = []string{value}
+
+		return nil
+	})
+}
+
+// routeLimits reads route limits written as
+// "POST /api/likes user=30/1m ip=120/1m; DELETE /api/comments/{id} ip=60/1m".
+// The routes replace the configured ones.
+func (r *envReader) routeLimits(key string, target *map[string]RouteLimitConfig) {
+	r.parse(key, func(value string) error {
+		routes := map[string]RouteLimitConfig{}
+
+		for _, entry := range strings.Split(value, ";") {
+			fields := strings.Fields(entry)
+
+			if len(fields) == 0 {
+				continue
+			}
+
+			if len(fields) < 3 {
+				return fmt.Errorf("%q is not <method> <route> <scope>=<limit>...", strings.TrimSpace(entry))
+			}
+
+			limits := RouteLimitConfig{}
+
+			for _, field := range fields[2:] {
+				parts := strings.SplitN(field, "=", 2)
+
+				switch {
+				case len(parts) == 2 && parts[0] == "user":
+					limits.User = parts[1]
+				case len(parts) == 2 && parts[0] == "ip":
+					limits.IP = parts[1]
+				default:
+					return fmt.Errorf("%q is not user=<limit> or ip=<limit>", field)
+				}
+			}
+
+			routes[fields[0]+" "+fields[1]] = limits
+		}
+
+		*target = routes
+
+		return nil
+	})
+}
+
+func (r *envReader) parse(key string, set func(string) error) {
+	value := strings.TrimSpace(os.Getenv(key))
+
+	if value == "" {
+		return
+	}
+
+	if err := set(value); err != nil {
+		r.errors = append(r.errors, fmt.Sprintf("%s: %v", key, err))
+	}
+}
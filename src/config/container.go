@@ -10,29 +10,36 @@ type ControllerContainer struct {
 	PostController    controller.PostController
 	LikeController    controller.LikeController
 	CommentController controller.CommentController
+	HealthController  controller.HealthController
 }
 
 type ServiceContainer struct {
-	PostService    service.IPostService
-	LikeService    service.ILikeService
-	CommentService service.CommentService
+	PostService        service.IPostService
+	LikeService        service.ILikeService
+	CommentService     service.CommentService
+	IdempotencyService service.IIdempotencyService
+	AuthorService      service.IAuthorService
+	HealthService      service.IHealthService
 }
 
 type RepositoryContainer struct {
-	PostRepository    repository.IPostRepository
-	LikeRepository    repository.ILikeRepository
-	CommentRepository repository.ICommentRepository
+	PostRepository           repository.IPostRepository
+	LikeRepository           repository.ILikeRepository
+	CommentRepository        repository.ICommentRepository
+	IdempotencyKeyRepository repository.IIdempotencyKeyRepository
 }
 
 func NewControllerContainer(
 	postController controller.PostController,
 	likeController controller.LikeController,
 	commentController controller.CommentController,
+	healthController controller.HealthController,
 ) ControllerContainer {
 	return ControllerContainer{
 		PostController:    postController,
 		LikeController:    likeController,
 		CommentController: commentController,
+		HealthController:  healthController,
 	}
 }
 
@@ -40,11 +47,17 @@ func NewServiceContainer(
 	postService service.IPostService,
 	likeService service.ILikeService,
 	commentService service.CommentService,
+	idempotencyService service.IIdempotencyService,
+	authorService service.IAuthorService,
+	healthService service.IHealthService,
 ) ServiceContainer {
 	return ServiceContainer{
-		PostService:    postService,
-		LikeService:    likeService,
-		CommentService: commentService,
+		PostService:        postService,
+		LikeService:        likeService,
+		CommentService:     commentService,
+		IdempotencyService: idempotencyService,
+		AuthorService:      authorService,
+		HealthService:      healthService,
 	}
 }
 
@@ -52,10 +65,12 @@ func NewRepositoryContainer(
 	postRepository repository.IPostRepository,
 	likeRepository repository.ILikeRepository,
 	commentRepository repository.ICommentRepository,
+	idempotencyKeyRepository repository.IIdempotencyKeyRepository,
 ) RepositoryContainer {
 	return RepositoryContainer{
-		PostRepository:    postRepository,
-		LikeRepository:    likeRepository,
-		CommentRepository: commentRepository,
+		PostRepository:           postRepository,
+		LikeRepository:           likeRepository,
+		CommentRepository:        commentRepository,
+		IdempotencyKeyRepository: idempotencyKeyRepository,
 	}
 }
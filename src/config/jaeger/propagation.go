@@ -0,0 +1,49 @@
+package setupJaeger
+
+import (
+	"github.com/opentracing/opentracing-go"
+	"github.com/uber/jaeger-client-go"
+	"github.com/uber/jaeger-client-go/zipkin"
+)
+
+type propagator interface {
+	jaeger.Injector
+	jaeger.Extractor
+}
+
+// httpHeadersPropagator writes both uber-trace-id and B3 headers and accepts
+// whichever of the two the caller sent.
+type httpHeadersPropagator struct {
+	propagators []propagator
+}
+
+func newHTTPHeadersPropagator() httpHeadersPropagator {
+	return httpHeadersPropagator{
+		propagators: []propagator{
+			jaeger.NewHTTPHeaderPropagator(new(jaeger.HeadersConfig).ApplyDefaults(), *jaeger.NewNullMetrics()),
+			zipkin.NewZipkinB3HTTPHeaderPropagator(),
+		},
+	}
+}
+
+func (p httpHeadersPropagator) Inject(spanContext jaeger.SpanContext, carrier interface{}) error {
+	for _, propagator := range p.propagators {
+		if err := propagator.Inject(spanContext, carrier); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p httpHeadersPropagator) Extract(carrier interface{}) (jaeger.SpanContext, error) {
+	for _, propagator := range p.propagators {
+		spanContext, err := propagator.Extract(carrier)
+
+		if err == nil && spanContext.IsValid() {
+			return spanContext, nil
+		}
+	}
+
+	return jaeger.SpanContext{}, opentracing.ErrSpanContextNotFound
+}
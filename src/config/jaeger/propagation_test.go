@@ -0,0 +1,61 @@
+package setupJaeger
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/uber/jaeger-client-go"
+)
+
+type PropagationUnitTestSuite struct {
+	suite.Suite
+	tracer opentracing.Tracer
+}
+
+func TestPropagationUnitTestSuite(t *testing.T) {
+	suite.Run(t, new(PropagationUnitTestSuite))
+}
+
+func (suite *PropagationUnitTestSuite) SetupSuite() {
+	propagator := newHTTPHeadersPropagator()
+
+	suite.tracer, _ = jaeger.NewTracer(
+		"posts-ms",
+		jaeger.NewConstSampler(true),
+		jaeger.NewNullReporter(),
+		jaeger.TracerOptions.Injector(opentracing.HTTPHeaders, propagator),
+		jaeger.TracerOptions.Extractor(opentracing.HTTPHeaders, propagator),
+	)
+}
+
+func (suite *PropagationUnitTestSuite) TestPropagation_Inject_WritesJaegerAndB3Headers() {
+	span := suite.tracer.StartSpan("test")
+	header := http.Header{}
+
+	err := suite.tracer.Inject(span.Context(), opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(header))
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+	assert.NotEmpty(suite.T(), header.Get("uber-trace-id"), "Jaeger header is missing")
+	assert.NotEmpty(suite.T(), header.Get("x-b3-traceid"), "B3 header is missing")
+}
+
+func (suite *PropagationUnitTestSuite) TestPropagation_Extract_ReadsB3Headers() {
+	header := http.Header{}
+	header.Set("x-b3-traceid", "463ac35c9f6413ad")
+	header.Set("x-b3-spanid", "a2fb4a1d1a96d312")
+	header.Set("x-b3-sampled", "1")
+
+	spanContext, err := suite.tracer.Extract(opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(header))
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+	assert.Equal(suite.T(), "463ac35c9f6413ad", spanContext.(jaeger.SpanContext).TraceID().String(), "Trace id is not extracted")
+}
+
+func (suite *PropagationUnitTestSuite) TestPropagation_Extract_WithoutHeadersReturnsNotFound() {
+	_, err := suite.tracer.Extract(opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(http.Header{}))
+
+	assert.Equal(suite.T(), opentracing.ErrSpanContextNotFound, err, "Error is not span context not found")
+}
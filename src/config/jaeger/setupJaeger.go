@@ -1,27 +1,133 @@
 package setupJaeger
 
 import (
+	"context"
+	"fmt"
 	"io"
+	"strings"
+	"time"
 
 	"github.com/opentracing/opentracing-go"
 	"github.com/uber/jaeger-client-go"
 	"github.com/uber/jaeger-client-go/config"
+	jaegerPropagator "go.opentelemetry.io/contrib/propagators/jaeger"
+	otelBridge "go.opentelemetry.io/otel/bridge/opentracing"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.10.0"
 )
 
-func InitJaeger() (opentracing.Tracer, io.Closer, error) {
-	cfg := config.Configuration{
-		ServiceName: "posts-ms",
+type closerFunc func() error
+
+func (f closerFunc) Close() error {
+	return f()
+}
+
+func InitTracer(cfg TracingConfig) (opentracing.Tracer, io.Closer, error) {
+	if !cfg.Enabled {
+		return opentracing.NoopTracer{}, closerFunc(func() error { return nil }), nil
+	}
+
+	if cfg.Exporter == OTLPExporter {
+		return initOTLPTracer(cfg)
+	}
+
+	return initJaegerTracer(cfg)
+}
+
+func initJaegerTracer(cfg TracingConfig) (opentracing.Tracer, io.Closer, error) {
+	reporter := &config.ReporterConfig{
+		LogSpans: cfg.LogSpans,
+	}
+
+	if strings.HasPrefix(cfg.Endpoint, "http://") || strings.HasPrefix(cfg.Endpoint, "https://") {
+		reporter.CollectorEndpoint = cfg.Endpoint
+	} else {
+		reporter.LocalAgentHostPort = cfg.Endpoint
+	}
+
+	jaegerCfg := config.Configuration{
+		ServiceName: cfg.ServiceName,
 		Sampler: &config.SamplerConfig{
-			Type:  "const",
-			Param: 1,
-		},
-		Reporter: &config.ReporterConfig{
-			LogSpans:           true,
-			LocalAgentHostPort: "jaeger:6831",
+			Type:  cfg.SamplerType,
+			Param: cfg.SamplerParam,
 		},
+		Reporter: reporter,
 	}
 
-	tracer, closer, err := cfg.NewTracer(config.Logger(jaeger.StdLogger))
+	propagator := newHTTPHeadersPropagator()
+
+	tracer, closer, err := jaegerCfg.NewTracer(
+		config.Logger(jaeger.StdLogger),
+		config.Injector(opentracing.HTTPHeaders, propagator),
+		config.Extractor(opentracing.HTTPHeaders, propagator),
+	)
 
 	return tracer, closer, err
 }
+
+// initOTLPTracer exports spans over OTLP/HTTP while the rest of the service
+// keeps using the OpenTracing API through the OpenTelemetry bridge.
+func initOTLPTracer(cfg TracingConfig) (opentracing.Tracer, io.Closer, error) {
+	sampler, err := newOTLPSampler(cfg.SamplerType, cfg.SamplerParam)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	options := []otlptracehttp.Option{otlptracehttp.WithEndpoint(cfg.Endpoint)}
+
+	if cfg.Insecure {
+		options = append(options, otlptracehttp.WithInsecure())
+	}
+
+	exporter, err := otlptracehttp.New(context.Background(), options...)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sampler),
+		sdktrace.WithResource(resource.NewWithAttributes(
+			semconv.SchemaURL,
+			semconv.ServiceNameKey.String(cfg.ServiceName),
+		)),
+	)
+
+	tracer, _ := otelBridge.NewTracerPair(provider.Tracer(cfg.ServiceName))
+
+	tracer.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+		jaegerPropagator.Jaeger{},
+	))
+
+	closer := closerFunc(func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+
+		defer cancel()
+
+		return provider.Shutdown(ctx)
+	})
+
+	return tracer, closer, nil
+}
+
+func newOTLPSampler(samplerType string, param float64) (sdktrace.Sampler, error) {
+	switch samplerType {
+	case jaeger.SamplerTypeConst:
+		if param >= 1 {
+			return sdktrace.ParentBased(sdktrace.AlwaysSample()), nil
+		}
+
+		return sdktrace.ParentBased(sdktrace.NeverSample()), nil
+	case jaeger.SamplerTypeProbabilistic:
+		return sdktrace.ParentBased(sdktrace.TraceIDRatioBased(param)), nil
+	}
+
+	return nil, fmt.Errorf("sampler type %q is not supported by the otlp exporter", samplerType)
+}
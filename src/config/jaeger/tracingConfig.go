@@ -0,0 +1,102 @@
+package setupJaeger
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+)
+
+const (
+	JaegerExporter = "jaeger"
+	OTLPExporter   = "otlp"
+)
+
+type TracingConfig struct {
+	Enabled      bool    `yaml:"enabled"`
+	Exporter     string  `yaml:"exporter"`
+	Endpoint     string  `yaml:"endpoint"`
+	Insecure     bool    `yaml:"insecure"`
+	ServiceName  string  `yaml:"serviceName"`
+	SamplerType  string  `yaml:"samplerType"`
+	SamplerParam float64 `yaml:"samplerParam"`
+	LogSpans     bool    `yaml:"logSpans"`
+}
+
+func DefaultTracingConfig() TracingConfig {
+	return TracingConfig{
+		Enabled:      true,
+		Exporter:     JaegerExporter,
+		Insecure:     true,
+		ServiceName:  "posts-ms",
+		SamplerType:  "const",
+		SamplerParam: 1,
+	}
+}
+
+// LoadFromEnv overrides the fields whose TRACING_* variable is set and
+// validates the result, filling in the default endpoint of the exporter.
+func (cfg *TracingConfig) LoadFromEnv() error {
+	var err error
+
+	cfg.Exporter = getEnv("TRACING_EXPORTER", cfg.Exporter)
+	cfg.Endpoint = getEnv("TRACING_ENDPOINT", cfg.Endpoint)
+	cfg.ServiceName = getEnv("TRACING_SERVICE_NAME", cfg.ServiceName)
+	cfg.SamplerType = getEnv("TRACING_SAMPLER_TYPE", cfg.SamplerType)
+
+	if cfg.Enabled, err = getEnvBool("TRACING_ENABLED", cfg.Enabled); err != nil {
+		return err
+	}
+
+	if cfg.Insecure, err = getEnvBool("TRACING_INSECURE", cfg.Insecure); err != nil {
+		return err
+	}
+
+	if cfg.LogSpans, err = getEnvBool("TRACING_LOG_SPANS", cfg.LogSpans); err != nil {
+		return err
+	}
+
+	if value := os.Getenv("TRACING_SAMPLER_PARAM"); value != "" {
+		if cfg.SamplerParam, err = strconv.ParseFloat(value, 64); err != nil {
+			return fmt.Errorf("TRACING_SAMPLER_PARAM: %w", err)
+		}
+	}
+
+	switch cfg.Exporter {
+	case JaegerExporter:
+		if cfg.Endpoint == "" {
+			cfg.Endpoint = "jaeger:6831"
+		}
+	case OTLPExporter:
+		if cfg.Endpoint == "" {
+			cfg.Endpoint = "otel-collector:4318"
+		}
+	default:
+		return fmt.Errorf("TRACING_EXPORTER: unsupported exporter %q", cfg.Exporter)
+	}
+
+	return nil
+}
+
+func getEnv(key string, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok && value != "" {
+		return value
+	}
+
+	return fallback
+}
+
+func getEnvBool(key string, fallback bool) (bool, error) {
+	value := os.Getenv(key)
+
+	if value == "" {
+		return fallback, nil
+	}
+
+	parsed, err := strconv.ParseBool(value)
+
+	if err != nil {
+		return fallback, fmt.Errorf("%s: %w", key, err)
+	}
+
+	return parsed, nil
+}
@@ -0,0 +1,113 @@
+package setupJaeger
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type TracingConfigUnitTestSuite struct {
+	suite.Suite
+}
+
+func TestTracingConfigUnitTestSuite(t *testing.T) {
+	suite.Run(t, new(TracingConfigUnitTestSuite))
+}
+
+func (suite *TracingConfigUnitTestSuite) TestTracingConfig_LoadFromEnv_ReturnsDefaults() {
+	cfg := DefaultTracingConfig()
+	err := cfg.LoadFromEnv()
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+	assert.True(suite.T(), cfg.Enabled, "Tracing is disabled")
+	assert.Equal(suite.T(), JaegerExporter, cfg.Exporter, "Exporter is not jaeger")
+	assert.Equal(suite.T(), "jaeger:6831", cfg.Endpoint, "Endpoint is not jaeger agent")
+	assert.Equal(suite.T(), "posts-ms", cfg.ServiceName, "Service name is not posts-ms")
+	assert.Equal(suite.T(), "const", cfg.SamplerType, "Sampler type is not const")
+	assert.Equal(suite.T(), float64(1), cfg.SamplerParam, "Sampler param is not 1")
+	assert.False(suite.T(), cfg.LogSpans, "Span logging is enabled")
+}
+
+func (suite *TracingConfigUnitTestSuite) TestTracingConfig_LoadFromEnv_ReadsEnvironment() {
+	suite.T().Setenv("TRACING_EXPORTER", "otlp")
+	suite.T().Setenv("TRACING_ENDPOINT", "collector:4318")
+	suite.T().Setenv("TRACING_SERVICE_NAME", "posts")
+	suite.T().Setenv("TRACING_SAMPLER_TYPE", "probabilistic")
+	suite.T().Setenv("TRACING_SAMPLER_PARAM", "0.25")
+
+	cfg := DefaultTracingConfig()
+	err := cfg.LoadFromEnv()
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+	assert.Equal(suite.T(), OTLPExporter, cfg.Exporter, "Exporter is not otlp")
+	assert.Equal(suite.T(), "collector:4318", cfg.Endpoint, "Endpoint is not read")
+	assert.Equal(suite.T(), "posts", cfg.ServiceName, "Service name is not read")
+	assert.Equal(suite.T(), "probabilistic", cfg.SamplerType, "Sampler type is not read")
+	assert.Equal(suite.T(), 0.25, cfg.SamplerParam, "Sampler param is not read")
+}
+
+func (suite *TracingConfigUnitTestSuite) TestTracingConfig_LoadFromEnv_InvalidValues() {
+	suite.T().Setenv("TRACING_SAMPLER_PARAM", "all")
+
+	cfg := DefaultTracingConfig()
+	err := cfg.LoadFromEnv()
+
+	assert.NotNil(suite.T(), err, "Error is nil")
+
+	suite.T().Setenv("TRACING_SAMPLER_PARAM", "1")
+	suite.T().Setenv("TRACING_EXPORTER", "zipkin")
+
+	cfg = DefaultTracingConfig()
+	err = cfg.LoadFromEnv()
+
+	assert.NotNil(suite.T(), err, "Error is nil")
+}
+
+func (suite *TracingConfigUnitTestSuite) TestTracingConfig_InitTracer_Disabled() {
+	tracer, closer, err := InitTracer(TracingConfig{Enabled: false})
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+	assert.Equal(suite.T(), opentracing.NoopTracer{}, tracer, "Tracer is not noop")
+	assert.Nil(suite.T(), closer.Close(), "Closer returned error")
+}
+
+func (suite *TracingConfigUnitTestSuite) TestTracingConfig_InitTracer_OTLPPropagatesJaegerHeaders() {
+	tracer, closer, err := InitTracer(TracingConfig{
+		Enabled:      true,
+		Exporter:     OTLPExporter,
+		Endpoint:     "localhost:4318",
+		Insecure:     true,
+		ServiceName:  "posts-ms",
+		SamplerType:  "const",
+		SamplerParam: 1,
+	})
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+
+	defer closer.Close()
+
+	span := tracer.StartSpan("test")
+	header := http.Header{}
+
+	err = tracer.Inject(span.Context(), opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(header))
+
+	span.Finish()
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+	assert.NotEmpty(suite.T(), header.Get("uber-trace-id"), "Jaeger header is missing")
+	assert.NotEmpty(suite.T(), header.Get("traceparent"), "W3C header is missing")
+}
+
+func (suite *TracingConfigUnitTestSuite) TestTracingConfig_InitTracer_OTLPRejectsRateLimitingSampler() {
+	_, _, err := InitTracer(TracingConfig{
+		Enabled:     true,
+		Exporter:    OTLPExporter,
+		Endpoint:    "localhost:4318",
+		SamplerType: "ratelimiting",
+	})
+
+	assert.NotNil(suite.T(), err, "Error is nil")
+}
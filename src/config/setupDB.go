@@ -1,35 +1,40 @@
 package config
 
 import (
+	"context"
 	"fmt"
-	"os"
-	"posts-ms/src/entity"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
-func SetupDB() (*gorm.DB, error) {
-	host := os.Getenv("DATABASE_DOMAIN")
-	user := os.Getenv("DATABASE_USERNAME")
-	password := os.Getenv("DATABASE_PASSWORD")
-	name := os.Getenv("DATABASE_SCHEMA")
-	port := os.Getenv("DATABASE_PORT")
-
+func SetupDB(cfg DatabaseConfig) (*gorm.DB, error) {
 	connectionString := fmt.Sprintf(
 		"host=%s user=%s password=%s dbname=%s port=%s sslmode=disable",
-		host,
-		user,
-		password,
-		name,
-		port,
+		cfg.Host,
+		cfg.Username,
+		cfg.Password,
+		cfg.Schema,
+		cfg.Port,
 	)
 
 	db, err := gorm.Open(postgres.Open(connectionString), &gorm.Config{})
 
-	db.AutoMigrate(&entity.Post{Tbl: "posts"})
-	db.AutoMigrate(&entity.Like{Tbl: "likes"})
-	db.AutoMigrate(&entity.Comment{Tbl: "comments"})
+	if err != nil {
+		return nil, err
+	}
+
+	return db, nil
+}
+
+func NewDatabaseHealthCheck(db *gorm.DB) func(context.Context) error {
+	return func(ctx context.Context) error {
+		sqlDB, err := db.DB()
+
+		if err != nil {
+			return err
+		}
 
-	return db, err
+		return sqlDB.PingContext(ctx)
+	}
 }
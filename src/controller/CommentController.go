@@ -1,16 +1,14 @@
 package controller
 
 import (
-	"bytes"
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"os"
+	"posts-ms/src/client"
 	"posts-ms/src/dto/request"
 	"posts-ms/src/service"
 	"posts-ms/src/utils"
 	"strconv"
-	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/opentracing/opentracing-go"
@@ -20,15 +18,17 @@ import (
 
 type CommentController struct {
 	CommentService service.ICommentService
+	AuthorService  service.IAuthorService
+	Events         client.IEventsDispatcher
 	validate       *validator.Validate
 	logger         *logrus.Entry
 }
 
-func NewCommentController(commentService service.ICommentService) CommentController {
+func NewCommentController(commentService service.ICommentService, authorService service.IAuthorService, events client.IEventsDispatcher) CommentController {
 	config := &validator.Config{TagName: "validate"}
 	logger := utils.Logger()
 
-	return CommentController{CommentService: commentService, validate: validator.New(config), logger: logger}
+	return CommentController{CommentService: commentService, AuthorService: authorService, Events: events, validate: validator.New(config), logger: logger}
 }
 
 func (c CommentController) GetAllByPostId(w http.ResponseWriter, r *http.Request) {
@@ -36,22 +36,34 @@ func (c CommentController) GetAllByPostId(w http.ResponseWriter, r *http.Request
 
 	defer span.Finish()
 
-	c.logger.Info("Getting comments for specified post request received")
+	c.logger.WithContext(ctx).Info("Getting comments for specified post request received")
 	params := mux.Vars(r)
 
 	id, error := strconv.Atoi(params["postId"])
 
 	if error != nil {
-		w.WriteHeader(http.StatusBadRequest)
+		writeValidationProblem(w, r, c.logger, "post id %q is not a number", params["postId"])
 
 		return
 	}
 
-	comments := c.CommentService.GetAllByPostId(uint(id), ctx)
+	viewer, ok := viewerId(r)
+
+	if !ok {
+		writeValidationProblem(w, r, c.logger, "viewer id %q is not a number", r.URL.Query().Get("viewerId"))
+
+		return
+	}
+
+	comments := c.CommentService.GetAllByPostId(uint(id), viewer, ctx)
+
+	if expands(r, "author") {
+		c.AuthorService.AddAuthorsToComments(comments, ctx)
+	}
 
 	payload, _ := json.Marshal(comments)
 
-	c.logger.Info("Returning list of comments")
+	c.logger.WithContext(ctx).Info("Returning list of comments")
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
@@ -63,16 +75,20 @@ func (c CommentController) Create(w http.ResponseWriter, r *http.Request) {
 
 	defer span.Finish()
 
-	c.logger.Info("Creating comment request received")
+	c.logger.WithContext(ctx).Info("Creating comment request received")
 
 	var commentDto request.CommentDto
 
-	json.NewDecoder(r.Body).Decode(&commentDto)
+	if error := json.NewDecoder(r.Body).Decode(&commentDto); error != nil {
+		writeValidationProblem(w, r, c.logger, "request body is not valid JSON")
 
-	error := c.validate.Struct(commentDto)
+		return
+	}
 
-	if error != nil {
-		w.WriteHeader(http.StatusBadRequest)
+	utils.SetUserId(ctx, commentDto.UserId)
+
+	if error := c.validate.Struct(commentDto); error != nil {
+		writeValidationProblem(w, r, c.logger, "%s", error.Error())
 
 		return
 	}
@@ -80,20 +96,20 @@ func (c CommentController) Create(w http.ResponseWriter, r *http.Request) {
 	newLike, error := c.CommentService.Create(commentDto, ctx)
 
 	if error != nil {
-		c.logger.Error("Error occured in creating comment")
+		c.logger.WithContext(ctx).Error("Error occured in creating comment")
 
-		AddSystemEvent(time.Now().Format("2006-01-02 15:04:05"), "Comment unsuccessfully created")
+		c.Events.Publish(request.EventRequestDTO{EntityType: request.CommentEntity, Action: request.CreatedAction, Outcome: request.FailedOutcome, ActorId: commentDto.UserId}, ctx)
 
-		handleCommentError(error, w)
+		writeProblem(w, r, c.logger, error)
 
 		return
 	}
 
 	payload, _ := json.Marshal(newLike)
 
-	c.logger.Info("Comment created successfully")
+	c.logger.WithContext(ctx).Info("Comment created successfully")
 
-	AddSystemEvent(time.Now().Format("2006-01-02 15:04:05"), "Comment successfully created")
+	c.Events.Publish(request.EventRequestDTO{EntityType: request.CommentEntity, Action: request.CreatedAction, Outcome: request.SucceededOutcome, EntityId: newLike.Id, ActorId: commentDto.UserId}, ctx)
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
@@ -105,55 +121,35 @@ func (c CommentController) Delete(w http.ResponseWriter, r *http.Request) {
 
 	defer span.Finish()
 
-	c.logger.Info("Deleting comment request received")
+	c.logger.WithContext(ctx).Info("Deleting comment request received")
 
 	params := mux.Vars(r)
 
 	id, error := strconv.Atoi(params["id"])
 
 	if error != nil {
-		c.logger.Error("Error occured in deleting comment")
+		c.logger.WithContext(ctx).Error("Error occured in deleting comment")
 
-		AddSystemEvent(time.Now().Format("2006-01-02 15:04:05"), fmt.Sprintf("Comment with id %d unsuccessfully deleted", id))
+		c.Events.Publish(request.EventRequestDTO{EntityType: request.CommentEntity, Action: request.DeletedAction, Outcome: request.FailedOutcome, Message: fmt.Sprintf("Comment with id %s unsuccessfully deleted", params["id"])}, ctx)
 
-		w.WriteHeader(http.StatusBadRequest)
+		writeValidationProblem(w, r, c.logger, "comment id %q is not a number", params["id"])
 
 		return
 	}
 
-	c.CommentService.Delete(uint(id), ctx)
+	if error := c.CommentService.Delete(uint(id), ctx); error != nil {
+		c.logger.WithContext(ctx).Error("Error occured in deleting comment")
 
-	c.logger.Info("Deleting comment was successful")
+		c.Events.Publish(request.EventRequestDTO{EntityType: request.CommentEntity, Action: request.DeletedAction, Outcome: request.FailedOutcome, EntityId: uint(id)}, ctx)
 
-	AddSystemEvent(time.Now().Format("2006-01-02 15:04:05"), fmt.Sprintf("Comment with id %d successfully deleted", id))
+		writeProblem(w, r, c.logger, error)
 
-	w.WriteHeader(http.StatusNoContent)
-}
-
-func handleCommentError(error error, w http.ResponseWriter) http.ResponseWriter {
-	w.WriteHeader(http.StatusBadRequest)
-
-	return w
-}
-
-func AddSystemEvent(time string, message string) error {
-	logger := utils.Logger()
-	event := request.EventRequestDTO{
-		Timestamp: time,
-		Message:   message,
+		return
 	}
 
-	b, _ := json.Marshal(&event)
-	endpoint := os.Getenv("EVENTS_MS")
-	logger.Info("Sending system event to events-ms")
-	req, _ := http.NewRequest("POST", endpoint, bytes.NewBuffer(b))
-	req.Header.Set("content-type", "application/json")
+	c.logger.WithContext(ctx).Info("Deleting comment was successful")
 
-	_, err := http.DefaultClient.Do(req)
-	if err != nil {
-		logger.Debug("Error happened during sending system event")
-		return err
-	}
+	c.Events.Publish(request.EventRequestDTO{EntityType: request.CommentEntity, Action: request.DeletedAction, Outcome: request.SucceededOutcome, EntityId: uint(id)}, ctx)
 
-	return nil
+	w.WriteHeader(http.StatusNoContent)
 }
@@ -0,0 +1,18 @@
+package controller
+
+import (
+	"net/http"
+	"strings"
+)
+
+func expands(r *http.Request, field string) bool {
+	for _, value := range r.URL.Query()["expand"] {
+		for _, expanded := range strings.Split(value, ",") {
+			if strings.TrimSpace(expanded) == field {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,41 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"posts-ms/src/dto/response"
+	"posts-ms/src/service"
+)
+
+type HealthController struct {
+	HealthService service.IHealthService
+}
+
+func NewHealthController(healthService service.IHealthService) HealthController {
+	return HealthController{HealthService: healthService}
+}
+
+func (c HealthController) Live(w http.ResponseWriter, r *http.Request) {
+	writeHealth(w, c.HealthService.Live(), http.StatusOK)
+}
+
+func (c HealthController) Ready(w http.ResponseWriter, r *http.Request) {
+	health, ready := c.HealthService.Ready(r.Context())
+
+	if !ready {
+		writeHealth(w, health, http.StatusServiceUnavailable)
+
+		return
+	}
+
+	writeHealth(w, health, http.StatusOK)
+}
+
+func writeHealth(w http.ResponseWriter, health response.HealthDto, status int) {
+	payload, _ := json.Marshal(health)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	w.Write(payload)
+}
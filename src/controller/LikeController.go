@@ -4,11 +4,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"posts-ms/src/client"
 	"posts-ms/src/dto/request"
 	"posts-ms/src/service"
 	"posts-ms/src/utils"
 	"strconv"
-	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/opentracing/opentracing-go"
@@ -18,15 +18,16 @@ import (
 
 type LikeController struct {
 	LikeService service.ILikeService
+	Events      client.IEventsDispatcher
 	validate    *validator.Validate
 	logger      *logrus.Entry
 }
 
-func NewLikeController(likeService service.ILikeService) LikeController {
+func NewLikeController(likeService service.ILikeService, events client.IEventsDispatcher) LikeController {
 	config := &validator.Config{TagName: "validate"}
 	logger := utils.Logger()
 
-	return LikeController{LikeService: likeService, validate: validator.New(config), logger: logger}
+	return LikeController{LikeService: likeService, Events: events, validate: validator.New(config), logger: logger}
 }
 
 func (c LikeController) GetAllByPostId(w http.ResponseWriter, r *http.Request) {
@@ -34,21 +35,21 @@ func (c LikeController) GetAllByPostId(w http.ResponseWriter, r *http.Request) {
 
 	defer span.Finish()
 
-	c.logger.Info("Getting all likes for specified post request received")
+	c.logger.WithContext(ctx).Info("Getting all likes for specified post request received")
 
 	params := mux.Vars(r)
 
 	id, error := strconv.Atoi(params["postId"])
 
 	if error != nil {
-		w.WriteHeader(http.StatusBadRequest)
+		c.logger.WithContext(ctx).Error("Error occured in getting likes for specified post")
 
-		c.logger.Error("Error occured in getting likes for specified post")
+		writeValidationProblem(w, r, c.logger, "post id %q is not a number", params["postId"])
 
 		return
 	}
 
-	c.logger.Info("Returning list of likes for specified post")
+	c.logger.WithContext(ctx).Info("Returning list of likes for specified post")
 
 	likes := c.LikeService.GetAllByPostId(uint(id), ctx)
 
@@ -64,34 +65,40 @@ func (c LikeController) Create(w http.ResponseWriter, r *http.Request) {
 
 	defer span.Finish()
 
-	c.logger.Info("Creating like request received")
+	c.logger.WithContext(ctx).Info("Creating like request received")
 	var likeDto request.LikeDto
 
-	json.NewDecoder(r.Body).Decode(&likeDto)
+	if error := json.NewDecoder(r.Body).Decode(&likeDto); error != nil {
+		writeValidationProblem(w, r, c.logger, "request body is not valid JSON")
 
-	error := c.validate.Struct(likeDto)
+		return
+	}
+
+	utils.SetUserId(ctx, likeDto.UserId)
+
+	if error := c.validate.Struct(likeDto); error != nil {
+		writeValidationProblem(w, r, c.logger, "%s", error.Error())
 
-	if error != nil {
-		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
 
 	newLike, error := c.LikeService.Create(likeDto, ctx)
 
 	if error != nil {
-		c.logger.Error("Error occured in creating like")
+		c.logger.WithContext(ctx).Error("Error occured in creating like")
+
+		c.Events.Publish(request.EventRequestDTO{EntityType: request.LikeEntity, Action: request.CreatedAction, Outcome: request.FailedOutcome, ActorId: likeDto.UserId}, ctx)
 
-		AddSystemEvent(time.Now().Format("2006-01-02 15:04:05"), "Like unsuccessfully created")
+		writeProblem(w, r, c.logger, error)
 
-		handleLikeError(error, w)
 		return
 	}
 
 	payload, _ := json.Marshal(newLike)
 
-	c.logger.Info("Like created successfully")
+	c.logger.WithContext(ctx).Info("Like created successfully")
 
-	AddSystemEvent(time.Now().Format("2006-01-02 15:04:05"), "Like successfully created")
+	c.Events.Publish(request.EventRequestDTO{EntityType: request.LikeEntity, Action: request.CreatedAction, Outcome: request.SucceededOutcome, EntityId: newLike.Id, ActorId: likeDto.UserId}, ctx)
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
@@ -104,16 +111,16 @@ func (c LikeController) Delete(w http.ResponseWriter, r *http.Request) {
 
 	defer span.Finish()
 
-	c.logger.Info("Deleting like request received")
+	c.logger.WithContext(ctx).Info("Deleting like request received")
 
 	params := mux.Vars(r)
 
 	userId, error := strconv.Atoi(params["userId"])
 
 	if error != nil {
-		c.logger.Error("Error occured in deleting like")
+		c.logger.WithContext(ctx).Error("Error occured in deleting like")
 
-		w.WriteHeader(http.StatusBadRequest)
+		writeValidationProblem(w, r, c.logger, "user id %q is not a number", params["userId"])
 
 		return
 	}
@@ -121,24 +128,24 @@ func (c LikeController) Delete(w http.ResponseWriter, r *http.Request) {
 	postId, error := strconv.Atoi(params["postId"])
 
 	if error != nil {
-		c.logger.Error("Error occured in deleting like")
+		c.logger.WithContext(ctx).Error("Error occured in deleting like")
 
-		w.WriteHeader(http.StatusBadRequest)
+		writeValidationProblem(w, r, c.logger, "post id %q is not a number", params["postId"])
 
 		return
 	}
 
-	c.logger.Info("Like deleted successfully")
+	if error := c.LikeService.Delete(uint(userId), uint(postId), ctx); error != nil {
+		c.logger.WithContext(ctx).Error("Error occured in deleting like")
 
-	AddSystemEvent(time.Now().Format("2006-01-02 15:04:05"), fmt.Sprintf("Like for post with id %d of user with id %d successfully deleted", postId, userId))
+		writeProblem(w, r, c.logger, error)
 
-	c.LikeService.Delete(uint(userId), uint(postId), ctx)
+		return
+	}
 
-	w.WriteHeader(http.StatusNoContent)
-}
+	c.logger.WithContext(ctx).Info("Like deleted successfully")
 
-func handleLikeError(error error, w http.ResponseWriter) http.ResponseWriter {
-	w.WriteHeader(http.StatusBadRequest)
+	c.Events.Publish(request.EventRequestDTO{EntityType: request.LikeEntity, Action: request.DeletedAction, Outcome: request.SucceededOutcome, ActorId: uint(userId), Message: fmt.Sprintf("Like for post with id %d of user with id %d successfully deleted", postId, userId)}, ctx)
 
-	return w
+	w.WriteHeader(http.StatusNoContent)
 }
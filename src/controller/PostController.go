@@ -4,11 +4,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"posts-ms/src/client"
 	"posts-ms/src/dto/request"
 	"posts-ms/src/service"
 	"posts-ms/src/utils"
 	"strconv"
-	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/opentracing/opentracing-go"
@@ -17,16 +17,18 @@ import (
 )
 
 type PostController struct {
-	PostService service.IPostService
-	validate    *validator.Validate
-	logger      *logrus.Entry
+	PostService   service.IPostService
+	AuthorService service.IAuthorService
+	Events        client.IEventsDispatcher
+	validate      *validator.Validate
+	logger        *logrus.Entry
 }
 
-func NewPostController(postService service.IPostService) PostController {
+func NewPostController(postService service.IPostService, authorService service.IAuthorService, events client.IEventsDispatcher) PostController {
 	config := &validator.Config{TagName: "validate"}
 	logger := utils.Logger()
 
-	return PostController{PostService: postService, validate: validator.New(config), logger: logger}
+	return PostController{PostService: postService, AuthorService: authorService, Events: events, validate: validator.New(config), logger: logger}
 }
 
 func (c PostController) GetAllByUserId(w http.ResponseWriter, r *http.Request) {
@@ -34,24 +36,36 @@ func (c PostController) GetAllByUserId(w http.ResponseWriter, r *http.Request) {
 
 	defer span.Finish()
 
-	c.logger.Info("Getting all posts for specified user request received")
+	c.logger.WithContext(ctx).Info("Getting all posts for specified user request received")
 	params := mux.Vars(r)
 
 	id, error := strconv.Atoi(params["userId"])
 
 	if error != nil {
-		c.logger.Error("Error occured in getting posts by user")
+		c.logger.WithContext(ctx).Error("Error occured in getting posts by user")
 
-		w.WriteHeader(http.StatusBadRequest)
+		writeValidationProblem(w, r, c.logger, "user id %q is not a number", params["userId"])
 
 		return
 	}
 
-	likes := c.PostService.GetAllByUserId(uint(id), ctx)
+	viewer, ok := viewerId(r)
 
-	payload, _ := json.Marshal(likes)
+	if !ok {
+		writeValidationProblem(w, r, c.logger, "viewer id %q is not a number", r.URL.Query().Get("viewerId"))
 
-	c.logger.Info("Returning list of posts for specified user")
+		return
+	}
+
+	posts := c.PostService.GetAllByUserId(uint(id), viewer, ctx)
+
+	if expands(r, "author") {
+		c.AuthorService.AddAuthorsToPosts(posts, ctx)
+	}
+
+	payload, _ := json.Marshal(posts)
+
+	c.logger.WithContext(ctx).Info("Returning list of posts for specified user")
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
@@ -63,17 +77,35 @@ func (c PostController) GetAllByUserIds(w http.ResponseWriter, r *http.Request)
 
 	defer span.Finish()
 
-	c.logger.Info("Getting all posts for specified users request received")
+	c.logger.WithContext(ctx).Info("Getting all posts for specified users request received")
 
 	var search request.SearchPostPageableDto
 
-	json.NewDecoder(r.Body).Decode(&search)
+	if error := json.NewDecoder(r.Body).Decode(&search); error != nil {
+		c.logger.WithContext(ctx).Error("Error occured in getting posts by users")
+
+		writeValidationProblem(w, r, c.logger, "request body is not valid JSON")
+
+		return
+	}
+
+	viewer, ok := viewerId(r)
+
+	if !ok {
+		writeValidationProblem(w, r, c.logger, "viewer id %q is not a number", r.URL.Query().Get("viewerId"))
+
+		return
+	}
+
+	posts := c.PostService.GetAllByUserIds(search.Ids, viewer, ctx)
 
-	likes := c.PostService.GetAllByUserIds(search.Ids, ctx)
+	if expands(r, "author") {
+		c.AuthorService.AddAuthorsToPosts(posts, ctx)
+	}
 
-	payload, _ := json.Marshal(likes)
+	payload, _ := json.Marshal(posts)
 
-	c.logger.Info("Returning list of posts for specified users")
+	c.logger.WithContext(ctx).Info("Returning list of posts for specified users")
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
@@ -85,22 +117,32 @@ func (p PostController) Create(w http.ResponseWriter, r *http.Request) {
 
 	defer span.Finish()
 
-	p.logger.Info("Creating post request received")
+	p.logger.WithContext(ctx).Info("Creating post request received")
+
+	if error := r.ParseMultipartForm(32 << 20); error != nil {
+		p.logger.WithContext(ctx).Error("Error occured in creating post")
+
+		writeValidationProblem(w, r, p.logger, "request is not a valid multipart form")
 
-	r.ParseMultipartForm(32 << 20)
+		return
+	}
 
 	var postDto request.PostDto
 
-	postDtoJSON := r.Form["post"][0]
+	if error := json.Unmarshal([]byte(r.FormValue("post")), &postDto); error != nil {
+		p.logger.WithContext(ctx).Error("Error occured in creating post")
 
-	p.validate.Struct(postDto)
+		writeValidationProblem(w, r, p.logger, "post field is not valid JSON")
+
+		return
+	}
 
-	error := json.Unmarshal([]byte(postDtoJSON), &postDto)
+	utils.SetUserId(ctx, postDto.UserId)
 
-	if error != nil {
-		p.logger.Error("Error occured in creating post")
+	if error := p.validate.Struct(postDto); error != nil {
+		p.logger.WithContext(ctx).Error("Error occured in creating post")
 
-		w.WriteHeader(http.StatusBadRequest)
+		writeValidationProblem(w, r, p.logger, "%s", error.Error())
 
 		return
 	}
@@ -110,20 +152,20 @@ func (p PostController) Create(w http.ResponseWriter, r *http.Request) {
 	post, err := p.PostService.Create(postDto, files, ctx)
 
 	if err != nil {
-		p.logger.Error("Error occured in creating post")
+		p.logger.WithContext(ctx).Error("Error occured in creating post")
 
-		AddSystemEvent(time.Now().Format("2006-01-02 15:04:05"), "Post unsuccessfully created")
+		p.Events.Publish(request.EventRequestDTO{EntityType: request.PostEntity, Action: request.CreatedAction, Outcome: request.FailedOutcome, ActorId: postDto.UserId}, ctx)
 
-		handleMunicipalityError(err, w)
+		writeProblem(w, r, p.logger, err)
 
 		return
 	}
 
 	payload, _ := json.Marshal(post)
 
-	p.logger.Info("Post created successfully")
+	p.logger.WithContext(ctx).Info("Post created successfully")
 
-	AddSystemEvent(time.Now().Format("2006-01-02 15:04:05"), "Post successfully created")
+	p.Events.Publish(request.EventRequestDTO{EntityType: request.PostEntity, Action: request.CreatedAction, Outcome: request.SucceededOutcome, EntityId: post.Id, ActorId: postDto.UserId}, ctx)
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
@@ -135,33 +177,35 @@ func (c PostController) Delete(w http.ResponseWriter, r *http.Request) {
 
 	defer span.Finish()
 
-	c.logger.Info("Deleting post request received")
+	c.logger.WithContext(ctx).Info("Deleting post request received")
 
 	params := mux.Vars(r)
 
 	id, error := strconv.Atoi(params["id"])
 
 	if error != nil {
-		c.logger.Error("Error occured in deleting post")
+		c.logger.WithContext(ctx).Error("Error occured in deleting post")
 
-		AddSystemEvent(time.Now().Format("2006-01-02 15:04:05"), fmt.Sprintf("Post with id %d unsuccessfully deleted", id))
+		c.Events.Publish(request.EventRequestDTO{EntityType: request.PostEntity, Action: request.DeletedAction, Outcome: request.FailedOutcome, Message: fmt.Sprintf("Post with id %s unsuccessfully deleted", params["id"])}, ctx)
 
-		w.WriteHeader(http.StatusBadRequest)
+		writeValidationProblem(w, r, c.logger, "post id %q is not a number", params["id"])
 
 		return
 	}
 
-	c.PostService.Delete(uint(id), ctx)
+	if error := c.PostService.Delete(uint(id), ctx); error != nil {
+		c.logger.WithContext(ctx).Error("Error occured in deleting post")
 
-	c.logger.Info("Post deleted successfully")
+		c.Events.Publish(request.EventRequestDTO{EntityType: request.PostEntity, Action: request.DeletedAction, Outcome: request.FailedOutcome, EntityId: uint(id)}, ctx)
 
-	AddSystemEvent(time.Now().Format("2006-01-02 15:04:05"), fmt.Sprintf("Post with id %d successfully deleted", id))
+		writeProblem(w, r, c.logger, error)
 
-	w.WriteHeader(http.StatusNoContent)
-}
+		return
+	}
 
-func handleMunicipalityError(error error, w http.ResponseWriter) http.ResponseWriter {
-	w.WriteHeader(http.StatusConflict)
+	c.logger.WithContext(ctx).Info("Post deleted successfully")
 
-	return w
+	c.Events.Publish(request.EventRequestDTO{EntityType: request.PostEntity, Action: request.DeletedAction, Outcome: request.SucceededOutcome, EntityId: uint(id)}, ctx)
+
+	w.WriteHeader(http.StatusNoContent)
 }
@@ -0,0 +1,67 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"posts-ms/src/dto/response"
+	"posts-ms/src/service"
+	"posts-ms/src/utils"
+
+	"github.com/sirupsen/logrus"
+)
+
+var problemStatuses = []struct {
+	kind   error
+	status int
+}{
+	{service.ErrNotFound, http.StatusNotFound},
+	{service.ErrConflict, http.StatusConflict},
+	{service.ErrValidation, http.StatusBadRequest},
+	{service.ErrContentRejected, http.StatusUnprocessableEntity},
+	{service.ErrDependencyUnavailable, http.StatusServiceUnavailable},
+}
+
+// writeProblem renders err as an application/problem+json response. Domain
+// errors keep their detail, anything else is logged and answered with a
+// generic 500 so that internals do not leak to clients.
+func writeProblem(w http.ResponseWriter, r *http.Request, logger *logrus.Entry, err error) {
+	status := http.StatusInternalServerError
+	detail := "An unexpected error occured."
+
+	for _, problem := range problemStatuses {
+		if errors.Is(err, problem.kind) {
+			status = problem.status
+
+			break
+		}
+	}
+
+	var domainError *service.DomainError
+
+	if errors.As(err, &domainError) && status != http.StatusInternalServerError {
+		detail = domainError.Detail
+	}
+
+	if status >= http.StatusInternalServerError {
+		logger.WithContext(r.Context()).WithError(err).Error("Error occured in handling request")
+	}
+
+	payload, _ := json.Marshal(response.ProblemDto{
+		Type:     "about:blank",
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   detail,
+		Instance: r.URL.Path,
+		TraceId:  utils.TraceId(r.Context()),
+	})
+
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(status)
+	w.Write(payload)
+}
+
+// writeValidationProblem answers a request whose input could not be parsed.
+func writeValidationProblem(w http.ResponseWriter, r *http.Request, logger *logrus.Entry, format string, args ...interface{}) {
+	writeProblem(w, r, logger, service.NewValidationError(format, args...))
+}
@@ -0,0 +1,90 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"posts-ms/src/dto/response"
+	"posts-ms/src/service"
+	"posts-ms/src/utils"
+	"testing"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/uber/jaeger-client-go"
+)
+
+type ProblemUnitTestSuite struct {
+	suite.Suite
+}
+
+func TestProblemUnitTestSuite(t *testing.T) {
+	suite.Run(t, new(ProblemUnitTestSuite))
+}
+
+func (suite *ProblemUnitTestSuite) write(r *http.Request, err error) (*httptest.ResponseRecorder, response.ProblemDto) {
+	recorder := httptest.NewRecorder()
+
+	writeProblem(recorder, r, utils.Logger(), err)
+
+	var problem response.ProblemDto
+
+	json.Unmarshal(recorder.Body.Bytes(), &problem)
+
+	return recorder, problem
+}
+
+func (suite *ProblemUnitTestSuite) TestProblem_MapsDomainErrorsToStatus() {
+	cases := []struct {
+		err    error
+		status int
+	}{
+		{service.NewNotFoundError("post 1 does not exist"), http.StatusNotFound},
+		{service.NewConflictError("user 1 already liked post 1"), http.StatusConflict},
+		{service.NewValidationError("post requires an image"), http.StatusBadRequest},
+		{service.NewContentRejectedError("post contains content that is not allowed"), http.StatusUnprocessableEntity},
+		{service.NewDependencyUnavailableError(errors.New("timeout"), "media-ms is unavailable"), http.StatusServiceUnavailable},
+	}
+
+	for _, c := range cases {
+		recorder, problem := suite.write(httptest.NewRequest("DELETE", "/api/posts/1", nil), c.err)
+
+		assert.Equal(suite.T(), c.status, recorder.Code, "Status does not match")
+		assert.Equal(suite.T(), "application/problem+json", recorder.Header().Get("Content-Type"), "Content type is not problem+json")
+		assert.Equal(suite.T(), c.status, problem.Status, "Status in body does not match")
+		assert.Equal(suite.T(), http.StatusText(c.status), problem.Title, "Title does not match")
+		assert.Equal(suite.T(), "/api/posts/1", problem.Instance, "Instance is not request path")
+	}
+}
+
+func (suite *ProblemUnitTestSuite) TestProblem_KeepsDetailOfDomainError() {
+	_, problem := suite.write(httptest.NewRequest("DELETE", "/api/posts/1", nil), service.NewNotFoundError("post %d does not exist", 1))
+
+	assert.Equal(suite.T(), "post 1 does not exist", problem.Detail, "Detail does not match")
+}
+
+func (suite *ProblemUnitTestSuite) TestProblem_HidesUnexpectedError() {
+	recorder, problem := suite.write(httptest.NewRequest("DELETE", "/api/posts/1", nil), errors.New("pq: connection reset by peer"))
+
+	assert.Equal(suite.T(), http.StatusInternalServerError, recorder.Code, "Status is not 500")
+	assert.NotContains(suite.T(), problem.Detail, "pq", "Internal error leaks to client")
+}
+
+func (suite *ProblemUnitTestSuite) TestProblem_IncludesTraceId() {
+	tracer, closer := jaeger.NewTracer("posts-ms", jaeger.NewConstSampler(true), jaeger.NewNullReporter())
+
+	defer closer.Close()
+
+	span := tracer.StartSpan("HTTP DELETE /api/posts/{id}")
+
+	defer span.Finish()
+
+	r := httptest.NewRequest("DELETE", "/api/posts/1", nil)
+	r = r.WithContext(opentracing.ContextWithSpan(r.Context(), span))
+
+	_, problem := suite.write(r, service.NewNotFoundError("post 1 does not exist"))
+
+	assert.Equal(suite.T(), span.Context().(jaeger.SpanContext).TraceID().String(), problem.TraceId, "Trace id does not match")
+}
@@ -0,0 +1,20 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// viewerId reads the user a list is shown to from the viewerId query
+// parameter. Without it the request is anonymous, which is viewer 0.
+func viewerId(r *http.Request) (uint, bool) {
+	value := r.URL.Query().Get("viewerId")
+
+	if value == "" {
+		return 0, true
+	}
+
+	id, err := strconv.ParseUint(value, 10, 32)
+
+	return uint(id), err == nil
+}
@@ -1,6 +1,32 @@
 package request
 
+const (
+	PostEntity    = "post"
+	LikeEntity    = "like"
+	CommentEntity = "comment"
+)
+
+const (
+	CreatedAction = "created"
+	DeletedAction = "deleted"
+)
+
+const (
+	SucceededOutcome = "succeeded"
+	FailedOutcome    = "failed"
+)
+
+// EventRequestDTO is a system event sent to events-ms. EventId lets events-ms
+// drop duplicates of an event that was retried. ActorId and EntityId are 0
+// when they are not known.
 type EventRequestDTO struct {
-	Timestamp string
-	Message   string
+	EventId    string
+	Timestamp  string
+	Message    string
+	Action     string
+	Outcome    string
+	EntityType string
+	EntityId   uint
+	ActorId    uint
+	RequestId  string
 }
@@ -9,8 +9,16 @@ const (
 	Comment
 )
 
+// NotificationDTO carries the message rendered in the recipient's locale and
+// what it was rendered from, so that receivers can write their own text:
+// ActorId reacted last to post PostId and OtherActors more users reacted with
+// the same ReactionType, one of liked, disliked or commented.
 type NotificationDTO struct {
 	Message          string
 	UserAuth0ID      string
 	NotificationType *NotificationType
+	ActorId          uint
+	PostId           uint
+	ReactionType     string
+	OtherActors      int
 }
@@ -0,0 +1,8 @@
+package response
+
+type AuthorDto struct {
+	Id        int    `json:"id"`
+	Username  string `json:"username"`
+	FirstName string `json:"firstName"`
+	LastName  string `json:"lastName"`
+}
@@ -1,8 +1,11 @@
 package response
 
 type CommentDto struct {
-	Id      uint   `json:"id"`
-	PostId  uint   `json:"postId" validate:"required"`
-	UserId  uint   `json:"userId" validate:"required"`
-	Content string `json:"content" validate:"required"`
+	Id      uint       `json:"id"`
+	PostId  uint       `json:"postId" validate:"required"`
+	UserId  uint       `json:"userId" validate:"required"`
+	Content string     `json:"content" validate:"required"`
+	Author  *AuthorDto `json:"author,omitempty"`
+
+	ModerationStatus string `json:"moderationStatus"`
 }
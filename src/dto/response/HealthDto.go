@@ -0,0 +1,13 @@
+package response
+
+type HealthDto struct {
+	Status string                         `json:"status"`
+	Checks map[string]DependencyHealthDto `json:"checks,omitempty"`
+}
+
+type DependencyHealthDto struct {
+	Status    string  `json:"status"`
+	Critical  bool    `json:"critical"`
+	LatencyMs float64 `json:"latencyMs"`
+	Error     string  `json:"error,omitempty"`
+}
@@ -0,0 +1,8 @@
+package response
+
+type MediaProcessedDto struct {
+	PostId  uint   `json:"postId"`
+	ImageId uint   `json:"imageId"`
+	Success bool   `json:"success"`
+	Error   string `json:"error"`
+}
@@ -5,8 +5,12 @@ type PostDto struct {
 	Description  string       `json:"description" validate:"required"`
 	UserId       uint         `json:"userId" validate:"required"`
 	ImageId      uint         `json:"imageId" validate:"required"`
+	Status       string       `json:"status"`
 	TotalLikes   int          `json:"totalLikes" validate:"required"`
 	TotalUnlikes int          `json:"totalUnlikes" validate:"required"`
 	Likes        []LikeDto    `json:"likes"`
 	Comments     []CommentDto `json:"comments"`
+	Author       *AuthorDto   `json:"author,omitempty"`
+
+	ModerationStatus string `json:"moderationStatus"`
 }
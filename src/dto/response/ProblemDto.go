@@ -0,0 +1,11 @@
+package response
+
+// ProblemDto is an RFC 7807 problem details document.
+type ProblemDto struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	TraceId  string `json:"traceId,omitempty"`
+}
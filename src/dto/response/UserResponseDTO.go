@@ -16,4 +16,9 @@ type UserResponseDTO struct {
 	Skills         string
 	Interests      string
 	Public         bool
+	Locale         string
+	// Notification preferences, nil when users-ms has none stored for the
+	// user, which leaves notifications on.
+	LikeNotifications    *bool `json:"like_notifications"`
+	CommentNotifications *bool `json:"comment_notifications"`
 }
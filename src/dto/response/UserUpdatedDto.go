@@ -0,0 +1,5 @@
+package response
+
+type UserUpdatedDto struct {
+	ID int `json:"ID"`
+}
@@ -14,6 +14,9 @@ type Comment struct {
 	PostId  uint   `gorm:"not null;default:null"`
 	Post    Post
 
+	ModerationStatus ModerationStatus `gorm:"not null;default:0"`
+	ModerationReason string           `gorm:"default:null"`
+
 	Tbl string `gorm:"-"`
 }
 
@@ -31,5 +34,18 @@ func (comment Comment) CreateDto() *response.CommentDto {
 		PostId:  comment.PostId,
 		UserId:  comment.UserId,
 		Content: comment.Content,
+
+		ModerationStatus: comment.ModerationStatus.String(),
 	}
 }
+
+// VisibleTo tells whether the user with viewerId may see the comment. Held
+// comments are only shown to their author.
+func (comment Comment) VisibleTo(viewerId uint) bool {
+	return comment.ModerationStatus != ModerationHeld || comment.UserId == viewerId
+}
+
+func (comment *Comment) SetModeration(status ModerationStatus, reason string) {
+	comment.ModerationStatus = status
+	comment.ModerationReason = reason
+}
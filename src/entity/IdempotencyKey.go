@@ -0,0 +1,25 @@
+package entity
+
+import "time"
+
+type IdempotencyKey struct {
+	Key         string `gorm:"primaryKey"`
+	RequestHash string `gorm:"not null"`
+	Completed   bool   `gorm:"not null;default:false"`
+	StatusCode  int
+	ContentType string
+	Body        []byte
+	CreatedAt   time.Time
+	ExpiresAt   time.Time `gorm:"not null;index"`
+	// LockedUntil is when a request still in progress is presumed dead, so
+	// that a retry can take the key over.
+	LockedUntil time.Time
+}
+
+func (key IdempotencyKey) IsExpired(now time.Time) bool {
+	return now.After(key.ExpiresAt)
+}
+
+func (key IdempotencyKey) IsLockExpired(now time.Time) bool {
+	return !key.Completed && now.After(key.LockedUntil)
+}
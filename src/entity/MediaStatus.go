@@ -0,0 +1,20 @@
+package entity
+
+type MediaStatus int
+
+const (
+	MediaReady MediaStatus = iota
+	MediaProcessing
+	MediaFailed
+)
+
+func (status MediaStatus) String() string {
+	switch status {
+	case MediaProcessing:
+		return "processing"
+	case MediaFailed:
+		return "failed"
+	}
+
+	return "ready"
+}
@@ -0,0 +1,18 @@
+package entity
+
+// ModerationStatus tells whether content passed moderation. Held content is
+// waiting for review and is only shown to its author.
+type ModerationStatus int
+
+const (
+	ModerationApproved ModerationStatus = iota
+	ModerationHeld
+)
+
+func (status ModerationStatus) String() string {
+	if status == ModerationHeld {
+		return "held"
+	}
+
+	return "approved"
+}
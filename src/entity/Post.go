@@ -11,12 +11,16 @@ type Post struct {
 	gorm.Model
 	Description  string `gorm:"default:null"`
 	ImageId      uint
+	MediaStatus  MediaStatus `gorm:"not null;default:0"`
 	UserId       uint
 	TotalLikes   int
 	TotalUnlikes int
 	Likes        []Like
 	Comments     []Comment
 	Tbl          string `gorm:"-"`
+
+	ModerationStatus ModerationStatus `gorm:"not null;default:0"`
+	ModerationReason string           `gorm:"default:null"`
 }
 
 func CreatePost(dto request.PostDto) Post {
@@ -35,13 +39,22 @@ func (post Post) CreateDto() *response.PostDto {
 		Description:  post.Description,
 		UserId:       post.UserId,
 		ImageId:      post.ImageId,
+		Status:       post.MediaStatus.String(),
 		TotalLikes:   post.TotalLikes,
 		TotalUnlikes: post.TotalUnlikes,
 		Likes:        transformLikesToDtos(post.Likes),
 		Comments:     transformCommentsToDtos(post.Comments),
+
+		ModerationStatus: post.ModerationStatus.String(),
 	}
 }
 
+// VisibleTo tells whether the user with viewerId may see the post. Held posts
+// are only shown to their author.
+func (post Post) VisibleTo(viewerId uint) bool {
+	return post.ModerationStatus != ModerationHeld || post.UserId == viewerId
+}
+
 func transformLikesToDtos(likes []Like) []response.LikeDto {
 	var likesDto = []response.LikeDto{}
 
@@ -69,3 +82,12 @@ func transformCommentsToDtos(likes []Comment) []response.CommentDto {
 func (post *Post) SetImageId(imageId uint) {
 	post.ImageId = imageId
 }
+
+func (post *Post) SetMediaStatus(status MediaStatus) {
+	post.MediaStatus = status
+}
+
+func (post *Post) SetModeration(status ModerationStatus, reason string) {
+	post.ModerationStatus = status
+	post.ModerationReason = reason
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"posts-ms/src/config"
+	"posts-ms/src/service"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// waitFor calls connect until it succeeds, giving up once the configured
+// attempts are used or ctx is cancelled.
+func waitFor(ctx context.Context, logger *logrus.Entry, name string, cfg config.StartupConfig, connect func() error) error {
+	var err error
+
+	for attempt := 1; attempt <= cfg.Attempts; attempt++ {
+		if err = connect(); err == nil {
+			return nil
+		}
+
+		logger.WithError(err).Warnf("Connecting on %s failed, attempt %d of %d", name, attempt, cfg.Attempts)
+
+		if attempt == cfg.Attempts {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(cfg.Backoff):
+		}
+	}
+
+	return fmt.Errorf("%s is unavailable after %d attempts: %w", name, cfg.Attempts, err)
+}
+
+// shutdownCheck is a critical health check that fails once ctx is done, so
+// that readiness reports the service as down while it shuts down.
+func shutdownCheck(ctx context.Context) service.HealthCheck {
+	return service.HealthCheck{Name: "shutdown", Critical: true, Check: func(context.Context) error {
+		if ctx.Err() != nil {
+			return errors.New("service is shutting down")
+		}
+
+		return nil
+	}}
+}
+
+// serve runs the server until ctx is cancelled. It then keeps serving for
+// drainDelay, while shutdownCheck makes readiness fail, before it stops
+// accepting new connections and waits up to shutdownTimeout for in-flight
+// requests.
+func serve(ctx context.Context, server *http.Server, drainDelay time.Duration, shutdownTimeout time.Duration) error {
+	serverErr := make(chan error, 1)
+
+	go func() {
+		serverErr <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serverErr:
+		return err
+	case <-ctx.Done():
+	}
+
+	select {
+	case err := <-serverErr:
+		return err
+	case <-time.After(drainDelay):
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+
+	if err := <-serverErr; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
+}
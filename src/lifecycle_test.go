@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"posts-ms/src/config"
+	"posts-ms/src/controller"
+	"posts-ms/src/service"
+	"posts-ms/src/utils"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type LifecycleUnitTestSuite struct {
+	suite.Suite
+	startup config.StartupConfig
+}
+
+func TestLifecycleUnitTestSuite(t *testing.T) {
+	suite.Run(t, new(LifecycleUnitTestSuite))
+}
+
+func (suite *LifecycleUnitTestSuite) SetupSuite() {
+	suite.startup = config.StartupConfig{Attempts: 3, Backoff: time.Millisecond}
+}
+
+func (suite *LifecycleUnitTestSuite) TestLifecycle_WaitFor_RetriesUntilConnected() {
+	calls := 0
+
+	err := waitFor(context.TODO(), utils.Logger(), "DB", suite.startup, func() error {
+		calls++
+
+		if calls < 3 {
+			return errors.New("connection refused")
+		}
+
+		return nil
+	})
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+	assert.Equal(suite.T(), 3, calls, "Connect is not retried")
+}
+
+func (suite *LifecycleUnitTestSuite) TestLifecycle_WaitFor_GivesUp() {
+	calls := 0
+
+	err := waitFor(context.TODO(), utils.Logger(), "DB", suite.startup, func() error {
+		calls++
+
+		return errors.New("connection refused")
+	})
+
+	assert.NotNil(suite.T(), err, "Error is nil")
+	assert.Equal(suite.T(), 3, calls, "Attempts are not limited")
+}
+
+func (suite *LifecycleUnitTestSuite) TestLifecycle_WaitFor_StopsOnCancel() {
+	ctx, cancel := context.WithCancel(context.TODO())
+
+	cancel()
+
+	err := waitFor(ctx, utils.Logger(), "DB", config.StartupConfig{Attempts: 3, Backoff: time.Hour}, func() error {
+		return errors.New("connection refused")
+	})
+
+	assert.Equal(suite.T(), context.Canceled, err, "Error is not cancellation")
+}
+
+func (suite *LifecycleUnitTestSuite) TestLifecycle_Serve_DrainsInFlightRequests() {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+
+	address := listener.Addr().String()
+
+	listener.Close()
+
+	started := make(chan struct{})
+
+	server := &http.Server{
+		Addr: address,
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(started)
+
+			time.Sleep(50 * time.Millisecond)
+
+			w.WriteHeader(http.StatusOK)
+		}),
+	}
+
+	ctx, cancel := context.WithCancel(context.TODO())
+
+	served := make(chan error, 1)
+
+	go func() {
+		served <- serve(ctx, server, 0, time.Second)
+	}()
+
+	responses := make(chan *http.Response, 1)
+
+	go func() {
+		for {
+			res, err := http.Get("http://" + address)
+
+			if err == nil {
+				responses <- res
+
+				return
+			}
+
+			time.Sleep(5 * time.Millisecond)
+		}
+	}()
+
+	<-started
+
+	cancel()
+
+	res := <-responses
+
+	res.Body.Close()
+
+	assert.Equal(suite.T(), http.StatusOK, res.StatusCode, "In-flight request is not completed")
+	assert.Nil(suite.T(), <-served, "Error is not nil")
+}
+
+func (suite *LifecycleUnitTestSuite) TestLifecycle_Serve_ReportsNotReadyWhileDraining() {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+
+	address := listener.Addr().String()
+
+	listener.Close()
+
+	ctx, cancel := context.WithCancel(context.TODO())
+
+	health := controller.NewHealthController(service.HealthService{
+		Checks:  []service.HealthCheck{shutdownCheck(ctx)},
+		Timeout: time.Second,
+		Logger:  utils.Logger(),
+	})
+
+	server := &http.Server{Addr: address, Handler: http.HandlerFunc(health.Ready)}
+
+	served := make(chan error, 1)
+
+	go func() {
+		served <- serve(ctx, server, 200*time.Millisecond, time.Second)
+	}()
+
+	client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+
+	ready := func() int {
+		res, err := client.Get("http://" + address + "/health/ready")
+
+		if err != nil {
+			return 0
+		}
+
+		res.Body.Close()
+
+		return res.StatusCode
+	}
+
+	assert.Eventually(suite.T(), func() bool { return ready() == http.StatusOK }, time.Second, 5*time.Millisecond, "Service is not ready")
+
+	cancel()
+
+	assert.Equal(suite.T(), http.StatusServiceUnavailable, ready(), "Service is ready while shutting down")
+	assert.Nil(suite.T(), <-served, "Error is not nil")
+}
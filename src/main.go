@@ -1,9 +1,11 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"os"
+	"os/signal"
 	"posts-ms/src/client"
 	"posts-ms/src/config"
 	setupJaeger "posts-ms/src/config/jaeger"
@@ -13,9 +15,14 @@ import (
 	"posts-ms/src/route"
 	"posts-ms/src/service"
 	"posts-ms/src/utils"
+	"syscall"
+	"time"
 
 	"github.com/opentracing/opentracing-go"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/rs/cors"
+	"github.com/sirupsen/logrus"
 	"github.com/streadway/amqp"
 	"gorm.io/gorm"
 )
@@ -23,94 +30,353 @@ import (
 func main() {
 	logger := utils.Logger()
 
-	logger.Info("Connecting with DB")
+	var err error
 
-	dataBase, _ := config.SetupDB()
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		err = runMigrate(logger, os.Args[2:])
+	} else {
+		err = run(logger)
+	}
+
+	if err != nil {
+		logger.WithError(err).Error("Error occured in running service")
+
+		fmt.Fprintln(os.Stderr, err)
+
+		os.Exit(1)
+	}
+
+	logger.Info("Service stopped")
+}
+
+func run(logger *logrus.Entry) error {
+	cfg, err := config.Load()
+
+	if err != nil {
+		return err
+	}
 
-	tracer, trCloser, err := setupJaeger.InitJaeger()
+	logCloser, err := utils.ConfigureLogger(cfg.Logging)
 
 	if err != nil {
-		logger.Debug(err.Error())
+		return err
+	}
+
+	defer logCloser.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+
+	defer stop()
 
-		fmt.Printf("error init jaeger %v", err)
+	tracer, trCloser, err := setupJaeger.InitTracer(cfg.Tracing)
+
+	if err != nil {
+		logger.WithError(err).Error("Error occured in initializing tracer")
 	} else {
-		defer trCloser.Close()
+		defer func() {
+			logger.Info("Flushing traces")
+
+			trCloser.Close()
+		}()
 
 		opentracing.SetGlobalTracer(tracer)
 	}
 
-	amqpServerURL := os.Getenv("AMQP_SERVER_URL")
+	var dataBase *gorm.DB
+
+	if cfg.Storage == config.PostgresStorage {
+		logger.Info("Connecting with DB")
+
+		err = waitFor(ctx, logger, "DB", cfg.Startup, func() error {
+			dataBase, err = config.SetupDB(cfg.Database)
+
+			return err
+		})
+
+		if err != nil {
+			return err
+		}
+
+		defer closeDB(logger, dataBase)
+
+		if err := registerDBStats(dataBase); err != nil {
+			return err
+		}
+
+		if err := prepareSchema(ctx, logger, cfg.Database, dataBase); err != nil {
+			return err
+		}
+	} else {
+		logger.Warn("Using in-memory storage, data is lost on restart")
+	}
 
 	logger.Info("Connecting on RabbitMq")
 
 	rabbit := rabbitmq.RMQProducer{
-		ConnectionString: amqpServerURL,
+		ConnectionString: cfg.RabbitMQ.URL,
 	}
 
-	channel, _ := rabbit.StartRabbitMQ()
+	var connection *amqp.Connection
+	var channel *amqp.Channel
+
+	err = waitFor(ctx, logger, "RabbitMq", cfg.Startup, func() error {
+		connection, channel, err = rabbit.Connect()
 
-	defer channel.Close()
+		return err
+	})
+
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		logger.Info("Closing RabbitMq connection")
+
+		channel.Close()
+		connection.Close()
+	}()
+
+	userClient := client.NewCachingUserRESTClient(
+		client.NewUserRESTClient(cfg.UserService.URL(), cfg.UserService.Timeout),
+		cfg.UserService.CacheSize,
+		cfg.UserService.CacheTTL,
+		cfg.UserService.Timeout,
+	)
+
+	events, err := client.NewEventsDispatcher(
+		client.NewEventsRESTClient(cfg.Events.URL, cfg.Events.Timeout),
+		client.EventsDispatcherConfig{
+			QueueSize: cfg.Events.QueueSize,
+			Workers:   cfg.Events.Workers,
+			Retry: client.RetryConfig{
+				MaxAttempts: cfg.Events.RetryAttempts,
+				BaseBackoff: cfg.Events.RetryBackoff,
+				MaxBackoff:  5 * time.Second,
+			},
+			SpoolFile:      cfg.Events.SpoolFile,
+			ReplayInterval: cfg.Events.ReplayInterval,
+		},
+		logger,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	events.Start()
+
+	defer func() {
+		logger.Info("Flushing system events")
+
+		events.Close()
+	}()
+
+	templates, err := service.LoadNotificationTemplates(cfg.Notification.DefaultLocale)
+
+	if err != nil {
+		return err
+	}
+
+	notifications := service.NewNotificationAggregator(
+		rabbitmq.NotificationPublisher{Channel: channel},
+		templates,
+		service.NotificationAggregatorConfig{
+			Window:       cfg.Notification.AggregationWindow,
+			DedupeWindow: cfg.Notification.DedupeWindow,
+		},
+		utils.Logger(),
+	)
 
-	repositoryContainer := initializeRepositories(dataBase)
-	serviceContainer := initializeServices(repositoryContainer, channel)
-	controllerContainer := initializeControllers(serviceContainer)
+	notifications.Start()
 
-	router := route.SetupRoutes(controllerContainer)
+	defer func() {
+		logger.Info("Flushing notifications")
 
-	port := os.Getenv("SERVER_PORT")
+		notifications.Close()
+	}()
+
+	moderator, err := cfg.Moderation.Moderator()
+
+	if err != nil {
+		return err
+	}
+
+	repositoryContainer := initializeRepositories(cfg, dataBase)
+	healthChecks := append(initializeHealthChecks(cfg, dataBase, connection, channel), shutdownCheck(ctx))
+	serviceContainer := initializeServices(cfg, repositoryContainer, userClient, channel, notifications, moderator, healthChecks)
+	controllerContainer := initializeControllers(serviceContainer, events)
+
+	logger.Info("Consuming media processing results from RabbitMq")
+
+	if err := rabbitmq.ConsumeMediaProcessed(channel, serviceContainer.PostService.HandleMediaProcessed); err != nil {
+		return err
+	}
+
+	if cfg.UserService.UpdatedEvents {
+		logger.Info("Consuming user updates from RabbitMq")
+
+		if err := rabbitmq.ConsumeUserUpdated(channel, userClient.HandleUserUpdated); err != nil {
+			return err
+		}
+	}
+
+	go purgeExpiredIdempotencyKeys(ctx, serviceContainer.IdempotencyService)
+
+	rateLimits := route.RateLimitOptions{TrustProxy: cfg.RateLimit.TrustProxy}
+
+	if cfg.RateLimit.Enabled {
+		if rateLimits.Routes, err = cfg.RateLimit.Limits(); err != nil {
+			return err
+		}
+	}
+
+	router := route.SetupRoutes(controllerContainer, serviceContainer.IdempotencyService, service.NewInMemoryRateLimiter(), rateLimits)
+
+	server := &http.Server{
+		Addr:         fmt.Sprintf(":%s", cfg.Server.Port),
+		Handler:      cors.AllowAll().Handler(router),
+		ReadTimeout:  cfg.Server.ReadTimeout,
+		WriteTimeout: cfg.Server.WriteTimeout,
+		IdleTimeout:  cfg.Server.IdleTimeout,
+	}
 
 	logger.Info("Starting server")
 
-	http.ListenAndServe(fmt.Sprintf(":%s", port), cors.AllowAll().Handler(router))
+	if err := serve(ctx, server, cfg.Server.DrainDelay, cfg.Server.ShutdownTimeout); err != nil {
+		return err
+	}
+
+	logger.Info("Server stopped, in-flight requests drained")
+
+	return nil
+}
+
+func closeDB(logger *logrus.Entry, dataBase *gorm.DB) {
+	logger.Info("Closing DB connection")
+
+	sqlDB, err := dataBase.DB()
+
+	if err != nil {
+		return
+	}
+
+	sqlDB.Close()
 }
 
-func initializeControllers(serviceContainer config.ServiceContainer) config.ControllerContainer {
-	postController := controller.NewPostController(serviceContainer.PostService)
-	likeController := controller.NewLikeController(serviceContainer.LikeService)
-	commentController := controller.NewCommentController(serviceContainer.CommentService)
+// registerDBStats exports the connection pool statistics of dataBase.
+func registerDBStats(dataBase *gorm.DB) error {
+	sqlDB, err := dataBase.DB()
+
+	if err != nil {
+		return err
+	}
+
+	return prometheus.Register(collectors.NewDBStatsCollector(sqlDB, "posts"))
+}
+
+func initializeControllers(serviceContainer config.ServiceContainer, events client.IEventsDispatcher) config.ControllerContainer {
+	postController := controller.NewPostController(serviceContainer.PostService, serviceContainer.AuthorService, events)
+	likeController := controller.NewLikeController(serviceContainer.LikeService, events)
+	commentController := controller.NewCommentController(serviceContainer.CommentService, serviceContainer.AuthorService, events)
+	healthController := controller.NewHealthController(serviceContainer.HealthService)
 
 	container := config.NewControllerContainer(
 		postController,
 		likeController,
 		commentController,
+		healthController,
 	)
 
 	return container
 }
 
-func initializeServices(repositoryContainer config.RepositoryContainer, channel *amqp.Channel) config.ServiceContainer {
-	mediaClient := client.NewMediaRESTClient()
-	userClient := client.NewUserRESTClient()
+func initializeServices(cfg config.Config, repositoryContainer config.RepositoryContainer, userClient client.IUserRESTClient, channel *amqp.Channel, notifications service.INotificationAggregator, moderator service.IModerator, healthChecks []service.HealthCheck) config.ServiceContainer {
+	mediaClient := client.NewMediaRESTClient(cfg.MediaService.URL)
 	postService := service.PostService{
 		PostRepository:    repositoryContainer.PostRepository,
 		LikeRepository:    repositoryContainer.LikeRepository,
 		CommentRepository: repositoryContainer.CommentRepository,
 		MediaClient:       mediaClient,
-		RabbitMQChannel:   channel,
+		MediaPublisher:    rabbitmq.MediaPublisher{Channel: channel},
+		Moderator:         moderator,
+		AsyncMediaUpload:  cfg.MediaService.UploadMode == config.AsyncMediaUpload,
 		Logger:            utils.Logger(),
 	}
-	likeService := service.LikeService{LikeRepository: repositoryContainer.LikeRepository, PostService: postService, UserRESTClient: userClient, RabbitMQChannel: channel, Logger: utils.Logger()}
-	commentService := service.CommentService{CommentRepository: repositoryContainer.CommentRepository, PostService: postService, UserRESTClient: userClient, RabbitMQChannel: channel, Logger: utils.Logger()}
+	likeService := service.LikeService{LikeRepository: repositoryContainer.LikeRepository, PostService: postService, UserRESTClient: userClient, Notifications: notifications, Logger: utils.Logger()}
+	commentService := service.CommentService{CommentRepository: repositoryContainer.CommentRepository, PostService: postService, UserRESTClient: userClient, Notifications: notifications, Moderator: moderator, Logger: utils.Logger()}
+	authorService := service.AuthorService{UserRESTClient: userClient, Logger: utils.Logger()}
+	idempotencyService := service.IdempotencyService{IdempotencyKeyRepository: repositoryContainer.IdempotencyKeyRepository, TTL: cfg.Idempotency.KeyTTL, LockTimeout: cfg.Idempotency.LockTimeout, Logger: utils.Logger()}
+	healthService := service.HealthService{Checks: healthChecks, Timeout: cfg.Health.Timeout, Logger: utils.Logger()}
 
 	container := config.NewServiceContainer(
 		postService,
 		likeService,
 		commentService,
+		idempotencyService,
+		authorService,
+		healthService,
 	)
 
 	return container
 }
 
-func initializeRepositories(dataBase *gorm.DB) config.RepositoryContainer {
+func initializeHealthChecks(cfg config.Config, dataBase *gorm.DB, connection *amqp.Connection, channel *amqp.Channel) []service.HealthCheck {
+	checks := []service.HealthCheck{
+		{Name: "rabbitmq", Critical: true, Check: rabbitmq.NewHealthCheck(connection, channel)},
+	}
+
+	if dataBase != nil {
+		checks = append(checks, service.HealthCheck{Name: "database", Critical: true, Check: config.NewDatabaseHealthCheck(dataBase)})
+	}
+
+	if cfg.Health.CheckDependencies {
+		checks = append(checks,
+			service.HealthCheck{Name: "users-ms", Check: client.NewReachabilityCheck(cfg.UserService.URL())},
+			service.HealthCheck{Name: "media-ms", Check: client.NewReachabilityCheck(cfg.MediaService.URL)},
+		)
+	}
+
+	return checks
+}
+
+func initializeRepositories(cfg config.Config, dataBase *gorm.DB) config.RepositoryContainer {
+	if cfg.Storage == config.MemoryStorage {
+		store := repository.NewInMemoryStore()
+
+		return config.NewRepositoryContainer(
+			repository.InMemoryPostRepository{Store: store},
+			repository.InMemoryLikeRepository{Store: store},
+			repository.InMemoryCommentRepository{Store: store},
+			repository.InMemoryIdempotencyKeyRepository{Store: store},
+		)
+	}
+
 	postRepository := repository.PostRepository{Database: dataBase}
 	likeRepository := repository.LikeRepository{Database: dataBase}
 	commentRepository := repository.CommentRepository{Database: dataBase}
+	idempotencyKeyRepository := repository.IdempotencyKeyRepository{Database: dataBase}
 
 	container := config.NewRepositoryContainer(
 		postRepository,
 		likeRepository,
 		commentRepository,
+		idempotencyKeyRepository,
 	)
 
 	return container
 }
+
+func purgeExpiredIdempotencyKeys(ctx context.Context, idempotencyService service.IIdempotencyService) {
+	ticker := time.NewTicker(time.Hour)
+
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			idempotencyService.DeleteExpired(ctx)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"posts-ms/src/config"
+	"posts-ms/src/migration"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const migrateUsage = "usage: migrate up | down [steps] | status"
+
+// runMigrate implements the migrate subcommand.
+func runMigrate(logger *logrus.Entry, args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	cfg, err := config.LoadMigration()
+
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+
+	var dataBase *gorm.DB
+
+	err = waitFor(ctx, logger, "DB", cfg.Startup, func() error {
+		dataBase, err = config.SetupDB(cfg.Database)
+
+		return err
+	})
+
+	if err != nil {
+		return err
+	}
+
+	defer closeDB(logger, dataBase)
+
+	migrator, err := migration.NewMigrator(dataBase, logger)
+
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "up":
+		if err := migrator.Up(ctx); err != nil {
+			return err
+		}
+
+		return printMigrationStatus(migrator, ctx)
+	case "down":
+		steps := 1
+
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps <= 0 {
+				return errors.New(migrateUsage)
+			}
+		}
+
+		if err := migrator.Down(steps, ctx); err != nil {
+			return err
+		}
+
+		return printMigrationStatus(migrator, ctx)
+	case "status":
+		return printMigrationStatus(migrator, ctx)
+	default:
+		return errors.New(migrateUsage)
+	}
+}
+
+func printMigrationStatus(migrator migration.Migrator, ctx context.Context) error {
+	statuses, err := migrator.Status(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(writer, "VERSION\tNAME\tAPPLIED AT")
+
+	for _, status := range statuses {
+		appliedAt := "pending"
+
+		if status.AppliedAt != nil {
+			appliedAt = status.AppliedAt.Format(time.RFC3339)
+		}
+
+		fmt.Fprintf(writer, "%d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+	}
+
+	return writer.Flush()
+}
+
+// prepareSchema refuses to start against a schema that is not at the version
+// this binary expects, applying pending migrations first when configured to.
+func prepareSchema(ctx context.Context, logger *logrus.Entry, cfg config.DatabaseConfig, dataBase *gorm.DB) error {
+	migrator, err := migration.NewMigrator(dataBase, logger)
+
+	if err != nil {
+		return err
+	}
+
+	if cfg.AutoMigrate {
+		logger.Info("Applying pending migrations")
+
+		if err := migrator.Up(ctx); err != nil {
+			return err
+		}
+	}
+
+	return migrator.CheckVersion(ctx)
+}
@@ -0,0 +1,398 @@
+package migration
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+//go:embed sql/*.sql
+var embedded embed.FS
+
+// lockId serializes migrations run by several instances at the same time.
+const lockId = 7293811
+
+var fileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// noTransaction is the first line of a file whose statements must not run in
+// a transaction, such as CREATE INDEX CONCURRENTLY. The statements of such a
+// file are run one at a time and are split at semicolons that end a line,
+// outside of $$ quoted bodies.
+const noTransaction = "-- migrate:no-transaction"
+
+var statementEnd = regexp.MustCompile(`;[ \t]*(\r?\n|$)`)
+
+var ErrUnexpectedSchemaVersion = errors.New("unexpected schema version")
+
+type Migration struct {
+	Version           int
+	Name              string
+	Up                string
+	Down              string
+	UpNoTransaction   bool
+	DownNoTransaction bool
+}
+
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	AppliedAt *time.Time
+}
+
+type appliedMigration struct {
+	Version   int `gorm:"primaryKey;autoIncrement:false"`
+	Name      string
+	AppliedAt time.Time
+}
+
+func (appliedMigration) TableName() string {
+	return "schema_migrations"
+}
+
+type Migrator struct {
+	Database   *gorm.DB
+	Migrations []Migration
+	Logger     *logrus.Entry
+}
+
+// NewMigrator returns a migrator for the migrations embedded in the binary.
+func NewMigrator(db *gorm.DB, logger *logrus.Entry) (Migrator, error) {
+	migrations, err := load(embedded)
+
+	if err != nil {
+		return Migrator{}, err
+	}
+
+	return Migrator{Database: db, Migrations: migrations, Logger: logger}, nil
+}
+
+// load reads <version>_<name>.up.sql and <version>_<name>.down.sql pairs from
+// the sql directory. Versions must start at 1 and have no gaps.
+func load(fsys fs.FS) ([]Migration, error) {
+	files, err := fs.ReadDir(fsys, "sql")
+
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int]*Migration{}
+
+	for _, file := range files {
+		match := fileName.FindStringSubmatch(file.Name())
+
+		if match == nil {
+			return nil, fmt.Errorf("migration %s: name does not match <version>_<name>.<up|down>.sql", file.Name())
+		}
+
+		version, _ := strconv.Atoi(match[1])
+
+		content, err := fs.ReadFile(fsys, "sql/"+file.Name())
+
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration %d: names %s and %s differ", version, migration.Name, match[2])
+		}
+
+		inTransaction := !strings.HasPrefix(string(content), noTransaction)
+
+		if match[3] == "up" {
+			migration.Up = string(content)
+			migration.UpNoTransaction = !inTransaction
+		} else {
+			migration.Down = string(content)
+			migration.DownNoTransaction = !inTransaction
+		}
+	}
+
+	migrations := []Migration{}
+
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %d: both up and down files are required", migration.Version)
+		}
+
+		migrations = append(migrations, *migration)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	for i, migration := range migrations {
+		if migration.Version != i+1 {
+			return nil, fmt.Errorf("migration %d: expected version %d", migration.Version, i+1)
+		}
+	}
+
+	return migrations, nil
+}
+
+func (m Migrator) LatestVersion() int {
+	return len(m.Migrations)
+}
+
+// CurrentVersion returns the highest applied version, 0 for an empty schema.
+func (m Migrator) CurrentVersion(ctx context.Context) (int, error) {
+	if err := m.ensureTable(ctx); err != nil {
+		return 0, err
+	}
+
+	return currentVersion(m.Database.WithContext(ctx))
+}
+
+func currentVersion(db *gorm.DB) (int, error) {
+	var version int
+
+	err := db.Model(&appliedMigration{}).Select("COALESCE(MAX(version), 0)").Scan(&version).Error
+
+	return version, err
+}
+
+// Up applies every pending migration, each in its own transaction unless its
+// file is marked with noTransaction.
+func (m Migrator) Up(ctx context.Context) error {
+	if err := m.ensureTable(ctx); err != nil {
+		return err
+	}
+
+	for _, migration := range m.Migrations {
+		applied, err := m.apply(migration, ctx)
+
+		if err != nil {
+			return fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+		}
+
+		if applied {
+			m.Logger.Infof("Applied migration %d_%s", migration.Version, migration.Name)
+		}
+	}
+
+	return nil
+}
+
+// Down reverts the last steps applied migrations, newest first. The version
+// to revert is read once the lock is held, so that two instances running Down
+// at the same time revert different migrations.
+func (m Migrator) Down(steps int, ctx context.Context) error {
+	if err := m.ensureTable(ctx); err != nil {
+		return err
+	}
+
+	for i := 0; i < steps; i++ {
+		var migration *Migration
+
+		err := m.withLock(ctx, func(conn *gorm.DB) error {
+			version, err := currentVersion(conn)
+
+			if err != nil {
+				return err
+			}
+
+			if version == 0 {
+				return nil
+			}
+
+			if version > m.LatestVersion() {
+				return fmt.Errorf("%w: version %d is not known to this binary", ErrUnexpectedSchemaVersion, version)
+			}
+
+			migration = &m.Migrations[version-1]
+
+			return run(conn, migration.Down, migration.DownNoTransaction, func(db *gorm.DB) error {
+				return db.Delete(&appliedMigration{}, migration.Version).Error
+			})
+		})
+
+		if err != nil {
+			if migration != nil {
+				return fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+
+			return err
+		}
+
+		if migration == nil {
+			return nil
+		}
+
+		m.Logger.Infof("Reverted migration %d_%s", migration.Version, migration.Name)
+	}
+
+	return nil
+}
+
+func (m Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	if err := m.ensureTable(ctx); err != nil {
+		return nil, err
+	}
+
+	var applied []appliedMigration
+
+	if err := m.Database.WithContext(ctx).Order("version").Find(&applied).Error; err != nil {
+		return nil, err
+	}
+
+	appliedAt := map[int]time.Time{}
+
+	for _, migration := range applied {
+		appliedAt[migration.Version] = migration.AppliedAt
+	}
+
+	statuses := []MigrationStatus{}
+
+	for _, migration := range m.Migrations {
+		status := MigrationStatus{Version: migration.Version, Name: migration.Name}
+
+		if at, ok := appliedAt[migration.Version]; ok {
+			status.AppliedAt = &at
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+// CheckVersion fails unless the schema is exactly at the latest version
+// embedded in the binary.
+func (m Migrator) CheckVersion(ctx context.Context) error {
+	version, err := m.CurrentVersion(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	if version != m.LatestVersion() {
+		return fmt.Errorf("%w: database is at version %d, expected %d", ErrUnexpectedSchemaVersion, version, m.LatestVersion())
+	}
+
+	return nil
+}
+
+func (m Migrator) ensureTable(ctx context.Context) error {
+	return m.Database.WithContext(ctx).Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    bigint PRIMARY KEY,
+		name       text NOT NULL,
+		applied_at timestamptz NOT NULL DEFAULT now()
+	)`).Error
+}
+
+func (m Migrator) apply(migration Migration, ctx context.Context) (bool, error) {
+	applied := false
+
+	err := m.withLock(ctx, func(conn *gorm.DB) error {
+		var count int64
+
+		if err := conn.Model(&appliedMigration{}).Where("version = ?", migration.Version).Count(&count).Error; err != nil {
+			return err
+		}
+
+		if count > 0 {
+			return nil
+		}
+
+		applied = true
+
+		return run(conn, migration.Up, migration.UpNoTransaction, func(db *gorm.DB) error {
+			return db.Create(&appliedMigration{Version: migration.Version, Name: migration.Name, AppliedAt: time.Now()}).Error
+		})
+	})
+
+	return applied, err
+}
+
+// withLock calls fn on a connection of its own that holds a session-level
+// advisory lock, which serializes migrations run by several instances. A
+// transaction-level lock cannot be used because some files must run outside
+// a transaction.
+func (m Migrator) withLock(ctx context.Context, fn func(*gorm.DB) error) error {
+	return m.Database.WithContext(ctx).Connection(func(conn *gorm.DB) error {
+		if err := conn.Exec("SELECT pg_advisory_lock(?)", lockId).Error; err != nil {
+			return err
+		}
+
+		defer func() {
+			// The connection goes back to the pool, so the lock is released even
+			// when ctx is done.
+			if err := conn.WithContext(context.Background()).Exec("SELECT pg_advisory_unlock(?)", lockId).Error; err != nil {
+				m.Logger.WithError(err).Error("Error occured in releasing migration lock")
+			}
+		}()
+
+		return fn(conn)
+	})
+}
+
+// run executes a migration file and records the outcome with record. A file
+// marked with noTransaction is executed statement by statement, and a failure
+// part way leaves the statements before it applied.
+func run(conn *gorm.DB, script string, noTransaction bool, record func(*gorm.DB) error) error {
+	if !noTransaction {
+		return conn.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec(script).Error; err != nil {
+				return err
+			}
+
+			return record(tx)
+		})
+	}
+
+	for _, statement := range statements(script) {
+		if err := conn.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+
+	return record(conn)
+}
+
+// statements splits a file into statements, leaving out the ones that are
+// empty or only comments. Semicolons inside a $$ quoted body, as in a DO
+// block, do not end a statement.
+func statements(script string) []string {
+	result := []string{}
+	start := 0
+
+	for _, end := range statementEnd.FindAllStringIndex(script, -1) {
+		if strings.Count(script[start:end[0]], "$$")%2 == 1 {
+			continue
+		}
+
+		result = appendStatement(result, script[start:end[0]])
+		start = end[1]
+	}
+
+	return appendStatement(result, script[start:])
+}
+
+func appendStatement(result []string, statement string) []string {
+	for _, line := range strings.Split(statement, "\n") {
+		line = strings.TrimSpace(line)
+
+		if line != "" && !strings.HasPrefix(line, "--") {
+			return append(result, strings.TrimSpace(statement))
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,69 @@
+package migration
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"posts-ms/src/utils"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type MigratorIntegrationTestSuite struct {
+	suite.Suite
+	migrator Migrator
+}
+
+func TestMigratorIntegrationTestSuite(t *testing.T) {
+	suite.Run(t, new(MigratorIntegrationTestSuite))
+}
+
+func (suite *MigratorIntegrationTestSuite) SetupSuite() {
+	connectionString := fmt.Sprintf(
+		"host=%s user=%s password=%s dbname=%s port=%s sslmode=disable",
+		os.Getenv("DATABASE_DOMAIN"),
+		os.Getenv("DATABASE_USERNAME"),
+		os.Getenv("DATABASE_PASSWORD"),
+		os.Getenv("DATABASE_SCHEMA"),
+		os.Getenv("DATABASE_PORT"),
+	)
+
+	db, _ := gorm.Open(postgres.Open(connectionString), &gorm.Config{})
+
+	suite.migrator, _ = NewMigrator(db, utils.Logger())
+}
+
+func (suite *MigratorIntegrationTestSuite) TestIntegrationMigrator_Up_IsIdempotent() {
+	err := suite.migrator.Up(context.Background())
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+
+	err = suite.migrator.Up(context.Background())
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+
+	version, err := suite.migrator.CurrentVersion(context.Background())
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+	assert.Equal(suite.T(), suite.migrator.LatestVersion(), version, "Schema is not at latest version")
+	assert.Nil(suite.T(), suite.migrator.CheckVersion(context.Background()), "Schema version is rejected")
+}
+
+func (suite *MigratorIntegrationTestSuite) TestIntegrationMigrator_Status_ReportsApplied() {
+	err := suite.migrator.Up(context.Background())
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+
+	statuses, err := suite.migrator.Status(context.Background())
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+	assert.Equal(suite.T(), suite.migrator.LatestVersion(), len(statuses), "Statuses are missing")
+
+	for _, status := range statuses {
+		assert.NotNil(suite.T(), status.AppliedAt, "Migration is not applied")
+	}
+}
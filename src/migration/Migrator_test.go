@@ -0,0 +1,148 @@
+package migration
+
+import (
+	"regexp"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+var (
+	createIndex        = regexp.MustCompile(`(?m)^CREATE (UNIQUE )?INDEX`)
+	foreignKey         = regexp.MustCompile(`ADD CONSTRAINT \w+ FOREIGN KEY`)
+	validateConstraint = regexp.MustCompile(`(?m)^ALTER TABLE \w+ VALIDATE CONSTRAINT`)
+)
+
+type MigratorUnitTestSuite struct {
+	suite.Suite
+}
+
+func TestMigratorUnitTestSuite(t *testing.T) {
+	suite.Run(t, new(MigratorUnitTestSuite))
+}
+
+func (suite *MigratorUnitTestSuite) TestMigrator_Load_EmbeddedMigrations() {
+	migrations, err := load(embedded)
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+	assert.NotEmpty(suite.T(), migrations, "Migrations are empty")
+
+	for i, migration := range migrations {
+		assert.Equal(suite.T(), i+1, migration.Version, "Versions are not sequential")
+		assert.NotEmpty(suite.T(), migration.Up, "Up migration is empty")
+		assert.NotEmpty(suite.T(), migration.Down, "Down migration is empty")
+	}
+}
+
+func (suite *MigratorUnitTestSuite) TestMigrator_Load_SortsByVersion() {
+	migrations, err := load(fstest.MapFS{
+		"sql/0002_second.up.sql":   {Data: []byte("SELECT 2")},
+		"sql/0002_second.down.sql": {Data: []byte("SELECT -2")},
+		"sql/0001_first.up.sql":    {Data: []byte("SELECT 1")},
+		"sql/0001_first.down.sql":  {Data: []byte("SELECT -1")},
+	})
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+	assert.Equal(suite.T(), 2, len(migrations), "Length of migrations not 2")
+	assert.Equal(suite.T(), "first", migrations[0].Name, "First migration is not first")
+	assert.Equal(suite.T(), "SELECT -2", migrations[1].Down, "Down migration is not read")
+}
+
+func (suite *MigratorUnitTestSuite) TestMigrator_Load_MissingDown() {
+	_, err := load(fstest.MapFS{
+		"sql/0001_first.up.sql": {Data: []byte("SELECT 1")},
+	})
+
+	assert.NotNil(suite.T(), err, "Error is nil")
+}
+
+func (suite *MigratorUnitTestSuite) TestMigrator_Load_VersionGap() {
+	_, err := load(fstest.MapFS{
+		"sql/0001_first.up.sql":   {Data: []byte("SELECT 1")},
+		"sql/0001_first.down.sql": {Data: []byte("SELECT -1")},
+		"sql/0003_third.up.sql":   {Data: []byte("SELECT 3")},
+		"sql/0003_third.down.sql": {Data: []byte("SELECT -3")},
+	})
+
+	assert.NotNil(suite.T(), err, "Error is nil")
+}
+
+func (suite *MigratorUnitTestSuite) TestMigrator_Load_InvalidName() {
+	_, err := load(fstest.MapFS{
+		"sql/first.sql": {Data: []byte("SELECT 1")},
+	})
+
+	assert.NotNil(suite.T(), err, "Error is nil")
+}
+
+func (suite *MigratorUnitTestSuite) TestMigrator_Load_NoTransactionMarker() {
+	migrations, err := load(fstest.MapFS{
+		"sql/0001_first.up.sql":   {Data: []byte("-- migrate:no-transaction\nCREATE INDEX CONCURRENTLY idx ON t (c);")},
+		"sql/0001_first.down.sql": {Data: []byte("DROP INDEX idx;")},
+	})
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+	assert.True(suite.T(), migrations[0].UpNoTransaction, "Up migration is run in a transaction")
+	assert.False(suite.T(), migrations[0].DownNoTransaction, "Down migration is not run in a transaction")
+}
+
+func (suite *MigratorUnitTestSuite) TestMigrator_Statements_SplitsAtLineEnds() {
+	script := `-- migrate:no-transaction
+-- Indexes are built without blocking writes.
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_a ON a (x);
+
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_b
+    ON b (y);
+-- Trailing comment`
+
+	assert.Equal(suite.T(), []string{
+		"-- migrate:no-transaction\n-- Indexes are built without blocking writes.\nCREATE INDEX CONCURRENTLY IF NOT EXISTS idx_a ON a (x)",
+		"CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_b\n    ON b (y)",
+	}, statements(script), "Statements are not split")
+}
+
+func (suite *MigratorUnitTestSuite) TestMigrator_Statements_KeepsDollarQuotedBodies() {
+	script := `DO $$
+BEGIN
+    PERFORM 1;
+END
+$$;
+SELECT 2;`
+
+	assert.Equal(suite.T(), []string{"DO $$\nBEGIN\n    PERFORM 1;\nEND\n$$", "SELECT 2"}, statements(script), "DO block is split")
+}
+
+func (suite *MigratorUnitTestSuite) TestMigrator_Load_IndexesAreBuiltConcurrently() {
+	migrations, err := load(embedded)
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+	assert.True(suite.T(), migrations[1].UpNoTransaction, "Indexes are built in a transaction")
+
+	for _, statement := range statements(migrations[1].Up) {
+		if createIndex.MatchString(statement) {
+			assert.Contains(suite.T(), statement, "CONCURRENTLY", "Index is not built concurrently")
+		}
+	}
+}
+
+func (suite *MigratorUnitTestSuite) TestMigrator_Load_ForeignKeysAreValidatedSeparately() {
+	migrations, err := load(embedded)
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+
+	validated := 0
+
+	for _, statement := range statements(migrations[1].Up) {
+		if foreignKey.MatchString(statement) {
+			assert.Contains(suite.T(), statement, "NOT VALID", "Foreign key checks existing rows when it is added")
+		}
+
+		if validateConstraint.MatchString(statement) {
+			validated++
+		}
+	}
+
+	assert.Equal(suite.T(), 2, validated, "Foreign keys are not validated")
+}
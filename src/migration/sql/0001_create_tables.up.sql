@@ -0,0 +1,58 @@
+-- Matches the schema previously created by gorm AutoMigrate, so databases
+-- that were set up before migrations existed are adopted as they are.
+
+CREATE TABLE IF NOT EXISTS posts (
+    id            bigserial PRIMARY KEY,
+    created_at    timestamptz,
+    updated_at    timestamptz,
+    deleted_at    timestamptz,
+    description   text DEFAULT NULL,
+    image_id      bigint,
+    media_status  bigint NOT NULL DEFAULT 0,
+    user_id       bigint,
+    total_likes   bigint,
+    total_unlikes bigint
+);
+
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS media_status bigint NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_posts_deleted_at ON posts (deleted_at);
+
+CREATE TABLE IF NOT EXISTS likes (
+    id         bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    user_id    bigint NOT NULL,
+    post_id    bigint NOT NULL,
+    like_type  bigint NOT NULL,
+    CONSTRAINT fk_posts_likes FOREIGN KEY (post_id) REFERENCES posts (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_likes_deleted_at ON likes (deleted_at);
+
+CREATE TABLE IF NOT EXISTS comments (
+    id         bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    content    text NOT NULL,
+    user_id    bigint NOT NULL,
+    post_id    bigint NOT NULL,
+    CONSTRAINT fk_posts_comments FOREIGN KEY (post_id) REFERENCES posts (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_comments_deleted_at ON comments (deleted_at);
+
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    key          text PRIMARY KEY,
+    request_hash text NOT NULL,
+    completed    boolean NOT NULL DEFAULT false,
+    status_code  bigint,
+    content_type text,
+    body         bytea,
+    created_at   timestamptz,
+    expires_at   timestamptz NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys (expires_at);
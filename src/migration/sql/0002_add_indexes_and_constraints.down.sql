@@ -0,0 +1,18 @@
+-- migrate:no-transaction
+ALTER TABLE comments
+    DROP CONSTRAINT IF EXISTS fk_posts_comments,
+    ADD CONSTRAINT fk_posts_comments FOREIGN KEY (post_id) REFERENCES posts (id) NOT VALID;
+
+ALTER TABLE comments VALIDATE CONSTRAINT fk_posts_comments;
+
+ALTER TABLE likes
+    DROP CONSTRAINT IF EXISTS fk_posts_likes,
+    ADD CONSTRAINT fk_posts_likes FOREIGN KEY (post_id) REFERENCES posts (id) NOT VALID;
+
+ALTER TABLE likes VALIDATE CONSTRAINT fk_posts_likes;
+
+DROP INDEX CONCURRENTLY IF EXISTS idx_posts_user_id_created_at;
+DROP INDEX CONCURRENTLY IF EXISTS idx_comments_post_id;
+DROP INDEX CONCURRENTLY IF EXISTS idx_likes_post_id;
+
+ALTER TABLE likes DROP CONSTRAINT IF EXISTS uq_likes_user_id_post_id;
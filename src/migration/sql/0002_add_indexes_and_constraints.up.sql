@@ -0,0 +1,75 @@
+-- migrate:no-transaction
+-- Runs outside a transaction so that the indexes are built without blocking
+-- writes to the tables. Every statement can be run again, so a migration that
+-- failed part way is completed by running it once more.
+
+-- A build that failed part way leaves an invalid index behind, which
+-- CREATE INDEX IF NOT EXISTS would take as done.
+DO $$
+DECLARE
+    invalid record;
+BEGIN
+    FOR invalid IN
+        SELECT class.relname
+        FROM pg_index
+        JOIN pg_class class ON class.oid = pg_index.indexrelid
+        WHERE NOT pg_index.indisvalid
+          AND class.relname IN ('uq_likes_user_id_post_id', 'idx_likes_post_id', 'idx_comments_post_id', 'idx_posts_user_id_created_at')
+    LOOP
+        EXECUTE format('DROP INDEX %I', invalid.relname);
+    END LOOP;
+END
+$$;
+
+-- Deleted likes are removed for good, as LikeRepository.Delete does, so that
+-- they cannot take the place of a user's reaction in the unique index.
+DELETE FROM likes WHERE deleted_at IS NOT NULL;
+
+-- A user has at most one reaction per post. Keep the newest of any
+-- duplicates and recount the totals they were included in.
+DELETE FROM likes older
+USING likes newer
+WHERE older.user_id = newer.user_id
+  AND older.post_id = newer.post_id
+  AND older.id < newer.id;
+
+UPDATE posts
+SET total_likes = (
+        SELECT count(*) FROM likes
+        WHERE likes.post_id = posts.id AND likes.like_type = 1 AND likes.deleted_at IS NULL
+    ),
+    total_unlikes = (
+        SELECT count(*) FROM likes
+        WHERE likes.post_id = posts.id AND likes.like_type <> 1 AND likes.deleted_at IS NULL
+    );
+
+-- A duplicate reaction saved after the cleanup above fails the build, and
+-- the migration has to be run again.
+CREATE UNIQUE INDEX CONCURRENTLY IF NOT EXISTS uq_likes_user_id_post_id ON likes (user_id, post_id);
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'uq_likes_user_id_post_id') THEN
+        ALTER TABLE likes ADD CONSTRAINT uq_likes_user_id_post_id UNIQUE USING INDEX uq_likes_user_id_post_id;
+    END IF;
+END
+$$;
+
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_likes_post_id ON likes (post_id);
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_comments_post_id ON comments (post_id);
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_posts_user_id_created_at ON posts (user_id, created_at DESC);
+
+-- The foreign keys are added without checking the existing rows, which would
+-- hold a lock that blocks writes, and are validated afterwards under a lock
+-- that does not.
+ALTER TABLE likes
+    DROP CONSTRAINT IF EXISTS fk_posts_likes,
+    ADD CONSTRAINT fk_posts_likes FOREIGN KEY (post_id) REFERENCES posts (id) ON DELETE CASCADE NOT VALID;
+
+ALTER TABLE likes VALIDATE CONSTRAINT fk_posts_likes;
+
+ALTER TABLE comments
+    DROP CONSTRAINT IF EXISTS fk_posts_comments,
+    ADD CONSTRAINT fk_posts_comments FOREIGN KEY (post_id) REFERENCES posts (id) ON DELETE CASCADE NOT VALID;
+
+ALTER TABLE comments VALIDATE CONSTRAINT fk_posts_comments;
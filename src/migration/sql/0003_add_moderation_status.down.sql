@@ -0,0 +1,7 @@
+ALTER TABLE comments
+    DROP COLUMN IF EXISTS moderation_reason,
+    DROP COLUMN IF EXISTS moderation_status;
+
+ALTER TABLE posts
+    DROP COLUMN IF EXISTS moderation_reason,
+    DROP COLUMN IF EXISTS moderation_status;
@@ -0,0 +1,8 @@
+-- Content created before moderation existed counts as approved.
+ALTER TABLE posts
+    ADD COLUMN moderation_status bigint NOT NULL DEFAULT 0,
+    ADD COLUMN moderation_reason text DEFAULT NULL;
+
+ALTER TABLE comments
+    ADD COLUMN moderation_status bigint NOT NULL DEFAULT 0,
+    ADD COLUMN moderation_reason text DEFAULT NULL;
@@ -0,0 +1,5 @@
+-- Keys reserved before locks existed count as locked until they were
+-- created, so a retry can take over the ones that never completed.
+ALTER TABLE idempotency_keys ADD COLUMN locked_until timestamptz;
+
+UPDATE idempotency_keys SET locked_until = created_at WHERE locked_until IS NULL;
@@ -0,0 +1,19 @@
+package rabbitmq
+
+import (
+	"context"
+
+	"github.com/streadway/amqp"
+)
+
+type IMediaPublisher interface {
+	DeleteImage(uint, context.Context) error
+}
+
+type MediaPublisher struct {
+	Channel *amqp.Channel
+}
+
+func (p MediaPublisher) DeleteImage(id uint, ctx context.Context) error {
+	return DeleteImage(id, p.Channel, ctx)
+}
@@ -0,0 +1,15 @@
+package rabbitmq
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MediaPublisherMock struct {
+	mock.Mock
+}
+
+func (m *MediaPublisherMock) DeleteImage(id uint, ctx context.Context) error {
+	return m.Called(id, ctx).Error(0)
+}
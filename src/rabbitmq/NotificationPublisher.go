@@ -0,0 +1,20 @@
+package rabbitmq
+
+import (
+	"context"
+	"posts-ms/src/dto/request"
+
+	"github.com/streadway/amqp"
+)
+
+type INotificationPublisher interface {
+	AddNotification(*request.NotificationDTO, context.Context) error
+}
+
+type NotificationPublisher struct {
+	Channel *amqp.Channel
+}
+
+func (p NotificationPublisher) AddNotification(notification *request.NotificationDTO, ctx context.Context) error {
+	return AddNotification(notification, p.Channel, ctx)
+}
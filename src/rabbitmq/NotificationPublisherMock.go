@@ -0,0 +1,16 @@
+package rabbitmq
+
+import (
+	"context"
+	"posts-ms/src/dto/request"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type NotificationPublisherMock struct {
+	mock.Mock
+}
+
+func (m *NotificationPublisherMock) AddNotification(notification *request.NotificationDTO, ctx context.Context) error {
+	return m.Called(notification, ctx).Error(0)
+}
@@ -0,0 +1,308 @@
+package rabbitmq
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"posts-ms/src/dto/response"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/streadway/amqp"
+)
+
+// ErrUnprocessable marks a message that fails however often it is delivered.
+// Such messages are dead-lettered, any other failure is delivered again.
+var ErrUnprocessable = errors.New("message cannot be processed")
+
+const (
+	// redeliveryDelay is how long a message that failed waits in the retry
+	// queue, so that an outage of a dependency does not turn into a busy loop.
+	redeliveryDelay = 5 * time.Second
+	// maxRedeliveries is how often a message is delivered again before it is
+	// dead-lettered.
+	maxRedeliveries = 5
+	// prefetchCount bounds the messages a consumer holds unacknowledged.
+	prefetchCount = 10
+)
+
+// publisher is the part of *amqp.Channel a consumer needs to retry messages.
+type publisher interface {
+	Publish(exchange string, key string, mandatory bool, immediate bool, msg amqp.Publishing) error
+}
+
+type MediaProcessedHandler func(response.MediaProcessedDto, context.Context) error
+
+type UserUpdatedHandler func(response.UserUpdatedDto, context.Context) error
+
+// consumer reads the messages routed from exchange to queue. Without a queue
+// name every instance gets an exclusive queue of its own that is deleted when
+// the instance disconnects, so that each instance sees every message.
+type consumer struct {
+	exchange      string
+	routingKey    string
+	queue         string
+	operationName string
+	handle        func([]byte, context.Context) error
+	retryQueue    string
+}
+
+func ConsumeMediaProcessed(channel *amqp.Channel, handler MediaProcessedHandler) error {
+	return consume(channel, consumer{
+		exchange:      "MediaProcessed-MS-exchange",
+		routingKey:    "MediaProcessed-MS-routing-key",
+		queue:         "MediaProcessed-Posts-MS-queue",
+		operationName: "Third service (rabbitmq) - Receive media processing result from media-ms",
+		handle: func(body []byte, ctx context.Context) error {
+			var result response.MediaProcessedDto
+
+			if err := json.Unmarshal(body, &result); err != nil {
+				return fmt.Errorf("%w: %v", ErrUnprocessable, err)
+			}
+
+			return handler(result, ctx)
+		},
+	})
+}
+
+func ConsumeUserUpdated(channel *amqp.Channel, handler UserUpdatedHandler) error {
+	return consume(channel, consumer{
+		exchange:      "UserUpdated-MS-exchange",
+		routingKey:    "UserUpdated-MS-routing-key",
+		operationName: "Third service (rabbitmq) - Receive user update from user-ms",
+		handle: func(body []byte, ctx context.Context) error {
+			var user response.UserUpdatedDto
+
+			if err := json.Unmarshal(body, &user); err != nil {
+				return fmt.Errorf("%w: %v", ErrUnprocessable, err)
+			}
+
+			return handler(user, ctx)
+		},
+	})
+}
+
+func consume(channel *amqp.Channel, c consumer) error {
+	err := channel.ExchangeDeclare(
+		c.exchange, // name
+		"direct",   // type
+		true,       // durable
+		false,      // auto-deleted
+		false,      // internal
+		false,      // no-wait
+		nil,        // arguments
+	)
+
+	if err != nil {
+		return err
+	}
+
+	queue, err := c.declareQueue(channel)
+
+	if err != nil {
+		return err
+	}
+
+	if c.queue != "" {
+		if c.retryQueue, err = declareRetry(channel, c.queue); err != nil {
+			return err
+		}
+	}
+
+	err = channel.QueueBind(
+		queue.Name,   // queue
+		c.routingKey, // routing key
+		c.exchange,   // exchange
+		false,        // no-wait
+		nil,          // arguments
+	)
+
+	if err != nil {
+		return err
+	}
+
+	if err := channel.Qos(prefetchCount, 0, false); err != nil {
+		return err
+	}
+
+	messages, err := channel.Consume(
+		queue.Name, // queue
+		"",         // consumer
+		false,      // auto-ack
+		false,      // exclusive
+		false,      // no-local
+		false,      // no-wait
+		nil,        // arguments
+	)
+
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		for message := range messages {
+			c.deliver(channel, message)
+		}
+	}()
+
+	return nil
+}
+
+// deliver hands a message to the handler. A message that failed is moved to
+// the retry queue, which sends it back once redeliveryDelay has passed, so
+// that the consumer goes on with other messages in the meantime. Messages that
+// cannot be processed, or failed maxRedeliveries times, are dead-lettered.
+func (c consumer) deliver(channel publisher, message amqp.Delivery) {
+	span := startConsumerSpan(c.operationName, message.Headers)
+
+	defer span.Finish()
+
+	ctx := opentracing.ContextWithSpan(context.Background(), span)
+
+	err := c.handle(message.Body, ctx)
+
+	if err == nil {
+		message.Ack(false)
+
+		return
+	}
+
+	span.SetTag("error", true)
+
+	if errors.Is(err, ErrUnprocessable) || c.retryQueue == "" || redeliveries(message.Headers, c.retryQueue) >= maxRedeliveries {
+		message.Nack(false, false)
+
+		return
+	}
+
+	err = channel.Publish(
+		"",           // exchange
+		c.retryQueue, // routing key
+		false,        // mandatory
+		false,        // immediate
+		amqp.Publishing{
+			ContentType:  message.ContentType,
+			DeliveryMode: amqp.Persistent,
+			MessageId:    message.MessageId,
+			Timestamp:    message.Timestamp,
+			Headers:      message.Headers,
+			Body:         message.Body,
+		})
+
+	if err != nil {
+		message.Nack(false, true)
+
+		return
+	}
+
+	message.Ack(false)
+}
+
+// redeliveries counts how often a message has come back from retryQueue,
+// from the x-death header the broker adds when it expires there.
+func redeliveries(headers amqp.Table, retryQueue string) int64 {
+	deaths, _ := headers["x-death"].([]interface{})
+
+	for _, death := range deaths {
+		if table, ok := death.(amqp.Table); ok && table["queue"] == retryQueue {
+			count, _ := table["count"].(int64)
+
+			return count
+		}
+	}
+
+	return 0
+}
+
+func (c consumer) declareQueue(channel *amqp.Channel) (amqp.Queue, error) {
+	if c.queue == "" {
+		return channel.QueueDeclare(
+			"",    // name
+			false, // durable
+			true,  // auto-deleted
+			true,  // exclusive
+			false, // no-wait
+			nil,   // arguments
+		)
+	}
+
+	deadLetterExchange, err := declareDeadLetter(channel, c.queue)
+
+	if err != nil {
+		return amqp.Queue{}, err
+	}
+
+	arguments := amqp.Table{"x-dead-letter-exchange": deadLetterExchange}
+
+	return channel.QueueDeclare(
+		c.queue,   // name
+		true,      // durable
+		false,     // auto-deleted
+		false,     // exclusive
+		false,     // no-wait
+		arguments, // arguments
+	)
+}
+
+// declareRetry declares the queue that failed messages of queue wait in.
+// Once they expire there they are dead-lettered back to queue.
+func declareRetry(channel *amqp.Channel, queue string) (string, error) {
+	arguments := amqp.Table{
+		"x-message-ttl":             int32(redeliveryDelay / time.Millisecond),
+		"x-dead-letter-exchange":    "",
+		"x-dead-letter-routing-key": queue,
+	}
+
+	retry, err := channel.QueueDeclare(
+		queue+"-retry", // name
+		true,           // durable
+		false,          // auto-deleted
+		false,          // exclusive
+		false,          // no-wait
+		arguments,      // arguments
+	)
+
+	return retry.Name, err
+}
+
+// declareDeadLetter declares the exchange that the messages rejected from
+// queue are sent to, and a queue that keeps them for inspection.
+func declareDeadLetter(channel *amqp.Channel, queue string) (string, error) {
+	exchange := queue + "-dead-letter-exchange"
+
+	err := channel.ExchangeDeclare(
+		exchange, // name
+		"fanout", // type
+		true,     // durable
+		false,    // auto-deleted
+		false,    // internal
+		false,    // no-wait
+		nil,      // arguments
+	)
+
+	if err != nil {
+		return "", err
+	}
+
+	deadLetters, err := channel.QueueDeclare(
+		queue+"-dead-letter", // name
+		true,                 // durable
+		false,                // auto-deleted
+		false,                // exclusive
+		false,                // no-wait
+		nil,                  // arguments
+	)
+
+	if err != nil {
+		return "", err
+	}
+
+	return exchange, channel.QueueBind(
+		deadLetters.Name, // queue
+		"",               // routing key
+		exchange,         // exchange
+		false,            // no-wait
+		nil,              // arguments
+	)
+}
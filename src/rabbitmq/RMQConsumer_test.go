@@ -0,0 +1,112 @@
+package rabbitmq
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/streadway/amqp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type RMQConsumerUnitTestSuite struct {
+	suite.Suite
+}
+
+func TestRMQConsumerUnitTestSuite(t *testing.T) {
+	suite.Run(t, new(RMQConsumerUnitTestSuite))
+}
+
+type recordingAcknowledger struct {
+	acked    bool
+	nacked   bool
+	requeued bool
+}
+
+func (a *recordingAcknowledger) Ack(tag uint64, multiple bool) error {
+	a.acked = true
+
+	return nil
+}
+
+func (a *recordingAcknowledger) Nack(tag uint64, multiple bool, requeue bool) error {
+	a.nacked = true
+	a.requeued = requeue
+
+	return nil
+}
+
+func (a *recordingAcknowledger) Reject(tag uint64, requeue bool) error {
+	return a.Nack(tag, false, requeue)
+}
+
+type recordingPublisher struct {
+	keys []string
+	err  error
+}
+
+func (p *recordingPublisher) Publish(exchange string, key string, mandatory bool, immediate bool, msg amqp.Publishing) error {
+	p.keys = append(p.keys, key)
+
+	return p.err
+}
+
+func failingConsumer(err error) consumer {
+	return consumer{
+		operationName: "test",
+		retryQueue:    "queue-retry",
+		handle:        func([]byte, context.Context) error { return err },
+	}
+}
+
+func deathHeaders(count int64) amqp.Table {
+	return amqp.Table{"x-death": []interface{}{
+		amqp.Table{"queue": "queue", "count": int64(1)},
+		amqp.Table{"queue": "queue-retry", "count": count},
+	}}
+}
+
+func (suite *RMQConsumerUnitTestSuite) TestRMQConsumer_Deliver_FailureIsMovedToRetryQueue() {
+	acknowledger := &recordingAcknowledger{}
+	channel := &recordingPublisher{}
+
+	failingConsumer(errors.New("outage")).deliver(channel, amqp.Delivery{Acknowledger: acknowledger, Headers: deathHeaders(2)})
+
+	assert.Equal(suite.T(), []string{"queue-retry"}, channel.keys, "Failed message is published to the retry queue")
+	assert.True(suite.T(), acknowledger.acked, "Failed message is acknowledged once it waits in the retry queue")
+}
+
+func (suite *RMQConsumerUnitTestSuite) TestRMQConsumer_Deliver_FailureAfterMaxRedeliveriesIsDeadLettered() {
+	acknowledger := &recordingAcknowledger{}
+	channel := &recordingPublisher{}
+
+	failingConsumer(errors.New("outage")).deliver(channel, amqp.Delivery{Acknowledger: acknowledger, Headers: deathHeaders(maxRedeliveries)})
+
+	assert.Empty(suite.T(), channel.keys, "Message is not retried again")
+	assert.True(suite.T(), acknowledger.nacked, "Message is rejected")
+	assert.False(suite.T(), acknowledger.requeued, "Message is dead-lettered")
+}
+
+func (suite *RMQConsumerUnitTestSuite) TestRMQConsumer_Deliver_UnprocessableIsDeadLettered() {
+	acknowledger := &recordingAcknowledger{}
+	channel := &recordingPublisher{}
+
+	failingConsumer(ErrUnprocessable).deliver(channel, amqp.Delivery{Acknowledger: acknowledger})
+
+	assert.Empty(suite.T(), channel.keys, "Unprocessable message is not retried")
+	assert.False(suite.T(), acknowledger.requeued, "Unprocessable message is dead-lettered")
+}
+
+func (suite *RMQConsumerUnitTestSuite) TestRMQConsumer_Deliver_FailedRetryPublishRequeues() {
+	acknowledger := &recordingAcknowledger{}
+	channel := &recordingPublisher{err: errors.New("channel closed")}
+
+	failingConsumer(errors.New("outage")).deliver(channel, amqp.Delivery{Acknowledger: acknowledger})
+
+	assert.True(suite.T(), acknowledger.requeued, "Message is requeued when the retry queue cannot be reached")
+}
+
+func (suite *RMQConsumerUnitTestSuite) TestRMQConsumer_Redeliveries_WithoutHeaderIsZero() {
+	assert.Equal(suite.T(), int64(0), redeliveries(amqp.Table{}, "queue-retry"), "First delivery has no redeliveries")
+}
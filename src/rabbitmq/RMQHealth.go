@@ -0,0 +1,36 @@
+package rabbitmq
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+
+	"github.com/streadway/amqp"
+)
+
+// NewHealthCheck reports the broker as down once the connection or the
+// channel has been closed, by the broker or by a network failure.
+func NewHealthCheck(connection *amqp.Connection, channel *amqp.Channel) func(context.Context) error {
+	var closed int32
+
+	notifications := channel.NotifyClose(make(chan *amqp.Error, 1))
+
+	go func() {
+		for range notifications {
+		}
+
+		atomic.StoreInt32(&closed, 1)
+	}()
+
+	return func(context.Context) error {
+		if connection.IsClosed() {
+			return errors.New("connection is closed")
+		}
+
+		if atomic.LoadInt32(&closed) == 1 {
+			return errors.New("channel is closed")
+		}
+
+		return nil
+	}
+}
@@ -17,22 +17,32 @@ type RMQProducer struct {
 }
 
 func (r RMQProducer) StartRabbitMQ() (*amqp.Channel, error) {
+	_, channel, err := r.Connect()
+
+	return channel, err
+}
+
+// Connect opens a connection and a channel on it. The connection is returned
+// so that it can be closed on shutdown.
+func (r RMQProducer) Connect() (*amqp.Connection, *amqp.Channel, error) {
 	connectRabbitMQ, err := amqp.Dial(r.ConnectionString)
 
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	channelRabbitMQ, err := connectRabbitMQ.Channel()
 
 	if err != nil {
-		return nil, err
+		connectRabbitMQ.Close()
+
+		return nil, nil, err
 	}
 
-	return channelRabbitMQ, err
+	return connectRabbitMQ, channelRabbitMQ, nil
 }
 
-func DeleteImage(id uint, channel *amqp.Channel, ctx context.Context) {
+func DeleteImage(id uint, channel *amqp.Channel, ctx context.Context) error {
 	span, _ := opentracing.StartSpanFromContext(ctx, "Third service (rabbitmq) - Send request to media-ms for deleting media")
 
 	defer span.Finish()
@@ -46,7 +56,7 @@ func DeleteImage(id uint, channel *amqp.Channel, ctx context.Context) {
 
 	payload, _ := json.Marshal(media)
 
-	channel.Publish(
+	return channel.Publish(
 		"DeleteImageOnMedias-MS-exchange",    // exchange
 		"DeleteImageOnMedias-MS-routing-key", // routing key
 		false,                                // mandatory
@@ -56,11 +66,12 @@ func DeleteImage(id uint, channel *amqp.Channel, ctx context.Context) {
 			DeliveryMode: amqp.Persistent,
 			MessageId:    uuid.String(),
 			Timestamp:    time.Now(),
+			Headers:      injectSpanContext(span),
 			Body:         payload,
 		})
 }
 
-func AddNotification(notification *request.NotificationDTO, channel *amqp.Channel, ctx context.Context) {
+func AddNotification(notification *request.NotificationDTO, channel *amqp.Channel, ctx context.Context) error {
 	span, _ := opentracing.StartSpanFromContext(ctx, "Third service (rabbitmq) - Send request to user-ms for notifying user")
 
 	defer span.Finish()
@@ -69,7 +80,7 @@ func AddNotification(notification *request.NotificationDTO, channel *amqp.Channe
 
 	payload, _ := json.Marshal(notification)
 
-	channel.Publish(
+	return channel.Publish(
 		"AddNotification-MS-exchange",    // exchange
 		"AddNotification-MS-routing-key", // routing key
 		false,                            // mandatory
@@ -79,6 +90,7 @@ func AddNotification(notification *request.NotificationDTO, channel *amqp.Channe
 			DeliveryMode: amqp.Persistent,
 			MessageId:    uuid.String(),
 			Timestamp:    time.Now(),
+			Headers:      injectSpanContext(span),
 			Body:         payload,
 		})
 }
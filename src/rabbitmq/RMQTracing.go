@@ -0,0 +1,53 @@
+package rabbitmq
+
+import (
+	"net/http"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/streadway/amqp"
+)
+
+// Span context is carried in the HTTP headers format because it is the only
+// format supported by every tracer this service can be configured with.
+func injectSpanContext(span opentracing.Span) amqp.Table {
+	ext.SpanKindProducer.Set(span)
+	ext.Component.Set(span, "rabbitmq")
+
+	header := http.Header{}
+
+	span.Tracer().Inject(span.Context(), opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(header))
+
+	headers := amqp.Table{}
+
+	for key := range header {
+		headers[key] = header.Get(key)
+	}
+
+	return headers
+}
+
+func startConsumerSpan(operationName string, headers amqp.Table) opentracing.Span {
+	tracer := opentracing.GlobalTracer()
+
+	header := http.Header{}
+
+	for key, value := range headers {
+		if text, ok := value.(string); ok {
+			header.Set(key, text)
+		}
+	}
+
+	var options []opentracing.StartSpanOption
+
+	if spanContext, err := tracer.Extract(opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(header)); err == nil {
+		options = append(options, opentracing.FollowsFrom(spanContext))
+	}
+
+	span := tracer.StartSpan(operationName, options...)
+
+	ext.SpanKindConsumer.Set(span)
+	ext.Component.Set(span, "rabbitmq")
+
+	return span
+}
@@ -0,0 +1,59 @@
+package rabbitmq
+
+import (
+	"testing"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/mocktracer"
+	"github.com/streadway/amqp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type RMQTracingUnitTestSuite struct {
+	suite.Suite
+	tracer *mocktracer.MockTracer
+}
+
+func TestRMQTracingUnitTestSuite(t *testing.T) {
+	suite.Run(t, new(RMQTracingUnitTestSuite))
+}
+
+func (suite *RMQTracingUnitTestSuite) SetupTest() {
+	suite.tracer = mocktracer.New()
+
+	opentracing.SetGlobalTracer(suite.tracer)
+}
+
+func (suite *RMQTracingUnitTestSuite) TearDownTest() {
+	opentracing.SetGlobalTracer(opentracing.NoopTracer{})
+}
+
+func (suite *RMQTracingUnitTestSuite) TestRMQTracing_InjectSpanContext_ConsumerContinuesTrace() {
+	producerSpan := suite.tracer.StartSpan("producer")
+
+	headers := injectSpanContext(producerSpan)
+
+	producerSpan.Finish()
+
+	consumerSpan := startConsumerSpan("consumer", headers)
+
+	consumerSpan.Finish()
+
+	producer := producerSpan.(*mocktracer.MockSpan)
+	consumer := consumerSpan.(*mocktracer.MockSpan)
+
+	assert.NotEmpty(suite.T(), headers, "Headers are empty")
+	assert.Equal(suite.T(), producer.SpanContext.TraceID, consumer.SpanContext.TraceID, "Trace id is not propagated")
+	assert.Equal(suite.T(), producer.SpanContext.SpanID, consumer.ParentID, "Consumer span is not child of producer span")
+}
+
+func (suite *RMQTracingUnitTestSuite) TestRMQTracing_StartConsumerSpan_WithoutHeadersStartsNewTrace() {
+	consumerSpan := startConsumerSpan("consumer", amqp.Table{"x-retry": int32(1)})
+
+	consumerSpan.Finish()
+
+	consumer := consumerSpan.(*mocktracer.MockSpan)
+
+	assert.Equal(suite.T(), 0, consumer.ParentID, "Consumer span has parent")
+}
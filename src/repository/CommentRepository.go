@@ -38,7 +38,7 @@ func (r CommentRepository) Create(comment entity.Comment, ctx context.Context) (
 
 	error := r.Database.Save(&comment).Error
 
-	return comment, error
+	return comment, translateError(error)
 }
 
 func (r CommentRepository) Delete(id uint, ctx context.Context) error {
@@ -46,9 +46,9 @@ func (r CommentRepository) Delete(id uint, ctx context.Context) error {
 
 	defer span.Finish()
 
-	r.Database.Unscoped().Delete(&entity.Comment{}, id)
+	result := r.Database.Unscoped().Delete(&entity.Comment{}, id)
 
-	return nil
+	return deletedOne(result)
 }
 
 func (r CommentRepository) DeleteByPostId(id uint, ctx context.Context) error {
@@ -56,7 +56,5 @@ func (r CommentRepository) DeleteByPostId(id uint, ctx context.Context) error {
 
 	defer span.Finish()
 
-	r.Database.Unscoped().Where("post_id = ?", id).Delete(&entity.Comment{})
-
-	return nil
+	return r.Database.Unscoped().Where("post_id = ?", id).Delete(&entity.Comment{}).Error
 }
@@ -24,7 +24,7 @@ func (c CommentRepositoryMock) Delete(id uint, ctx context.Context) error {
 	case 1:
 		return nil
 	case 2:
-		return errors.New("")
+		return gorm.ErrRecordNotFound
 	}
 
 	return nil
@@ -63,7 +63,25 @@ func (c CommentRepositoryMock) GetAllByPostId(id uint, ctx context.Context) []*e
 				UserId:  5,
 				PostId:  2,
 			}}
-
+	case 3:
+		return []*entity.Comment{
+			{
+				Model: gorm.Model{
+					ID: 1,
+				},
+				Content: "Some text",
+				UserId:  2,
+				PostId:  3,
+			},
+			{
+				Model: gorm.Model{
+					ID: 2,
+				},
+				Content:          "Some text 2",
+				UserId:           5,
+				PostId:           3,
+				ModerationStatus: entity.ModerationHeld,
+			}}
 	}
 
 	return nil
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"posts-ms/src/entity"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IIdempotencyKeyRepository interface {
+	CreateIfNotExists(entity.IdempotencyKey, context.Context) (bool, error)
+	GetByKey(string, context.Context) (*entity.IdempotencyKey, error)
+	TakeOver(string, string, time.Time, time.Time, context.Context) (bool, error)
+	Update(entity.IdempotencyKey, context.Context) error
+	Delete(string, context.Context) error
+	DeleteExpired(time.Time, context.Context) error
+}
+
+type IdempotencyKeyRepository struct {
+	Database *gorm.DB
+}
+
+func (r IdempotencyKeyRepository) CreateIfNotExists(key entity.IdempotencyKey, ctx context.Context) (bool, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "Repository - Create idempotency key")
+
+	defer span.Finish()
+
+	result := r.Database.Clauses(clause.OnConflict{DoNothing: true}).Create(&key)
+
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r IdempotencyKeyRepository) GetByKey(key string, ctx context.Context) (*entity.IdempotencyKey, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "Repository - Get idempotency key")
+
+	defer span.Finish()
+
+	var idempotencyKey = entity.IdempotencyKey{}
+
+	error := r.Database.First(&idempotencyKey, "key = ?", key).Error
+
+	return &idempotencyKey, error
+}
+
+// TakeOver locks an in-progress key with requestHash again until lockedUntil,
+// when its lock expired before now. It returns false when another request
+// holds or took over the key.
+func (r IdempotencyKeyRepository) TakeOver(key string, requestHash string, now time.Time, lockedUntil time.Time, ctx context.Context) (bool, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "Repository - Take over idempotency key")
+
+	defer span.Finish()
+
+	result := r.Database.Model(&entity.IdempotencyKey{}).
+		Where("key = ? AND request_hash = ? AND NOT completed AND locked_until < ?", key, requestHash, now).
+		Update("locked_until", lockedUntil)
+
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r IdempotencyKeyRepository) Update(key entity.IdempotencyKey, ctx context.Context) error {
+	span, _ := opentracing.StartSpanFromContext(ctx, "Repository - Update idempotency key")
+
+	defer span.Finish()
+
+	return r.Database.Save(&key).Error
+}
+
+func (r IdempotencyKeyRepository) Delete(key string, ctx context.Context) error {
+	span, _ := opentracing.StartSpanFromContext(ctx, "Repository - Delete idempotency key")
+
+	defer span.Finish()
+
+	return r.Database.Delete(&entity.IdempotencyKey{}, "key = ?", key).Error
+}
+
+func (r IdempotencyKeyRepository) DeleteExpired(now time.Time, ctx context.Context) error {
+	span, _ := opentracing.StartSpanFromContext(ctx, "Repository - Delete expired idempotency keys")
+
+	defer span.Finish()
+
+	return r.Database.Delete(&entity.IdempotencyKey{}, "expires_at < ?", now).Error
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"posts-ms/src/entity"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type IdempotencyKeyRepositoryMock struct {
+	mock.Mock
+}
+
+func (i IdempotencyKeyRepositoryMock) CreateIfNotExists(key entity.IdempotencyKey, ctx context.Context) (bool, error) {
+	switch key.Key {
+	case "new":
+		return true, nil
+	case "broken":
+		return false, errors.New("")
+	}
+
+	return false, nil
+}
+
+func (i IdempotencyKeyRepositoryMock) GetByKey(key string, ctx context.Context) (*entity.IdempotencyKey, error) {
+	switch key {
+	case "completed":
+		return &entity.IdempotencyKey{
+			Key:         key,
+			RequestHash: "hash",
+			Completed:   true,
+			StatusCode:  201,
+			ContentType: "application/json",
+			Body:        []byte(`{"id":1}`),
+			ExpiresAt:   time.Now().Add(time.Hour),
+		}, nil
+	case "pending":
+		return &entity.IdempotencyKey{
+			Key:         key,
+			RequestHash: "hash",
+			ExpiresAt:   time.Now().Add(time.Hour),
+			LockedUntil: time.Now().Add(time.Minute),
+		}, nil
+	case "stale", "stale-taken":
+		return &entity.IdempotencyKey{
+			Key:         key,
+			RequestHash: "hash",
+			ExpiresAt:   time.Now().Add(time.Hour),
+			LockedUntil: time.Now().Add(-time.Minute),
+		}, nil
+	}
+
+	return nil, errors.New("")
+}
+
+func (i IdempotencyKeyRepositoryMock) TakeOver(key string, requestHash string, now time.Time, lockedUntil time.Time, ctx context.Context) (bool, error) {
+	return key == "stale", nil
+}
+
+func (i IdempotencyKeyRepositoryMock) Update(entity.IdempotencyKey, context.Context) error {
+	return nil
+}
+
+func (i IdempotencyKeyRepositoryMock) Delete(string, context.Context) error {
+	return nil
+}
+
+func (i IdempotencyKeyRepositoryMock) DeleteExpired(time.Time, context.Context) error {
+	return nil
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"posts-ms/src/entity"
+
+	"github.com/opentracing/opentracing-go"
+	"gorm.io/gorm"
+)
+
+type InMemoryCommentRepository struct {
+	Store *InMemoryStore
+}
+
+func (r InMemoryCommentRepository) GetAllByPostId(id uint, ctx context.Context) []*entity.Comment {
+	span, _ := opentracing.StartSpanFromContext(ctx, "Repository - Get all comments for specific post")
+
+	defer span.Finish()
+
+	r.Store.mutex.RLock()
+	defer r.Store.mutex.RUnlock()
+
+	var comments = []*entity.Comment{}
+
+	for _, value := range r.Store.commentsOf(id) {
+		comment := value
+
+		comments = append(comments, &comment)
+	}
+
+	return comments
+}
+
+func (r InMemoryCommentRepository) Create(comment entity.Comment, ctx context.Context) (entity.Comment, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "Repository - Create new comment for specific post")
+
+	defer span.Finish()
+
+	r.Store.mutex.Lock()
+	defer r.Store.mutex.Unlock()
+
+	if _, ok := r.Store.posts[comment.PostId]; !ok {
+		return comment, ErrPostDoesNotExist
+	}
+
+	comment.ID = r.Store.assignId("comments", comment.ID)
+
+	r.Store.stamp(&comment.Model)
+
+	stored := comment
+	stored.Post = entity.Post{}
+
+	r.Store.comments[comment.ID] = stored
+
+	return comment, nil
+}
+
+func (r InMemoryCommentRepository) Delete(id uint, ctx context.Context) error {
+	span, _ := opentracing.StartSpanFromContext(ctx, "Repository - Delete comment by id")
+
+	defer span.Finish()
+
+	r.Store.mutex.Lock()
+	defer r.Store.mutex.Unlock()
+
+	if _, ok := r.Store.comments[id]; !ok {
+		return gorm.ErrRecordNotFound
+	}
+
+	delete(r.Store.comments, id)
+
+	return nil
+}
+
+func (r InMemoryCommentRepository) DeleteByPostId(id uint, ctx context.Context) error {
+	span, _ := opentracing.StartSpanFromContext(ctx, "Repository - Delete comment by post id")
+
+	defer span.Finish()
+
+	r.Store.mutex.Lock()
+	defer r.Store.mutex.Unlock()
+
+	for _, comment := range r.Store.commentsOf(id) {
+		delete(r.Store.comments, comment.ID)
+	}
+
+	return nil
+}
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"posts-ms/src/entity"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"gorm.io/gorm"
+)
+
+type InMemoryIdempotencyKeyRepository struct {
+	Store *InMemoryStore
+}
+
+func (r InMemoryIdempotencyKeyRepository) CreateIfNotExists(key entity.IdempotencyKey, ctx context.Context) (bool, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "Repository - Create idempotency key")
+
+	defer span.Finish()
+
+	r.Store.mutex.Lock()
+	defer r.Store.mutex.Unlock()
+
+	if _, ok := r.Store.idempotencyKeys[key.Key]; ok {
+		return false, nil
+	}
+
+	if key.CreatedAt.IsZero() {
+		key.CreatedAt = r.Store.now()
+	}
+
+	r.Store.idempotencyKeys[key.Key] = key
+
+	return true, nil
+}
+
+func (r InMemoryIdempotencyKeyRepository) GetByKey(key string, ctx context.Context) (*entity.IdempotencyKey, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "Repository - Get idempotency key")
+
+	defer span.Finish()
+
+	r.Store.mutex.RLock()
+	defer r.Store.mutex.RUnlock()
+
+	idempotencyKey, ok := r.Store.idempotencyKeys[key]
+
+	if !ok {
+		return &entity.IdempotencyKey{}, gorm.ErrRecordNotFound
+	}
+
+	return &idempotencyKey, nil
+}
+
+func (r InMemoryIdempotencyKeyRepository) TakeOver(key string, requestHash string, now time.Time, lockedUntil time.Time, ctx context.Context) (bool, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "Repository - Take over idempotency key")
+
+	defer span.Finish()
+
+	r.Store.mutex.Lock()
+	defer r.Store.mutex.Unlock()
+
+	idempotencyKey, ok := r.Store.idempotencyKeys[key]
+
+	if !ok || idempotencyKey.RequestHash != requestHash || idempotencyKey.Completed || !idempotencyKey.LockedUntil.Before(now) {
+		return false, nil
+	}
+
+	idempotencyKey.LockedUntil = lockedUntil
+	r.Store.idempotencyKeys[key] = idempotencyKey
+
+	return true, nil
+}
+
+func (r InMemoryIdempotencyKeyRepository) Update(key entity.IdempotencyKey, ctx context.Context) error {
+	span, _ := opentracing.StartSpanFromContext(ctx, "Repository - Update idempotency key")
+
+	defer span.Finish()
+
+	r.Store.mutex.Lock()
+	defer r.Store.mutex.Unlock()
+
+	r.Store.idempotencyKeys[key.Key] = key
+
+	return nil
+}
+
+func (r InMemoryIdempotencyKeyRepository) Delete(key string, ctx context.Context) error {
+	span, _ := opentracing.StartSpanFromContext(ctx, "Repository - Delete idempotency key")
+
+	defer span.Finish()
+
+	r.Store.mutex.Lock()
+	defer r.Store.mutex.Unlock()
+
+	delete(r.Store.idempotencyKeys, key)
+
+	return nil
+}
+
+func (r InMemoryIdempotencyKeyRepository) DeleteExpired(now time.Time, ctx context.Context) error {
+	span, _ := opentracing.StartSpanFromContext(ctx, "Repository - Delete expired idempotency keys")
+
+	defer span.Finish()
+
+	r.Store.mutex.Lock()
+	defer r.Store.mutex.Unlock()
+
+	for key, idempotencyKey := range r.Store.idempotencyKeys {
+		if idempotencyKey.ExpiresAt.Before(now) {
+			delete(r.Store.idempotencyKeys, key)
+		}
+	}
+
+	return nil
+}
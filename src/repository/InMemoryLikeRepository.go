@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"posts-ms/src/entity"
+
+	"github.com/opentracing/opentracing-go"
+	"gorm.io/gorm"
+)
+
+type InMemoryLikeRepository struct {
+	Store *InMemoryStore
+}
+
+func (r InMemoryLikeRepository) GetAllByPostId(id uint, ctx context.Context) []*entity.Like {
+	span, _ := opentracing.StartSpanFromContext(ctx, "Repository - Get all likes for specific post")
+
+	defer span.Finish()
+
+	r.Store.mutex.RLock()
+	defer r.Store.mutex.RUnlock()
+
+	var likes = []*entity.Like{}
+
+	for _, value := range r.Store.likesOf(id) {
+		like := value
+
+		likes = append(likes, &like)
+	}
+
+	return likes
+}
+
+func (r InMemoryLikeRepository) Create(like entity.Like, ctx context.Context) (entity.Like, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "Repository - Create new like")
+
+	defer span.Finish()
+
+	r.Store.mutex.Lock()
+	defer r.Store.mutex.Unlock()
+
+	if _, ok := r.Store.posts[like.PostId]; !ok {
+		return like, ErrPostDoesNotExist
+	}
+
+	for _, existing := range r.Store.likes {
+		if existing.ID != like.ID && existing.UserId == like.UserId && existing.PostId == like.PostId {
+			return like, ErrDuplicateLike
+		}
+	}
+
+	like.ID = r.Store.assignId("likes", like.ID)
+
+	r.Store.stamp(&like.Model)
+
+	stored := like
+	stored.Post = entity.Post{}
+
+	r.Store.likes[like.ID] = stored
+
+	return like, nil
+}
+
+func (r InMemoryLikeRepository) GetByUserIdAndPostId(userId uint, postId uint, ctx context.Context) (entity.Like, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "Repository - Get like likes for specific post from specific user")
+
+	defer span.Finish()
+
+	r.Store.mutex.RLock()
+	defer r.Store.mutex.RUnlock()
+
+	for _, like := range r.Store.likes {
+		if like.UserId == userId && like.PostId == postId {
+			return like, nil
+		}
+	}
+
+	return entity.Like{}, gorm.ErrRecordNotFound
+}
+
+func (r InMemoryLikeRepository) Delete(id uint, ctx context.Context) error {
+	span, _ := opentracing.StartSpanFromContext(ctx, "Repository -Delete by id")
+
+	defer span.Finish()
+
+	r.Store.mutex.Lock()
+	defer r.Store.mutex.Unlock()
+
+	if _, ok := r.Store.likes[id]; !ok {
+		return gorm.ErrRecordNotFound
+	}
+
+	delete(r.Store.likes, id)
+
+	return nil
+}
+
+func (r InMemoryLikeRepository) DeleteByPostId(id uint, ctx context.Context) error {
+	span, _ := opentracing.StartSpanFromContext(ctx, "Repository - Delete by post id")
+
+	defer span.Finish()
+
+	r.Store.mutex.Lock()
+	defer r.Store.mutex.Unlock()
+
+	for _, like := range r.Store.likesOf(id) {
+		delete(r.Store.likes, like.ID)
+	}
+
+	return nil
+}
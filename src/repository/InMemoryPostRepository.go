@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"posts-ms/src/entity"
+	"sort"
+
+	"github.com/opentracing/opentracing-go"
+	"gorm.io/gorm"
+)
+
+type InMemoryPostRepository struct {
+	Store *InMemoryStore
+}
+
+func (r InMemoryPostRepository) GetById(id uint, ctx context.Context) (*entity.Post, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "Repository - Get post by id")
+
+	defer span.Finish()
+
+	r.Store.mutex.RLock()
+	defer r.Store.mutex.RUnlock()
+
+	post, ok := r.Store.posts[id]
+
+	if !ok {
+		return &entity.Post{}, gorm.ErrRecordNotFound
+	}
+
+	post.Likes = r.Store.likesOf(id)
+
+	return &post, nil
+}
+
+func (r InMemoryPostRepository) GetAllByUserId(id uint, ctx context.Context) []*entity.Post {
+	span, _ := opentracing.StartSpanFromContext(ctx, "Repository - Get all posts by user id")
+
+	defer span.Finish()
+
+	return r.getAllByUserIds([]uint{id})
+}
+
+func (r InMemoryPostRepository) GetAllByUserIds(ids []uint, ctx context.Context) []*entity.Post {
+	span, _ := opentracing.StartSpanFromContext(ctx, "Repository - Get all posts by user ids")
+
+	defer span.Finish()
+
+	return r.getAllByUserIds(ids)
+}
+
+func (r InMemoryPostRepository) getAllByUserIds(ids []uint) []*entity.Post {
+	r.Store.mutex.RLock()
+	defer r.Store.mutex.RUnlock()
+
+	users := map[uint]bool{}
+
+	for _, id := range ids {
+		users[id] = true
+	}
+
+	var posts = []*entity.Post{}
+
+	for _, value := range r.Store.posts {
+		if !users[value.UserId] {
+			continue
+		}
+
+		post := value
+		post.Likes = r.Store.likesOf(post.ID)
+		post.Comments = r.Store.commentsOf(post.ID)
+
+		posts = append(posts, &post)
+	}
+
+	sort.SliceStable(posts, func(i, j int) bool {
+		if !posts[i].CreatedAt.Equal(posts[j].CreatedAt) {
+			return posts[i].CreatedAt.After(posts[j].CreatedAt)
+		}
+
+		return posts[i].ID > posts[j].ID
+	})
+
+	return posts
+}
+
+func (r InMemoryPostRepository) Create(post entity.Post, ctx context.Context) (entity.Post, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "Repository - Create post")
+
+	defer span.Finish()
+
+	r.Store.mutex.Lock()
+	defer r.Store.mutex.Unlock()
+
+	post.ID = r.Store.assignId("posts", post.ID)
+
+	r.Store.stamp(&post.Model)
+
+	stored := post
+	stored.Likes = nil
+	stored.Comments = nil
+
+	r.Store.posts[post.ID] = stored
+
+	return post, nil
+}
+
+func (r InMemoryPostRepository) Delete(id uint, ctx context.Context) error {
+	span, _ := opentracing.StartSpanFromContext(ctx, "Repository - Delete post by id")
+
+	defer span.Finish()
+
+	r.Store.mutex.Lock()
+	defer r.Store.mutex.Unlock()
+
+	if _, ok := r.Store.posts[id]; !ok {
+		return gorm.ErrRecordNotFound
+	}
+
+	for _, like := range r.Store.likesOf(id) {
+		delete(r.Store.likes, like.ID)
+	}
+
+	for _, comment := range r.Store.commentsOf(id) {
+		delete(r.Store.comments, comment.ID)
+	}
+
+	delete(r.Store.posts, id)
+
+	return nil
+}
@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type InMemoryRepositoryUnitTestSuite struct {
+	RepositoryConformanceSuite
+}
+
+func TestInMemoryRepositoryUnitTestSuite(t *testing.T) {
+	suite.Run(t, new(InMemoryRepositoryUnitTestSuite))
+}
+
+func (suite *InMemoryRepositoryUnitTestSuite) SetupTest() {
+	store := NewInMemoryStore()
+
+	suite.repositories = conformanceRepositories{
+		posts:           InMemoryPostRepository{Store: store},
+		likes:           InMemoryLikeRepository{Store: store},
+		comments:        InMemoryCommentRepository{Store: store},
+		idempotencyKeys: InMemoryIdempotencyKeyRepository{Store: store},
+	}
+}
+
+func (suite *InMemoryRepositoryUnitTestSuite) TestInMemory_IdsAreCountedPerTable() {
+	post := suite.createPost(conformanceUserId, time.Now())
+	like := suite.createLike(conformanceUserId, post.ID)
+	comment := suite.createComment(conformanceUserId, post.ID)
+	second := suite.createPost(conformanceUserId, time.Now())
+
+	assert.Equal(suite.T(), uint(1), post.ID, "First post id is not 1")
+	assert.Equal(suite.T(), uint(1), like.ID, "First like id is not 1")
+	assert.Equal(suite.T(), uint(1), comment.ID, "First comment id is not 1")
+	assert.Equal(suite.T(), uint(2), second.ID, "Second post id is not 2")
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"posts-ms/src/entity"
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// InMemoryStore keeps every table in memory behind one lock, so that the
+// in-memory repositories can enforce the same constraints as the schema:
+// likes are unique per user and post, and likes and comments belong to an
+// existing post and are deleted with it.
+type InMemoryStore struct {
+	mutex           sync.RWMutex
+	posts           map[uint]entity.Post
+	likes           map[uint]entity.Like
+	comments        map[uint]entity.Comment
+	idempotencyKeys map[string]entity.IdempotencyKey
+	lastIds         map[string]uint
+	now             func() time.Time
+}
+
+func NewInMemoryStore() *InMemoryStore {
+	return &InMemoryStore{
+		posts:           map[uint]entity.Post{},
+		likes:           map[uint]entity.Like{},
+		comments:        map[uint]entity.Comment{},
+		idempotencyKeys: map[string]entity.IdempotencyKey{},
+		lastIds:         map[string]uint{},
+		now:             time.Now,
+	}
+}
+
+// assignId returns id, or the next free one of table when id is 0, like the
+// serial primary key of each table does.
+func (s *InMemoryStore) assignId(table string, id uint) uint {
+	if id == 0 {
+		s.lastIds[table]++
+
+		return s.lastIds[table]
+	}
+
+	if id > s.lastIds[table] {
+		s.lastIds[table] = id
+	}
+
+	return id
+}
+
+// stamp sets the timestamps the way gorm does on save.
+func (s *InMemoryStore) stamp(model *gorm.Model) {
+	now := s.now()
+
+	if model.CreatedAt.IsZero() {
+		model.CreatedAt = now
+	}
+
+	model.UpdatedAt = now
+}
+
+func (s *InMemoryStore) likesOf(postId uint) []entity.Like {
+	likes := []entity.Like{}
+
+	for _, like := range s.likes {
+		if like.PostId == postId {
+			likes = append(likes, like)
+		}
+	}
+
+	sort.SliceStable(likes, func(i, j int) bool {
+		return likes[i].ID < likes[j].ID
+	})
+
+	return likes
+}
+
+func (s *InMemoryStore) commentsOf(postId uint) []entity.Comment {
+	comments := []entity.Comment{}
+
+	for _, comment := range s.comments {
+		if comment.PostId == postId {
+			comments = append(comments, comment)
+		}
+	}
+
+	sort.SliceStable(comments, func(i, j int) bool {
+		return comments[i].ID < comments[j].ID
+	})
+
+	return comments
+}
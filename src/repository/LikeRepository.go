@@ -11,8 +11,8 @@ import (
 type ILikeRepository interface {
 	Create(entity.Like, context.Context) (entity.Like, error)
 	GetByUserIdAndPostId(uint, uint, context.Context) (entity.Like, error)
-	Delete(uint, context.Context)
-	DeleteByPostId(uint, context.Context)
+	Delete(uint, context.Context) error
+	DeleteByPostId(uint, context.Context) error
 	GetAllByPostId(uint, context.Context) []*entity.Like
 }
 
@@ -39,7 +39,7 @@ func (r LikeRepository) Create(like entity.Like, ctx context.Context) (entity.Li
 
 	error := r.Database.Save(&like).Error
 
-	return like, error
+	return like, translateError(error)
 }
 
 func (r LikeRepository) GetByUserIdAndPostId(userId uint, postId uint, ctx context.Context) (entity.Like, error) {
@@ -57,18 +57,20 @@ func (r LikeRepository) GetByUserIdAndPostId(userId uint, postId uint, ctx conte
 	return like, error
 }
 
-func (r LikeRepository) Delete(id uint, ctx context.Context) {
+func (r LikeRepository) Delete(id uint, ctx context.Context) error {
 	span, _ := opentracing.StartSpanFromContext(ctx, "Repository -Delete by id")
 
 	defer span.Finish()
 
-	r.Database.Unscoped().Delete(&entity.Like{}, id)
+	result := r.Database.Unscoped().Delete(&entity.Like{}, id)
+
+	return deletedOne(result)
 }
 
-func (r LikeRepository) DeleteByPostId(id uint, ctx context.Context) {
+func (r LikeRepository) DeleteByPostId(id uint, ctx context.Context) error {
 	span, _ := opentracing.StartSpanFromContext(ctx, "Repository - Delete by post id")
 
 	defer span.Finish()
 
-	r.Database.Unscoped().Where("post_id = ?", id).Delete(&entity.Like{})
+	return r.Database.Unscoped().Where("post_id = ?", id).Delete(&entity.Like{}).Error
 }
@@ -2,7 +2,6 @@ package repository
 
 import (
 	"context"
-	"errors"
 	"posts-ms/src/entity"
 
 	"github.com/stretchr/testify/mock"
@@ -14,6 +13,10 @@ type LikeRepositoryMock struct {
 }
 
 func (l LikeRepositoryMock) Create(like entity.Like, ctx context.Context) (entity.Like, error) {
+	if like.UserId == 7 {
+		return like, ErrDuplicateLike
+	}
+
 	like.ID = 1
 
 	return like, nil
@@ -21,23 +24,25 @@ func (l LikeRepositoryMock) Create(like entity.Like, ctx context.Context) (entit
 
 func (l LikeRepositoryMock) GetByUserIdAndPostId(userId uint, postId uint, ctx context.Context) (entity.Like, error) {
 	if userId == 1 && postId == 1 {
-		return entity.Like{}, errors.New("")
+		return entity.Like{}, gorm.ErrRecordNotFound
 	} else {
 		return entity.Like{
 			Model: gorm.Model{
 				ID: 1,
 			},
-			UserId:   2,
-			PostId:   1,
+			UserId:   userId,
+			PostId:   postId,
 			LikeType: 1,
 		}, nil
 	}
 }
 
-func (l LikeRepositoryMock) Delete(uint, context.Context) {
+func (l LikeRepositoryMock) Delete(uint, context.Context) error {
+	return nil
 }
 
-func (l LikeRepositoryMock) DeleteByPostId(uint, context.Context) {
+func (l LikeRepositoryMock) DeleteByPostId(uint, context.Context) error {
+	return nil
 }
 
 func (l LikeRepositoryMock) GetAllByPostId(id uint, ctx context.Context) []*entity.Like {
@@ -12,7 +12,7 @@ import (
 
 type IPostRepository interface {
 	Create(entity.Post, context.Context) (entity.Post, error)
-	Delete(uint, context.Context)
+	Delete(uint, context.Context) error
 	GetById(uint, context.Context) (*entity.Post, error)
 	GetAllByUserId(uint, context.Context) []*entity.Post
 	GetAllByUserIds([]uint, context.Context) []*entity.Post
@@ -41,7 +41,7 @@ func (r PostRepository) GetAllByUserId(id uint, ctx context.Context) []*entity.P
 
 	var posts = []*entity.Post{}
 
-	r.Database.Preload("Likes").Preload("Comments").Order("created_at desc").Find(&posts, "user_id = ?", id)
+	r.Database.Preload("Likes").Preload("Comments").Order("created_at desc, id desc").Find(&posts, "user_id = ?", id)
 
 	return posts
 }
@@ -53,7 +53,7 @@ func (r PostRepository) GetAllByUserIds(ids []uint, ctx context.Context) []*enti
 
 	var posts = []*entity.Post{}
 
-	r.Database.Preload("Likes").Preload("Comments").Order("created_at desc").Find(&posts, "user_id = any(?)", pq.Array(ids))
+	r.Database.Preload("Likes").Preload("Comments").Order("created_at desc, id desc").Find(&posts, "user_id = any(?)", pq.Array(ids))
 
 	return posts
 }
@@ -68,10 +68,12 @@ func (r PostRepository) Create(post entity.Post, ctx context.Context) (entity.Po
 	return post, error
 }
 
-func (r PostRepository) Delete(id uint, ctx context.Context) {
+func (r PostRepository) Delete(id uint, ctx context.Context) error {
 	span, _ := opentracing.StartSpanFromContext(ctx, "Repository - Delete post by id")
 
 	defer span.Finish()
 
-	r.Database.Unscoped().Select(clause.Associations).Delete(&entity.Post{}, id)
+	result := r.Database.Unscoped().Select(clause.Associations).Delete(&entity.Post{Model: gorm.Model{ID: id}})
+
+	return deletedOne(result)
 }
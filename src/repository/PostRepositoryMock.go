@@ -14,18 +14,26 @@ type PostRepositoryMock struct {
 }
 
 func (p PostRepositoryMock) Create(post entity.Post, ctx context.Context) (entity.Post, error) {
+	if post.UserId == 3 {
+		return post, errors.New("")
+	}
+
 	post.ID = 1
 
 	return post, nil
 }
 
-func (p PostRepositoryMock) Delete(uint, context.Context) {
+func (p PostRepositoryMock) Delete(id uint, ctx context.Context) error {
+	if id == 1 {
+		return gorm.ErrRecordNotFound
+	}
 
+	return nil
 }
 
 func (p PostRepositoryMock) GetById(id uint, ctx context.Context) (*entity.Post, error) {
 	if id == 1 {
-		return nil, errors.New("")
+		return nil, gorm.ErrRecordNotFound
 	} else {
 		return &entity.Post{
 			Model: gorm.Model{
@@ -43,6 +51,28 @@ func (p PostRepositoryMock) GetById(id uint, ctx context.Context) (*entity.Post,
 func (p PostRepositoryMock) GetAllByUserId(id uint, ctx context.Context) []*entity.Post {
 	if id == 1 {
 		return []*entity.Post{}
+	} else if id == 4 {
+		return []*entity.Post{
+			{
+				Model: gorm.Model{
+					ID: 3,
+				},
+				UserId:      4,
+				Description: "Some text",
+				Comments: []entity.Comment{
+					{Model: gorm.Model{ID: 1}, Content: "Some text", UserId: 5, PostId: 3, ModerationStatus: entity.ModerationHeld},
+					{Model: gorm.Model{ID: 2}, Content: "Some text 2", UserId: 6, PostId: 3},
+				},
+			},
+			{
+				Model: gorm.Model{
+					ID: 4,
+				},
+				UserId:           4,
+				Description:      "Some text",
+				ModerationStatus: entity.ModerationHeld,
+			},
+		}
 	} else {
 		return []*entity.Post{
 			{
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"posts-ms/src/migration"
+	"posts-ms/src/utils"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type PostgresRepositoryIntegrationTestSuite struct {
+	RepositoryConformanceSuite
+	db *gorm.DB
+}
+
+func TestPostgresRepositoryIntegrationTestSuite(t *testing.T) {
+	suite.Run(t, new(PostgresRepositoryIntegrationTestSuite))
+}
+
+func (suite *PostgresRepositoryIntegrationTestSuite) SetupSuite() {
+	connectionString := fmt.Sprintf(
+		"host=%s user=%s password=%s dbname=%s port=%s sslmode=disable",
+		os.Getenv("DATABASE_DOMAIN"),
+		os.Getenv("DATABASE_USERNAME"),
+		os.Getenv("DATABASE_PASSWORD"),
+		os.Getenv("DATABASE_SCHEMA"),
+		os.Getenv("DATABASE_PORT"),
+	)
+
+	db, _ := gorm.Open(postgres.Open(connectionString), &gorm.Config{})
+
+	migrator, _ := migration.NewMigrator(db, utils.Logger())
+
+	migrator.Up(context.Background())
+
+	suite.db = db
+	suite.repositories = conformanceRepositories{
+		posts:           PostRepository{Database: db},
+		likes:           LikeRepository{Database: db},
+		comments:        CommentRepository{Database: db},
+		idempotencyKeys: IdempotencyKeyRepository{Database: db},
+	}
+}
+
+func (suite *PostgresRepositoryIntegrationTestSuite) SetupTest() {
+	suite.db.Exec("DELETE FROM posts WHERE user_id >= ?", conformanceUserId)
+	suite.db.Exec("DELETE FROM likes WHERE user_id >= ?", conformanceUserId)
+	suite.db.Exec("DELETE FROM comments WHERE user_id >= ?", conformanceUserId)
+	suite.db.Exec("DELETE FROM idempotency_keys WHERE key LIKE ?", conformanceKeyPrefix+"%")
+}
@@ -0,0 +1,199 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"posts-ms/src/entity"
+	"posts-ms/src/migration"
+	"posts-ms/src/utils"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// QueryPlanIntegrationTestSuite explains the statements the repositories
+// actually issue and fails when an access path stops using its index.
+type QueryPlanIntegrationTestSuite struct {
+	suite.Suite
+	db *gorm.DB
+}
+
+func TestQueryPlanIntegrationTestSuite(t *testing.T) {
+	suite.Run(t, new(QueryPlanIntegrationTestSuite))
+}
+
+func (suite *QueryPlanIntegrationTestSuite) SetupSuite() {
+	connectionString := fmt.Sprintf(
+		"host=%s user=%s password=%s dbname=%s port=%s sslmode=disable",
+		os.Getenv("DATABASE_DOMAIN"),
+		os.Getenv("DATABASE_USERNAME"),
+		os.Getenv("DATABASE_PASSWORD"),
+		os.Getenv("DATABASE_SCHEMA"),
+		os.Getenv("DATABASE_PORT"),
+	)
+
+	db, _ := gorm.Open(postgres.Open(connectionString), &gorm.Config{})
+
+	migrator, _ := migration.NewMigrator(db, utils.Logger())
+
+	migrator.Up(context.Background())
+
+	suite.db = db
+}
+
+// statementRecorder collects the SQL of a dry run session.
+type statementRecorder struct {
+	logger.Interface
+	statements []string
+}
+
+func (r *statementRecorder) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	sql, _ := fc()
+
+	r.statements = append(r.statements, sql)
+}
+
+func (suite *QueryPlanIntegrationTestSuite) statementOf(query func(*gorm.DB)) string {
+	recorder := &statementRecorder{Interface: logger.Discard}
+
+	query(suite.db.Session(&gorm.Session{DryRun: true, Logger: recorder}))
+
+	assert.NotEmpty(suite.T(), recorder.statements, "No statement recorded")
+
+	return recorder.statements[0]
+}
+
+type planNode struct {
+	NodeType     string     `json:"Node Type"`
+	RelationName string     `json:"Relation Name"`
+	IndexName    string     `json:"Index Name"`
+	Plans        []planNode `json:"Plans"`
+}
+
+// explain returns the indexes used by the plan and the relations it scans
+// sequentially. Sequential scans are disabled so that the planner picks an
+// index whenever one can serve the query, even on small tables.
+func (suite *QueryPlanIntegrationTestSuite) explain(sql string) ([]string, []string) {
+	var output string
+
+	err := suite.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SET LOCAL enable_seqscan = off").Error; err != nil {
+			return err
+		}
+
+		return tx.Raw("EXPLAIN (FORMAT JSON) " + sql).Row().Scan(&output)
+	})
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+
+	var plans []struct {
+		Plan planNode `json:"Plan"`
+	}
+
+	assert.Nil(suite.T(), json.Unmarshal([]byte(output), &plans), "Plan is not valid json")
+
+	indexes := []string{}
+	seqScans := []string{}
+
+	var walk func(planNode)
+
+	walk = func(node planNode) {
+		if node.IndexName != "" {
+			indexes = append(indexes, node.IndexName)
+		}
+
+		if node.NodeType == "Seq Scan" {
+			seqScans = append(seqScans, node.RelationName)
+		}
+
+		for _, child := range node.Plans {
+			walk(child)
+		}
+	}
+
+	for _, plan := range plans {
+		walk(plan.Plan)
+	}
+
+	return indexes, seqScans
+}
+
+func (suite *QueryPlanIntegrationTestSuite) assertUsesIndex(index string, query func(*gorm.DB)) {
+	indexes, seqScans := suite.explain(suite.statementOf(query))
+
+	assert.Contains(suite.T(), indexes, index, "Index is not used")
+	assert.Empty(suite.T(), seqScans, "Query scans sequentially")
+}
+
+func (suite *QueryPlanIntegrationTestSuite) TestIntegrationQueryPlan_LikesByPostId() {
+	suite.assertUsesIndex("idx_likes_post_id", func(db *gorm.DB) {
+		LikeRepository{Database: db}.GetAllByPostId(1, context.Background())
+	})
+}
+
+func (suite *QueryPlanIntegrationTestSuite) TestIntegrationQueryPlan_LikeByUserIdAndPostId() {
+	suite.assertUsesIndex("uq_likes_user_id_post_id", func(db *gorm.DB) {
+		LikeRepository{Database: db}.GetByUserIdAndPostId(1, 1, context.Background())
+	})
+}
+
+func (suite *QueryPlanIntegrationTestSuite) TestIntegrationQueryPlan_CommentsByPostId() {
+	suite.assertUsesIndex("idx_comments_post_id", func(db *gorm.DB) {
+		CommentRepository{Database: db}.GetAllByPostId(1, context.Background())
+	})
+}
+
+func (suite *QueryPlanIntegrationTestSuite) TestIntegrationQueryPlan_PostsByUserId() {
+	suite.assertUsesIndex("idx_posts_user_id_created_at", func(db *gorm.DB) {
+		PostRepository{Database: db}.GetAllByUserId(1, context.Background())
+	})
+}
+
+func (suite *QueryPlanIntegrationTestSuite) TestIntegrationQueryPlan_PostsByUserIds() {
+	suite.assertUsesIndex("idx_posts_user_id_created_at", func(db *gorm.DB) {
+		PostRepository{Database: db}.GetAllByUserIds([]uint{1, 2}, context.Background())
+	})
+}
+
+func (suite *QueryPlanIntegrationTestSuite) TestIntegrationConstraints_LikeIsUniquePerUserAndPost() {
+	post := entity.Post{Description: "Constraints", UserId: 9001}
+
+	suite.db.Create(&post)
+
+	defer suite.db.Unscoped().Delete(&entity.Post{}, post.ID)
+
+	err := suite.db.Create(&entity.Like{UserId: 9002, PostId: post.ID, LikeType: entity.Positive}).Error
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+
+	err = suite.db.Create(&entity.Like{UserId: 9002, PostId: post.ID, LikeType: entity.TypeOfLike(2)}).Error
+
+	assert.NotNil(suite.T(), err, "Duplicate like is accepted")
+}
+
+func (suite *QueryPlanIntegrationTestSuite) TestIntegrationConstraints_DeletingPostCascades() {
+	post := entity.Post{Description: "Constraints", UserId: 9001}
+
+	suite.db.Create(&post)
+	suite.db.Create(&entity.Like{UserId: 9003, PostId: post.ID, LikeType: entity.Positive})
+	suite.db.Create(&entity.Comment{UserId: 9003, PostId: post.ID, Content: "Comment"})
+
+	err := suite.db.Exec("DELETE FROM posts WHERE id = ?", post.ID).Error
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+
+	var likes, comments int64
+
+	suite.db.Model(&entity.Like{}).Where("post_id = ?", post.ID).Count(&likes)
+	suite.db.Model(&entity.Comment{}).Where("post_id = ?", post.ID).Count(&comments)
+
+	assert.Equal(suite.T(), int64(0), likes, "Likes are not deleted")
+	assert.Equal(suite.T(), int64(0), comments, "Comments are not deleted")
+}
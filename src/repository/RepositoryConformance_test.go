@@ -0,0 +1,383 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"posts-ms/src/entity"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+// Users and keys used by the conformance tests, kept apart from the data
+// other suites create in a shared database.
+const (
+	conformanceUserId    = uint(900001)
+	conformanceKeyPrefix = "conformance-"
+)
+
+type conformanceRepositories struct {
+	posts           IPostRepository
+	likes           ILikeRepository
+	comments        ICommentRepository
+	idempotencyKeys IIdempotencyKeyRepository
+}
+
+// RepositoryConformanceSuite holds the behaviour every storage backend must
+// provide. Backends embed it and set repositories before each test.
+type RepositoryConformanceSuite struct {
+	suite.Suite
+	repositories conformanceRepositories
+}
+
+func (suite *RepositoryConformanceSuite) createPost(userId uint, createdAt time.Time) entity.Post {
+	post, err := suite.repositories.posts.Create(entity.Post{
+		Model:       gorm.Model{CreatedAt: createdAt},
+		Description: "Some text",
+		UserId:      userId,
+	}, context.TODO())
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+
+	return post
+}
+
+func (suite *RepositoryConformanceSuite) createLike(userId uint, postId uint) entity.Like {
+	like, err := suite.repositories.likes.Create(entity.Like{UserId: userId, PostId: postId, LikeType: entity.Positive}, context.TODO())
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+
+	return like
+}
+
+func (suite *RepositoryConformanceSuite) createComment(userId uint, postId uint) entity.Comment {
+	comment, err := suite.repositories.comments.Create(entity.Comment{UserId: userId, PostId: postId, Content: "Some comment"}, context.TODO())
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+
+	return comment
+}
+
+func (suite *RepositoryConformanceSuite) TestConformance_Post_CreateAndGetById() {
+	created := suite.createPost(conformanceUserId, time.Now())
+
+	assert.NotZero(suite.T(), created.ID, "Post id is not assigned")
+	assert.False(suite.T(), created.CreatedAt.IsZero(), "Created at is not set")
+
+	post, err := suite.repositories.posts.GetById(created.ID, context.TODO())
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+	assert.Equal(suite.T(), created.ID, post.ID, "Post id does not match")
+	assert.Equal(suite.T(), "Some text", post.Description, "Description does not match")
+	assert.Equal(suite.T(), conformanceUserId, post.UserId, "User id does not match")
+}
+
+func (suite *RepositoryConformanceSuite) TestConformance_Post_GetByIdMissing() {
+	_, err := suite.repositories.posts.GetById(999999999, context.TODO())
+
+	assert.True(suite.T(), errors.Is(err, gorm.ErrRecordNotFound), "Error is not record not found")
+}
+
+func (suite *RepositoryConformanceSuite) TestConformance_Post_GetByIdLoadsLikes() {
+	created := suite.createPost(conformanceUserId, time.Now())
+
+	suite.createLike(conformanceUserId+1, created.ID)
+	suite.createLike(conformanceUserId+2, created.ID)
+
+	post, err := suite.repositories.posts.GetById(created.ID, context.TODO())
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+	assert.Equal(suite.T(), 2, len(post.Likes), "Length of likes not 2")
+}
+
+func (suite *RepositoryConformanceSuite) TestConformance_Post_CreateUpdatesExisting() {
+	created := suite.createPost(conformanceUserId, time.Now())
+
+	created.Description = "Updated text"
+	created.TotalLikes = 3
+
+	_, err := suite.repositories.posts.Create(created, context.TODO())
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+
+	post, _ := suite.repositories.posts.GetById(created.ID, context.TODO())
+
+	assert.Equal(suite.T(), "Updated text", post.Description, "Description is not updated")
+	assert.Equal(suite.T(), 3, post.TotalLikes, "Total likes are not updated")
+	assert.Equal(suite.T(), 1, len(suite.repositories.posts.GetAllByUserId(conformanceUserId, context.TODO())), "Post is duplicated")
+}
+
+func (suite *RepositoryConformanceSuite) TestConformance_Post_GetAllByUserIdNewestFirst() {
+	now := time.Now()
+
+	older := suite.createPost(conformanceUserId, now.Add(-time.Hour))
+	newer := suite.createPost(conformanceUserId, now)
+
+	suite.createPost(conformanceUserId+1, now)
+	suite.createLike(conformanceUserId+2, older.ID)
+	suite.createComment(conformanceUserId+2, older.ID)
+
+	posts := suite.repositories.posts.GetAllByUserId(conformanceUserId, context.TODO())
+
+	assert.Equal(suite.T(), 2, len(posts), "Length of posts not 2")
+	assert.Equal(suite.T(), newer.ID, posts[0].ID, "Newest post is not first")
+	assert.Equal(suite.T(), older.ID, posts[1].ID, "Oldest post is not last")
+	assert.Equal(suite.T(), 1, len(posts[1].Likes), "Likes are not loaded")
+	assert.Equal(suite.T(), 1, len(posts[1].Comments), "Comments are not loaded")
+}
+
+func (suite *RepositoryConformanceSuite) TestConformance_Post_CreatedAtTheSameTimeAreOrderedById() {
+	createdAt := time.Now()
+	expected := []uint{}
+
+	for i := 0; i < 5; i++ {
+		post := suite.createPost(conformanceUserId, createdAt)
+
+		expected = append([]uint{post.ID}, expected...)
+	}
+
+	for i := 0; i < 10; i++ {
+		byUserId := []uint{}
+		byUserIds := []uint{}
+
+		for _, post := range suite.repositories.posts.GetAllByUserId(conformanceUserId, context.TODO()) {
+			byUserId = append(byUserId, post.ID)
+		}
+
+		for _, post := range suite.repositories.posts.GetAllByUserIds([]uint{conformanceUserId}, context.TODO()) {
+			byUserIds = append(byUserIds, post.ID)
+		}
+
+		assert.Equal(suite.T(), expected, byUserId, "Posts by user id are not ordered by id")
+		assert.Equal(suite.T(), expected, byUserIds, "Posts by user ids are not ordered by id")
+	}
+}
+
+func (suite *RepositoryConformanceSuite) TestConformance_Post_GetAllByUserIds() {
+	suite.createPost(conformanceUserId, time.Now())
+	suite.createPost(conformanceUserId+1, time.Now())
+	suite.createPost(conformanceUserId+2, time.Now())
+
+	posts := suite.repositories.posts.GetAllByUserIds([]uint{conformanceUserId, conformanceUserId + 1}, context.TODO())
+
+	assert.Equal(suite.T(), 2, len(posts), "Length of posts not 2")
+
+	for _, post := range posts {
+		assert.NotEqual(suite.T(), conformanceUserId+2, post.UserId, "Post of other user is returned")
+	}
+}
+
+func (suite *RepositoryConformanceSuite) TestConformance_Post_DeleteRemovesLikesAndComments() {
+	post := suite.createPost(conformanceUserId, time.Now())
+
+	suite.createLike(conformanceUserId+1, post.ID)
+	suite.createComment(conformanceUserId+1, post.ID)
+
+	err := suite.repositories.posts.Delete(post.ID, context.TODO())
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+
+	_, err = suite.repositories.posts.GetById(post.ID, context.TODO())
+
+	assert.NotNil(suite.T(), err, "Post is not deleted")
+	assert.Empty(suite.T(), suite.repositories.likes.GetAllByPostId(post.ID, context.TODO()), "Likes are not deleted")
+	assert.Empty(suite.T(), suite.repositories.comments.GetAllByPostId(post.ID, context.TODO()), "Comments are not deleted")
+}
+
+func (suite *RepositoryConformanceSuite) TestConformance_Like_CreateAndGetByUserIdAndPostId() {
+	post := suite.createPost(conformanceUserId, time.Now())
+	created := suite.createLike(conformanceUserId+1, post.ID)
+
+	assert.NotZero(suite.T(), created.ID, "Like id is not assigned")
+
+	like, err := suite.repositories.likes.GetByUserIdAndPostId(conformanceUserId+1, post.ID, context.TODO())
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+	assert.Equal(suite.T(), created.ID, like.ID, "Like id does not match")
+
+	_, err = suite.repositories.likes.GetByUserIdAndPostId(conformanceUserId+2, post.ID, context.TODO())
+
+	assert.True(suite.T(), errors.Is(err, gorm.ErrRecordNotFound), "Error is not record not found")
+}
+
+func (suite *RepositoryConformanceSuite) TestConformance_Like_CreateUpdatesExisting() {
+	post := suite.createPost(conformanceUserId, time.Now())
+	like := suite.createLike(conformanceUserId+1, post.ID)
+
+	like.LikeType = entity.TypeOfLike(2)
+
+	_, err := suite.repositories.likes.Create(like, context.TODO())
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+
+	likes := suite.repositories.likes.GetAllByPostId(post.ID, context.TODO())
+
+	assert.Equal(suite.T(), 1, len(likes), "Length of likes not 1")
+	assert.Equal(suite.T(), entity.TypeOfLike(2), likes[0].LikeType, "Like type is not updated")
+}
+
+func (suite *RepositoryConformanceSuite) TestConformance_Like_RejectsDuplicate() {
+	post := suite.createPost(conformanceUserId, time.Now())
+
+	suite.createLike(conformanceUserId+1, post.ID)
+
+	_, err := suite.repositories.likes.Create(entity.Like{UserId: conformanceUserId + 1, PostId: post.ID, LikeType: entity.Positive}, context.TODO())
+
+	assert.True(suite.T(), errors.Is(err, ErrDuplicateLike), "Duplicate like is accepted")
+}
+
+func (suite *RepositoryConformanceSuite) TestConformance_Like_RejectsMissingPost() {
+	_, err := suite.repositories.likes.Create(entity.Like{UserId: conformanceUserId, PostId: 999999999, LikeType: entity.Positive}, context.TODO())
+
+	assert.True(suite.T(), errors.Is(err, ErrPostDoesNotExist), "Like for missing post is accepted")
+}
+
+func (suite *RepositoryConformanceSuite) TestConformance_Like_Delete() {
+	post := suite.createPost(conformanceUserId, time.Now())
+	like := suite.createLike(conformanceUserId+1, post.ID)
+
+	suite.createLike(conformanceUserId+2, post.ID)
+	suite.createLike(conformanceUserId+3, post.ID)
+
+	err := suite.repositories.likes.Delete(like.ID, context.TODO())
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+	assert.Equal(suite.T(), 2, len(suite.repositories.likes.GetAllByPostId(post.ID, context.TODO())), "Length of likes not 2")
+
+	err = suite.repositories.likes.DeleteByPostId(post.ID, context.TODO())
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+
+	assert.Empty(suite.T(), suite.repositories.likes.GetAllByPostId(post.ID, context.TODO()), "Likes are not deleted")
+}
+
+func (suite *RepositoryConformanceSuite) TestConformance_DeleteMissing() {
+	assert.True(suite.T(), errors.Is(suite.repositories.posts.Delete(999999999, context.TODO()), gorm.ErrRecordNotFound), "Missing post is deleted")
+	assert.True(suite.T(), errors.Is(suite.repositories.likes.Delete(999999999, context.TODO()), gorm.ErrRecordNotFound), "Missing like is deleted")
+	assert.True(suite.T(), errors.Is(suite.repositories.comments.Delete(999999999, context.TODO()), gorm.ErrRecordNotFound), "Missing comment is deleted")
+}
+
+func (suite *RepositoryConformanceSuite) TestConformance_Comment_CreateAndGetAllByPostId() {
+	post := suite.createPost(conformanceUserId, time.Now())
+	first := suite.createComment(conformanceUserId+1, post.ID)
+	second := suite.createComment(conformanceUserId+1, post.ID)
+
+	comments := suite.repositories.comments.GetAllByPostId(post.ID, context.TODO())
+
+	assert.Equal(suite.T(), 2, len(comments), "Length of comments not 2")
+	assert.ElementsMatch(suite.T(), []uint{first.ID, second.ID}, []uint{comments[0].ID, comments[1].ID}, "Comments do not match")
+	assert.Equal(suite.T(), "Some comment", comments[0].Content, "Content does not match")
+}
+
+func (suite *RepositoryConformanceSuite) TestConformance_Comment_RejectsMissingPost() {
+	_, err := suite.repositories.comments.Create(entity.Comment{UserId: conformanceUserId, PostId: 999999999, Content: "Some comment"}, context.TODO())
+
+	assert.True(suite.T(), errors.Is(err, ErrPostDoesNotExist), "Comment for missing post is accepted")
+}
+
+func (suite *RepositoryConformanceSuite) TestConformance_Comment_Delete() {
+	post := suite.createPost(conformanceUserId, time.Now())
+	comment := suite.createComment(conformanceUserId+1, post.ID)
+
+	suite.createComment(conformanceUserId+1, post.ID)
+
+	err := suite.repositories.comments.Delete(comment.ID, context.TODO())
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+	assert.Equal(suite.T(), 1, len(suite.repositories.comments.GetAllByPostId(post.ID, context.TODO())), "Length of comments not 1")
+
+	err = suite.repositories.comments.DeleteByPostId(post.ID, context.TODO())
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+	assert.Empty(suite.T(), suite.repositories.comments.GetAllByPostId(post.ID, context.TODO()), "Comments are not deleted")
+}
+
+func (suite *RepositoryConformanceSuite) TestConformance_IdempotencyKey_Lifecycle() {
+	key := entity.IdempotencyKey{
+		Key:         conformanceKeyPrefix + "lifecycle",
+		RequestHash: "hash",
+		CreatedAt:   time.Now(),
+		ExpiresAt:   time.Now().Add(time.Hour),
+	}
+
+	created, err := suite.repositories.idempotencyKeys.CreateIfNotExists(key, context.TODO())
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+	assert.True(suite.T(), created, "Key is not created")
+
+	created, err = suite.repositories.idempotencyKeys.CreateIfNotExists(key, context.TODO())
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+	assert.False(suite.T(), created, "Key is created twice")
+
+	key.Completed = true
+	key.StatusCode = 201
+	key.Body = []byte("{}")
+
+	err = suite.repositories.idempotencyKeys.Update(key, context.TODO())
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+
+	stored, err := suite.repositories.idempotencyKeys.GetByKey(key.Key, context.TODO())
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+	assert.True(suite.T(), stored.Completed, "Key is not completed")
+	assert.Equal(suite.T(), 201, stored.StatusCode, "Status code is not stored")
+	assert.Equal(suite.T(), []byte("{}"), stored.Body, "Body is not stored")
+
+	err = suite.repositories.idempotencyKeys.Delete(key.Key, context.TODO())
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+
+	_, err = suite.repositories.idempotencyKeys.GetByKey(key.Key, context.TODO())
+
+	assert.True(suite.T(), errors.Is(err, gorm.ErrRecordNotFound), "Error is not record not found")
+}
+
+func (suite *RepositoryConformanceSuite) TestConformance_IdempotencyKey_DeleteExpired() {
+	now := time.Now()
+
+	suite.repositories.idempotencyKeys.CreateIfNotExists(entity.IdempotencyKey{Key: conformanceKeyPrefix + "expired", RequestHash: "hash", ExpiresAt: now.Add(-time.Minute)}, context.TODO())
+	suite.repositories.idempotencyKeys.CreateIfNotExists(entity.IdempotencyKey{Key: conformanceKeyPrefix + "valid", RequestHash: "hash", ExpiresAt: now.Add(time.Minute)}, context.TODO())
+
+	err := suite.repositories.idempotencyKeys.DeleteExpired(now, context.TODO())
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+
+	_, err = suite.repositories.idempotencyKeys.GetByKey(conformanceKeyPrefix+"expired", context.TODO())
+
+	assert.NotNil(suite.T(), err, "Expired key is not deleted")
+
+	_, err = suite.repositories.idempotencyKeys.GetByKey(conformanceKeyPrefix+"valid", context.TODO())
+
+	assert.Nil(suite.T(), err, "Valid key is deleted")
+}
+
+func (suite *RepositoryConformanceSuite) TestConformance_IdempotencyKey_TakeOver() {
+	now := time.Now()
+	key := conformanceKeyPrefix + "takeover"
+
+	suite.repositories.idempotencyKeys.CreateIfNotExists(entity.IdempotencyKey{Key: key, RequestHash: "hash", CreatedAt: now, ExpiresAt: now.Add(time.Hour), LockedUntil: now.Add(time.Minute)}, context.TODO())
+
+	takenOver, err := suite.repositories.idempotencyKeys.TakeOver(key, "hash", now, now.Add(2*time.Minute), context.TODO())
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+	assert.False(suite.T(), takenOver, "Locked key is taken over")
+
+	later := now.Add(90 * time.Second)
+
+	takenOver, _ = suite.repositories.idempotencyKeys.TakeOver(key, "other-hash", later, later.Add(time.Minute), context.TODO())
+
+	assert.False(suite.T(), takenOver, "Key is taken over by a different request")
+
+	takenOver, err = suite.repositories.idempotencyKeys.TakeOver(key, "hash", later, later.Add(time.Minute), context.TODO())
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+	assert.True(suite.T(), takenOver, "Stale key is not taken over")
+
+	takenOver, _ = suite.repositories.idempotencyKeys.TakeOver(key, "hash", later, later.Add(time.Minute), context.TODO())
+
+	assert.False(suite.T(), takenOver, "Key is taken over twice")
+}
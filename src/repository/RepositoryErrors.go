@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrDuplicateLike    = errors.New("like for this user and post already exists")
+	ErrPostDoesNotExist = errors.New("post does not exist")
+)
+
+// Postgres error codes of the constraints the schema enforces.
+const (
+	uniqueViolation     = "23505"
+	foreignKeyViolation = "23503"
+)
+
+// translateError maps constraint violations reported by the database onto the
+// errors every storage backend returns, so callers need not know the driver.
+func translateError(err error) error {
+	var sqlError interface{ SQLState() string }
+
+	if !errors.As(err, &sqlError) {
+		return err
+	}
+
+	switch sqlError.SQLState() {
+	case uniqueViolation:
+		return ErrDuplicateLike
+	case foreignKeyViolation:
+		return ErrPostDoesNotExist
+	}
+
+	return err
+}
+
+// deletedOne reports gorm.ErrRecordNotFound when a delete by id removed nothing.
+func deletedOne(result *gorm.DB) error {
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
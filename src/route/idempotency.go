@@ -0,0 +1,186 @@
+package route
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"posts-ms/src/service"
+	"sort"
+
+	"github.com/opentracing/opentracing-go"
+)
+
+const (
+	idempotencyKeyHeader      = "Idempotency-Key"
+	idempotencyReplayedHeader = "Idempotency-Replayed"
+	maxIdempotencyKeyLength   = 255
+	// maxRequestBodySize bounds the bodies the middlewares read into memory.
+	maxRequestBodySize = 32 << 20
+)
+
+type recordingResponseWriter struct {
+	*responseWriter
+	body bytes.Buffer
+}
+
+func (rw *recordingResponseWriter) Write(b []byte) (int, error) {
+	rw.body.Write(b)
+
+	return rw.responseWriter.Write(b)
+}
+
+// idempotencyMiddleware stores the response of a request sent with an
+// Idempotency-Key header and replays it when the client retries the request.
+func idempotencyMiddleware(idempotencyService service.IIdempotencyService) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header := r.Header.Get(idempotencyKeyHeader)
+
+			if header == "" {
+				next.ServeHTTP(w, r)
+
+				return
+			}
+
+			if len(header) > maxIdempotencyKeyLength {
+				w.WriteHeader(http.StatusBadRequest)
+
+				return
+			}
+
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestBodySize))
+
+			if err != nil {
+				if len(body) >= maxRequestBodySize {
+					w.WriteHeader(http.StatusRequestEntityTooLarge)
+				} else {
+					w.WriteHeader(http.StatusBadRequest)
+				}
+
+				return
+			}
+
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			requestHash, err := hashRequest(r, body)
+
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+
+				return
+			}
+
+			key := r.Method + " " + r.URL.Path + " " + header
+
+			stored, err := idempotencyService.Begin(key, requestHash, r.Context())
+
+			switch err {
+			case nil:
+			case service.ErrIdempotencyKeyInProgress:
+				w.WriteHeader(http.StatusConflict)
+
+				return
+			case service.ErrIdempotencyKeyReused:
+				w.WriteHeader(http.StatusUnprocessableEntity)
+
+				return
+			default:
+				w.WriteHeader(http.StatusServiceUnavailable)
+
+				return
+			}
+
+			if stored != nil {
+				if stored.ContentType != "" {
+					w.Header().Set("Content-Type", stored.ContentType)
+				}
+
+				w.Header().Set(idempotencyReplayedHeader, "true")
+				w.WriteHeader(stored.StatusCode)
+				w.Write(stored.Body)
+
+				return
+			}
+
+			rw := &recordingResponseWriter{responseWriter: NewResponseWriter(w)}
+
+			next.ServeHTTP(rw, r)
+
+			// The client may already be gone, the outcome still has to be stored.
+			ctx := opentracing.ContextWithSpan(context.Background(), opentracing.SpanFromContext(r.Context()))
+
+			if rw.statusCode >= http.StatusInternalServerError {
+				idempotencyService.Release(key, ctx)
+
+				return
+			}
+
+			idempotencyService.Complete(key, rw.statusCode, rw.Header().Get("Content-Type"), rw.body.Bytes(), ctx)
+		})
+	}
+}
+
+// hashRequest identifies a request by its method, path and body. Multipart
+// bodies are identified by their fields and a digest of each file instead,
+// because clients pick a new random boundary when they build a retry.
+func hashRequest(r *http.Request, body []byte) (string, error) {
+	hash := sha256.New()
+
+	hash.Write([]byte(r.Method + "\n" + r.URL.Path + "\n"))
+
+	mediaType, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+
+	if err != nil || mediaType != "multipart/form-data" {
+		hash.Write(body)
+
+		return hex.EncodeToString(hash.Sum(nil)), nil
+	}
+
+	parts, err := multipartDigests(multipart.NewReader(bytes.NewReader(body), params["boundary"]))
+
+	if err != nil {
+		return "", err
+	}
+
+	for _, part := range parts {
+		hash.Write([]byte(part + "\n"))
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// multipartDigests describes every part by its field name, file name and a
+// digest of its content, sorted so that the order of the parts does not
+// matter.
+func multipartDigests(reader *multipart.Reader) ([]string, error) {
+	var parts []string
+
+	for {
+		part, err := reader.NextPart()
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		digest := sha256.New()
+
+		if _, err := io.Copy(digest, part); err != nil {
+			return nil, err
+		}
+
+		parts = append(parts, part.FormName()+"\x00"+part.FileName()+"\x00"+hex.EncodeToString(digest.Sum(nil)))
+	}
+
+	sort.Strings(parts)
+
+	return parts, nil
+}
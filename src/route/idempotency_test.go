@@ -0,0 +1,195 @@
+package route
+
+import (
+	"bytes"
+	"context"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"posts-ms/src/entity"
+	"posts-ms/src/service"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type idempotencyServiceFake struct {
+	keys map[string]*entity.IdempotencyKey
+}
+
+func (f *idempotencyServiceFake) Begin(key string, requestHash string, ctx context.Context) (*entity.IdempotencyKey, error) {
+	existing, ok := f.keys[key]
+
+	if !ok {
+		f.keys[key] = &entity.IdempotencyKey{Key: key, RequestHash: requestHash}
+
+		return nil, nil
+	}
+
+	if existing.RequestHash != requestHash {
+		return nil, service.ErrIdempotencyKeyReused
+	}
+
+	if !existing.Completed {
+		return nil, service.ErrIdempotencyKeyInProgress
+	}
+
+	return existing, nil
+}
+
+func (f *idempotencyServiceFake) Complete(key string, statusCode int, contentType string, body []byte, ctx context.Context) error {
+	f.keys[key].Completed = true
+	f.keys[key].StatusCode = statusCode
+	f.keys[key].ContentType = contentType
+	f.keys[key].Body = body
+
+	return nil
+}
+
+func (f *idempotencyServiceFake) Release(key string, ctx context.Context) error {
+	delete(f.keys, key)
+
+	return nil
+}
+
+func (f *idempotencyServiceFake) DeleteExpired(context.Context) error {
+	return nil
+}
+
+type IdempotencyMiddlewareUnitTestSuite struct {
+	suite.Suite
+	idempotencyService *idempotencyServiceFake
+	calls              int
+	status             int
+	handler            http.Handler
+}
+
+func TestIdempotencyMiddlewareUnitTestSuite(t *testing.T) {
+	suite.Run(t, new(IdempotencyMiddlewareUnitTestSuite))
+}
+
+func (suite *IdempotencyMiddlewareUnitTestSuite) SetupTest() {
+	suite.idempotencyService = &idempotencyServiceFake{keys: map[string]*entity.IdempotencyKey{}}
+	suite.calls = 0
+	suite.status = http.StatusCreated
+
+	suite.handler = idempotencyMiddleware(suite.idempotencyService)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		suite.calls++
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(suite.status)
+		w.Write([]byte(`{"id":1}`))
+	}))
+}
+
+func (suite *IdempotencyMiddlewareUnitTestSuite) send(key string, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", "/api/likes", strings.NewReader(body))
+
+	if key != "" {
+		req.Header.Set(idempotencyKeyHeader, key)
+	}
+
+	rec := httptest.NewRecorder()
+
+	suite.handler.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func (suite *IdempotencyMiddlewareUnitTestSuite) TestIdempotencyMiddleware_WithoutKey_CallsHandlerEveryTime() {
+	suite.send("", `{"postId":1}`)
+	suite.send("", `{"postId":1}`)
+
+	assert.Equal(suite.T(), 2, suite.calls, "Handler is not called twice")
+}
+
+func (suite *IdempotencyMiddlewareUnitTestSuite) TestIdempotencyMiddleware_Retry_ReplaysStoredResponse() {
+	first := suite.send("abc", `{"postId":1}`)
+	second := suite.send("abc", `{"postId":1}`)
+
+	assert.Equal(suite.T(), 1, suite.calls, "Handler is not called once")
+	assert.Equal(suite.T(), http.StatusCreated, second.Code, "Status code is not replayed")
+	assert.Equal(suite.T(), first.Body.String(), second.Body.String(), "Body is not replayed")
+	assert.Equal(suite.T(), "application/json", second.Header().Get("Content-Type"), "Content type is not replayed")
+	assert.Equal(suite.T(), "true", second.Header().Get(idempotencyReplayedHeader), "Replayed header is missing")
+}
+
+func (suite *IdempotencyMiddlewareUnitTestSuite) TestIdempotencyMiddleware_SameKeyDifferentBody_ReturnsUnprocessableEntity() {
+	suite.send("abc", `{"postId":1}`)
+	rec := suite.send("abc", `{"postId":2}`)
+
+	assert.Equal(suite.T(), 1, suite.calls, "Handler is not called once")
+	assert.Equal(suite.T(), http.StatusUnprocessableEntity, rec.Code, "Status code is not 422")
+}
+
+func (suite *IdempotencyMiddlewareUnitTestSuite) TestIdempotencyMiddleware_RequestInProgress_ReturnsConflict() {
+	requestHash, _ := hashRequest(httptest.NewRequest("POST", "/api/likes", nil), []byte(`{"postId":1}`))
+
+	suite.idempotencyService.keys["POST /api/likes abc"] = &entity.IdempotencyKey{RequestHash: requestHash}
+
+	rec := suite.send("abc", `{"postId":1}`)
+
+	assert.Equal(suite.T(), 0, suite.calls, "Handler is called")
+	assert.Equal(suite.T(), http.StatusConflict, rec.Code, "Status code is not 409")
+}
+
+func (suite *IdempotencyMiddlewareUnitTestSuite) TestIdempotencyMiddleware_ServerError_ReleasesKey() {
+	suite.status = http.StatusInternalServerError
+
+	suite.send("abc", `{"postId":1}`)
+
+	suite.status = http.StatusCreated
+
+	rec := suite.send("abc", `{"postId":1}`)
+
+	assert.Equal(suite.T(), 2, suite.calls, "Handler is not called twice")
+	assert.Equal(suite.T(), http.StatusCreated, rec.Code, "Status code is not 201")
+}
+
+func (suite *IdempotencyMiddlewareUnitTestSuite) sendPost(key string, description string, image string) *httptest.ResponseRecorder {
+	var body bytes.Buffer
+
+	writer := multipart.NewWriter(&body)
+
+	writer.WriteField("post", `{"description":"`+description+`","userId":1}`)
+
+	file, _ := writer.CreateFormFile("file", "image.png")
+
+	file.Write([]byte(image))
+	writer.Close()
+
+	req := httptest.NewRequest("POST", "/api/posts", &body)
+
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set(idempotencyKeyHeader, key)
+
+	rec := httptest.NewRecorder()
+
+	suite.handler.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func (suite *IdempotencyMiddlewareUnitTestSuite) TestIdempotencyMiddleware_MultipartRetryWithNewBoundary_ReplaysStoredResponse() {
+	suite.sendPost("abc", "Some text", "png")
+	rec := suite.sendPost("abc", "Some text", "png")
+
+	assert.Equal(suite.T(), 1, suite.calls, "Handler is not called once")
+	assert.Equal(suite.T(), "true", rec.Header().Get(idempotencyReplayedHeader), "Response is not replayed")
+}
+
+func (suite *IdempotencyMiddlewareUnitTestSuite) TestIdempotencyMiddleware_MultipartDifferentFile_ReturnsUnprocessableEntity() {
+	suite.sendPost("abc", "Some text", "png")
+	rec := suite.sendPost("abc", "Some text", "jpg")
+
+	assert.Equal(suite.T(), http.StatusUnprocessableEntity, rec.Code, "Status code is not 422")
+}
+
+func (suite *IdempotencyMiddlewareUnitTestSuite) TestIdempotencyMiddleware_BodyTooLarge_ReturnsRequestEntityTooLarge() {
+	rec := suite.send("abc", strings.Repeat("a", maxRequestBodySize+1))
+
+	assert.Equal(suite.T(), 0, suite.calls, "Handler is called")
+	assert.Equal(suite.T(), http.StatusRequestEntityTooLarge, rec.Code, "Status code is not 413")
+}
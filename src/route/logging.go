@@ -0,0 +1,70 @@
+package route
+
+import (
+	"net/http"
+	"posts-ms/src/utils"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+var validRequestId = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// requestLoggingMiddleware gives every request an id, taken from the
+// X-Request-ID header when the caller sent a usable one, stores it with the
+// route in the context so that log lines carry them, and logs the outcome.
+func requestLoggingMiddleware(logger *logrus.Entry) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+
+			requestId := r.Header.Get(utils.RequestIdHeader)
+
+			if !validRequestId.MatchString(requestId) {
+				requestId = uuid.Must(uuid.NewV4()).String()
+			}
+
+			info := &utils.RequestInfo{RequestId: requestId, Route: routeTemplate(r)}
+
+			if userId, err := strconv.ParseUint(mux.Vars(r)["userId"], 10, 64); err == nil {
+				info.SetUserId(uint(userId))
+			}
+
+			ctx := utils.WithRequestInfo(r.Context(), info)
+
+			w.Header().Set(utils.RequestIdHeader, requestId)
+
+			rw := NewResponseWriter(w)
+
+			next.ServeHTTP(rw, r.WithContext(ctx))
+
+			entry := logger.WithContext(ctx).WithFields(logrus.Fields{
+				"method":     r.Method,
+				"status":     rw.statusCode,
+				"durationMs": float64(time.Since(start).Microseconds()) / 1000,
+			})
+
+			if rw.statusCode >= http.StatusInternalServerError {
+				entry.Error("Request failed")
+			} else {
+				entry.Info("Request completed")
+			}
+		})
+	}
+}
+
+// routeTemplate returns the path template of the matched route, falling back
+// to the request path.
+func routeTemplate(r *http.Request) string {
+	if route := mux.CurrentRoute(r); route != nil {
+		if template, err := route.GetPathTemplate(); err == nil {
+			return template
+		}
+	}
+
+	return r.URL.Path
+}
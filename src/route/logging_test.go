@@ -0,0 +1,72 @@
+package route
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"posts-ms/src/utils"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type RequestLoggingMiddlewareUnitTestSuite struct {
+	suite.Suite
+	router *mux.Router
+	info   utils.RequestInfo
+}
+
+func TestRequestLoggingMiddlewareUnitTestSuite(t *testing.T) {
+	suite.Run(t, new(RequestLoggingMiddlewareUnitTestSuite))
+}
+
+func (suite *RequestLoggingMiddlewareUnitTestSuite) SetupTest() {
+	suite.info = utils.RequestInfo{}
+
+	suite.router = mux.NewRouter()
+	suite.router.Use(requestLoggingMiddleware(utils.Logger()))
+	suite.router.HandleFunc("/api/posts/users/{userId}", func(w http.ResponseWriter, r *http.Request) {
+		info := utils.RequestInfoFrom(r.Context())
+
+		suite.info.RequestId = info.RequestId
+		suite.info.Route = info.Route
+		suite.info.SetUserId(info.UserId())
+
+		w.WriteHeader(http.StatusOK)
+	}).Methods("GET")
+}
+
+func (suite *RequestLoggingMiddlewareUnitTestSuite) TestRequestLogging_GeneratesRequestId() {
+	recorder := httptest.NewRecorder()
+
+	suite.router.ServeHTTP(recorder, httptest.NewRequest("GET", "/api/posts/users/7", nil))
+
+	assert.NotEmpty(suite.T(), suite.info.RequestId, "Request id is not generated")
+	assert.Equal(suite.T(), suite.info.RequestId, recorder.Header().Get("X-Request-ID"), "Request id is not returned")
+	assert.Equal(suite.T(), "/api/posts/users/{userId}", suite.info.Route, "Route is not the template")
+	assert.Equal(suite.T(), uint(7), suite.info.UserId(), "User id is not taken from path")
+}
+
+func (suite *RequestLoggingMiddlewareUnitTestSuite) TestRequestLogging_AcceptsRequestId() {
+	recorder := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/posts/users/7", nil)
+
+	req.Header.Set("X-Request-ID", "gateway-1234")
+
+	suite.router.ServeHTTP(recorder, req)
+
+	assert.Equal(suite.T(), "gateway-1234", suite.info.RequestId, "Request id is not accepted")
+	assert.Equal(suite.T(), "gateway-1234", recorder.Header().Get("X-Request-ID"), "Request id is not returned")
+}
+
+func (suite *RequestLoggingMiddlewareUnitTestSuite) TestRequestLogging_ReplacesInvalidRequestId() {
+	req := httptest.NewRequest("GET", "/api/posts/users/7", nil)
+
+	req.Header.Set("X-Request-ID", "bad id\nwith newline")
+
+	suite.router.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.NotEqual(suite.T(), "bad id\nwith newline", suite.info.RequestId, "Invalid request id is accepted")
+	assert.NotEmpty(suite.T(), suite.info.RequestId, "Request id is not generated")
+}
@@ -0,0 +1,55 @@
+package route
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type PrometheusMiddlewareUnitTestSuite struct {
+	suite.Suite
+	router *mux.Router
+	status int
+}
+
+func TestPrometheusMiddlewareUnitTestSuite(t *testing.T) {
+	suite.Run(t, new(PrometheusMiddlewareUnitTestSuite))
+}
+
+func (suite *PrometheusMiddlewareUnitTestSuite) SetupTest() {
+	suite.status = http.StatusOK
+
+	suite.router = mux.NewRouter()
+	suite.router.Use(prometheusMiddleware)
+	suite.router.HandleFunc("/api/metrics-test/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(suite.status)
+	}).Methods("DELETE")
+}
+
+func (suite *PrometheusMiddlewareUnitTestSuite) TestPrometheusMiddleware_LabelsByRouteTemplateAndStatusClass() {
+	ok := httpRequests.WithLabelValues("DELETE", "/api/metrics-test/{id}", "2xx")
+	notFound := httpRequests.WithLabelValues("DELETE", "/api/metrics-test/{id}", "4xx")
+	okBefore := testutil.ToFloat64(ok)
+	notFoundBefore := testutil.ToFloat64(notFound)
+
+	suite.router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("DELETE", "/api/metrics-test/1", nil))
+	suite.router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("DELETE", "/api/metrics-test/2", nil))
+
+	suite.status = http.StatusNotFound
+
+	suite.router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("DELETE", "/api/metrics-test/3", nil))
+
+	assert.Equal(suite.T(), okBefore+2, testutil.ToFloat64(ok), "Successful requests are not counted under the template")
+	assert.Equal(suite.T(), notFoundBefore+1, testutil.ToFloat64(notFound), "Not found request is not counted as 4xx")
+}
+
+func (suite *PrometheusMiddlewareUnitTestSuite) TestPrometheusMiddleware_StatusClass() {
+	assert.Equal(suite.T(), "2xx", statusClass(http.StatusNoContent), "204 is not 2xx")
+	assert.Equal(suite.T(), "4xx", statusClass(http.StatusTooManyRequests), "429 is not 4xx")
+	assert.Equal(suite.T(), "5xx", statusClass(http.StatusServiceUnavailable), "503 is not 5xx")
+}
@@ -0,0 +1,183 @@
+package route
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"posts-ms/src/dto/response"
+	"posts-ms/src/service"
+	"posts-ms/src/utils"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// RateLimitOptions configures rateLimitMiddleware. Routes are keyed by method
+// and route template, for example "POST /api/likes". TrustProxy takes the
+// client address from the last X-Forwarded-For entry, the one added by the
+// proxy in front of the service, instead of the connection.
+type RateLimitOptions struct {
+	Routes     map[string]service.RouteRateLimit
+	TrustProxy bool
+}
+
+type rateLimitBucket struct {
+	scope string
+	key   string
+	limit service.RateLimit
+}
+
+// rateLimitMiddleware answers 429 with Retry-After once a client or a user
+// has spent the tokens of a limited route. The client bucket is checked first
+// so that a client over its limit is turned away before its body is read.
+// When the limiter fails the request is let through, a broken store must not
+// take the API down.
+func rateLimitMiddleware(limiter service.IRateLimiter, options RateLimitOptions, logger *logrus.Entry) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route := r.Method + " " + routeTemplate(r)
+			limit, ok := options.Routes[route]
+
+			if !ok {
+				next.ServeHTTP(w, r)
+
+				return
+			}
+
+			if !takeToken(limiter, rateLimitBucket{"client", "ip:" + route + ":" + clientAddress(r, options.TrustProxy), limit.IP}, w, r, logger) {
+				return
+			}
+
+			if limit.User.Enabled() {
+				if userId, ok := requestUserId(r); ok {
+					if !takeToken(limiter, rateLimitBucket{"user", fmt.Sprintf("user:%s:%d", route, userId), limit.User}, w, r, logger) {
+						return
+					}
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// takeToken takes a token from the bucket and reports whether the request may
+// go on. When it may not, the 429 response has already been written.
+func takeToken(limiter service.IRateLimiter, bucket rateLimitBucket, w http.ResponseWriter, r *http.Request, logger *logrus.Entry) bool {
+	allowed, retryAfter, err := limiter.Take(bucket.key, bucket.limit, r.Context())
+
+	if err != nil {
+		logger.WithContext(r.Context()).WithError(err).Warn("Error occured in rate limiting, request is let through")
+
+		return true
+	}
+
+	if !allowed {
+		logger.WithContext(r.Context()).Infof("Rate limit %s per %s exceeded", bucket.limit, bucket.scope)
+
+		writeTooManyRequests(w, r, retryAfter, fmt.Sprintf("Rate limit of %s per %s exceeded.", bucket.limit, bucket.scope))
+
+		return false
+	}
+
+	return true
+}
+
+func writeTooManyRequests(w http.ResponseWriter, r *http.Request, retryAfter time.Duration, detail string) {
+	payload, _ := json.Marshal(response.ProblemDto{
+		Type:     "about:blank",
+		Title:    http.StatusText(http.StatusTooManyRequests),
+		Status:   http.StatusTooManyRequests,
+		Detail:   detail,
+		Instance: r.URL.Path,
+		TraceId:  utils.TraceId(r.Context()),
+	})
+
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(http.StatusTooManyRequests)
+	w.Write(payload)
+}
+
+func clientAddress(r *http.Request, trustProxy bool) string {
+	if trustProxy {
+		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+			entries := strings.Split(forwarded, ",")
+
+			return strings.TrimSpace(entries[len(entries)-1])
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
+// requestUserId finds the user a write request acts for: the userId path
+// variable, or the userId field of the JSON body or of the post form field.
+// The service has no authentication, so the id is whatever the client sent and
+// a client can spread its requests over many ids; only the client bucket holds
+// against that. Bodies over maxRequestBodySize are not inspected. The body is
+// restored for the handler.
+func requestUserId(r *http.Request) (uint, bool) {
+	if value, ok := mux.Vars(r)["userId"]; ok {
+		userId, err := strconv.ParseUint(value, 10, 32)
+
+		return uint(userId), err == nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxRequestBodySize+1))
+
+	r.Body = readCloser{io.MultiReader(bytes.NewReader(body), r.Body), r.Body}
+
+	if err != nil || len(body) > maxRequestBodySize {
+		return 0, false
+	}
+
+	payload := body
+
+	if mediaType, params, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err == nil && mediaType == "multipart/form-data" {
+		form, err := multipart.NewReader(bytes.NewReader(body), params["boundary"]).ReadForm(maxRequestBodySize)
+
+		if err != nil {
+			return 0, false
+		}
+
+		defer form.RemoveAll()
+
+		if len(form.Value["post"]) == 0 {
+			return 0, false
+		}
+
+		payload = []byte(form.Value["post"][0])
+	}
+
+	var dto struct {
+		UserId uint `json:"userId"`
+	}
+
+	if json.Unmarshal(payload, &dto) != nil || dto.UserId == 0 {
+		return 0, false
+	}
+
+	return dto.UserId, true
+}
+
+// readCloser puts the bytes already read back in front of a request body.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,257 @@
+package route
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"posts-ms/src/dto/response"
+	"posts-ms/src/service"
+	"posts-ms/src/utils"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type failingRateLimiter struct{}
+
+func (failingRateLimiter) Take(string, service.RateLimit, context.Context) (bool, time.Duration, error) {
+	return false, 0, errors.New("store is down")
+}
+
+type countingReader struct {
+	io.Reader
+	read int
+}
+
+func (reader *countingReader) Read(p []byte) (int, error) {
+	n, err := reader.Reader.Read(p)
+
+	reader.read += n
+
+	return n, err
+}
+
+type RateLimitMiddlewareUnitTestSuite struct {
+	suite.Suite
+	options  RateLimitOptions
+	limiter  service.IRateLimiter
+	received []string
+}
+
+func TestRateLimitMiddlewareUnitTestSuite(t *testing.T) {
+	suite.Run(t, new(RateLimitMiddlewareUnitTestSuite))
+}
+
+func (suite *RateLimitMiddlewareUnitTestSuite) SetupTest() {
+	suite.limiter = service.NewInMemoryRateLimiter()
+	suite.received = nil
+	suite.options = RateLimitOptions{Routes: map[string]service.RouteRateLimit{
+		"POST /api/likes": {
+			User: service.RateLimit{Requests: 1, Per: time.Minute},
+			IP:   service.RateLimit{Requests: 3, Per: time.Minute},
+		},
+		"POST /api/posts": {
+			User: service.RateLimit{Requests: 1, Per: time.Minute},
+		},
+		"DELETE /api/likes/users/{userId}/posts/{postId}": {
+			User: service.RateLimit{Requests: 1, Per: time.Minute},
+		},
+	}}
+}
+
+func (suite *RateLimitMiddlewareUnitTestSuite) serve(req *http.Request) *httptest.ResponseRecorder {
+	router := mux.NewRouter()
+
+	router.Use(rateLimitMiddleware(suite.limiter, suite.options, utils.Logger()))
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		suite.received = append(suite.received, string(body))
+
+		w.WriteHeader(http.StatusCreated)
+	}
+
+	router.HandleFunc("/api/likes", handler).Methods("POST")
+	router.HandleFunc("/api/posts", handler).Methods("POST")
+	router.HandleFunc("/api/likes/users/{userId}/posts/{postId}", handler).Methods("DELETE")
+	router.HandleFunc("/api/likes/posts/{postId}", handler).Methods("GET")
+
+	recorder := httptest.NewRecorder()
+
+	router.ServeHTTP(recorder, req)
+
+	return recorder
+}
+
+func like(userId string, remoteAddr string) *http.Request {
+	req := httptest.NewRequest("POST", "/api/likes", strings.NewReader(`{"userId":`+userId+`,"postId":1,"likeType":1}`))
+
+	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = remoteAddr
+
+	return req
+}
+
+func (suite *RateLimitMiddlewareUnitTestSuite) TestRateLimit_UserOverLimitGetsTooManyRequests() {
+	first := suite.serve(like("7", "10.0.0.1:1234"))
+	second := suite.serve(like("7", "10.0.0.1:1234"))
+
+	assert.Equal(suite.T(), http.StatusCreated, first.Code, "First request is limited")
+	assert.Equal(suite.T(), http.StatusTooManyRequests, second.Code, "Second request is not limited")
+	assert.Equal(suite.T(), "60", second.Header().Get("Retry-After"), "Retry-After is not the time to the next token")
+	assert.Equal(suite.T(), "application/problem+json", second.Header().Get("Content-Type"), "Response is not a problem")
+
+	var problem response.ProblemDto
+
+	json.Unmarshal(second.Body.Bytes(), &problem)
+
+	assert.Equal(suite.T(), http.StatusTooManyRequests, problem.Status, "Problem status is not 429")
+	assert.Contains(suite.T(), problem.Detail, "per user", "Problem does not name the user limit")
+}
+
+func (suite *RateLimitMiddlewareUnitTestSuite) TestRateLimit_BodyIsRestoredForHandler() {
+	suite.serve(like("7", "10.0.0.1:1234"))
+
+	assert.Equal(suite.T(), []string{`{"userId":7,"postId":1,"likeType":1}`}, suite.received, "Handler does not get the body")
+}
+
+func (suite *RateLimitMiddlewareUnitTestSuite) TestRateLimit_UsersAreLimitedSeparately() {
+	first := suite.serve(like("7", "10.0.0.1:1234"))
+	second := suite.serve(like("8", "10.0.0.1:1234"))
+
+	assert.Equal(suite.T(), http.StatusCreated, first.Code, "First user is limited")
+	assert.Equal(suite.T(), http.StatusCreated, second.Code, "Second user is limited by the first")
+}
+
+func (suite *RateLimitMiddlewareUnitTestSuite) TestRateLimit_ClientOverLimitGetsTooManyRequests() {
+	for _, userId := range []string{"1", "2", "3"} {
+		assert.Equal(suite.T(), http.StatusCreated, suite.serve(like(userId, "10.0.0.1:1234")).Code, "Request within client limit is limited")
+	}
+
+	limited := suite.serve(like("4", "10.0.0.1:1234"))
+	otherClient := suite.serve(like("5", "10.0.0.2:1234"))
+
+	assert.Equal(suite.T(), http.StatusTooManyRequests, limited.Code, "Client over limit is not limited")
+	assert.Contains(suite.T(), limited.Body.String(), "per client", "Problem does not name the client limit")
+	assert.Equal(suite.T(), http.StatusCreated, otherClient.Code, "Other client is limited")
+}
+
+func (suite *RateLimitMiddlewareUnitTestSuite) TestRateLimit_ForwardedForIsTrustedOnlyWhenConfigured() {
+	suite.options.Routes["POST /api/likes"] = service.RouteRateLimit{IP: service.RateLimit{Requests: 1, Per: time.Minute}}
+
+	forwarded := func(address string) *http.Request {
+		req := like("7", "10.0.0.1:1234")
+
+		req.Header.Set("X-Forwarded-For", "198.51.100.1, "+address)
+
+		return req
+	}
+
+	suite.serve(forwarded("192.0.2.1"))
+
+	assert.Equal(suite.T(), http.StatusTooManyRequests, suite.serve(forwarded("192.0.2.2")).Code, "Forwarded address is trusted")
+
+	suite.options.TrustProxy = true
+	suite.limiter = service.NewInMemoryRateLimiter()
+
+	suite.serve(forwarded("192.0.2.1"))
+
+	assert.Equal(suite.T(), http.StatusCreated, suite.serve(forwarded("192.0.2.2")).Code, "Forwarded address is not used")
+}
+
+func (suite *RateLimitMiddlewareUnitTestSuite) TestRateLimit_ForwardedForEntriesSetByClientAreIgnored() {
+	suite.options.Routes["POST /api/likes"] = service.RouteRateLimit{IP: service.RateLimit{Requests: 1, Per: time.Minute}}
+	suite.options.TrustProxy = true
+
+	spoofed := func(address string) *http.Request {
+		req := like("7", "10.0.0.1:1234")
+
+		req.Header.Set("X-Forwarded-For", address+", 192.0.2.1")
+
+		return req
+	}
+
+	suite.serve(spoofed("198.51.100.1"))
+
+	assert.Equal(suite.T(), http.StatusTooManyRequests, suite.serve(spoofed("198.51.100.2")).Code, "Client escapes the limit by prepending an address")
+}
+
+func (suite *RateLimitMiddlewareUnitTestSuite) TestRateLimit_ClientOverLimitIsRejectedBeforeBodyIsRead() {
+	suite.options.Routes["POST /api/likes"] = service.RouteRateLimit{
+		User: service.RateLimit{Requests: 1, Per: time.Minute},
+		IP:   service.RateLimit{Requests: 1, Per: time.Minute},
+	}
+
+	suite.serve(like("7", "10.0.0.1:1234"))
+
+	body := &countingReader{Reader: strings.NewReader(`{"userId":8,"postId":1,"likeType":1}`)}
+	req := httptest.NewRequest("POST", "/api/likes", body)
+
+	req.RemoteAddr = "10.0.0.1:1234"
+
+	assert.Equal(suite.T(), http.StatusTooManyRequests, suite.serve(req).Code, "Client over limit is not limited")
+	assert.Equal(suite.T(), 0, body.read, "Body of a limited client is read")
+}
+
+func (suite *RateLimitMiddlewareUnitTestSuite) TestRateLimit_LargeBodyIsPassedThroughWhole() {
+	large := `{"userId":7,"description":"` + strings.Repeat("a", maxRequestBodySize) + `"}`
+
+	suite.serve(httptest.NewRequest("POST", "/api/posts", strings.NewReader(large)))
+
+	assert.Equal(suite.T(), []string{large}, suite.received, "Handler does not get the whole body")
+}
+
+func (suite *RateLimitMiddlewareUnitTestSuite) TestRateLimit_UserIsTakenFromPathAndMultipartForm() {
+	deleteLike := func() *http.Request {
+		return httptest.NewRequest("DELETE", "/api/likes/users/7/posts/1", nil)
+	}
+
+	suite.serve(deleteLike())
+
+	assert.Equal(suite.T(), http.StatusTooManyRequests, suite.serve(deleteLike()).Code, "User is not taken from path")
+
+	createPost := func() *http.Request {
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+
+		writer.WriteField("post", `{"userId":7,"description":"Post"}`)
+		writer.Close()
+
+		req := httptest.NewRequest("POST", "/api/posts", body)
+
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+
+		return req
+	}
+
+	suite.serve(createPost())
+
+	assert.Equal(suite.T(), http.StatusTooManyRequests, suite.serve(createPost()).Code, "User is not taken from multipart form")
+	assert.Contains(suite.T(), suite.received[1], `{"userId":7,"description":"Post"}`, "Handler does not get the multipart body")
+}
+
+func (suite *RateLimitMiddlewareUnitTestSuite) TestRateLimit_UnlimitedRoutePassesThrough() {
+	for i := 0; i < 5; i++ {
+		recorder := suite.serve(httptest.NewRequest("GET", "/api/likes/posts/1", nil))
+
+		assert.Equal(suite.T(), http.StatusCreated, recorder.Code, "Route without limit is limited")
+	}
+}
+
+func (suite *RateLimitMiddlewareUnitTestSuite) TestRateLimit_FailingLimiterLetsRequestThrough() {
+	suite.limiter = failingRateLimiter{}
+
+	recorder := suite.serve(like("7", "10.0.0.1:1234"))
+
+	assert.Equal(suite.T(), http.StatusCreated, recorder.Code, "Request is rejected when the limiter fails")
+}
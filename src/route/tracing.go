@@ -0,0 +1,36 @@
+package route
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+)
+
+func tracingMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tracer := opentracing.GlobalTracer()
+
+		path := routeTemplate(r)
+
+		parent, _ := tracer.Extract(opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(r.Header))
+
+		span := tracer.StartSpan(fmt.Sprintf("HTTP %s %s", r.Method, path), ext.RPCServerOption(parent))
+
+		defer span.Finish()
+
+		ext.HTTPMethod.Set(span, r.Method)
+		ext.HTTPUrl.Set(span, r.URL.String())
+		ext.Component.Set(span, "net/http")
+
+		rw := NewResponseWriter(w)
+		next.ServeHTTP(rw, r.WithContext(opentracing.ContextWithSpan(r.Context(), span)))
+
+		ext.HTTPStatusCode.Set(span, uint16(rw.statusCode))
+
+		if rw.statusCode >= http.StatusInternalServerError {
+			ext.Error.Set(span, true)
+		}
+	})
+}
@@ -0,0 +1,75 @@
+package route
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/mocktracer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type TracingMiddlewareUnitTestSuite struct {
+	suite.Suite
+	tracer *mocktracer.MockTracer
+	router *mux.Router
+}
+
+func TestTracingMiddlewareUnitTestSuite(t *testing.T) {
+	suite.Run(t, new(TracingMiddlewareUnitTestSuite))
+}
+
+func (suite *TracingMiddlewareUnitTestSuite) SetupTest() {
+	suite.tracer = mocktracer.New()
+
+	opentracing.SetGlobalTracer(suite.tracer)
+
+	suite.router = mux.NewRouter()
+	suite.router.Use(tracingMiddleware)
+	suite.router.HandleFunc("/api/posts/{id}", func(w http.ResponseWriter, r *http.Request) {
+		span, _ := opentracing.StartSpanFromContext(r.Context(), "Handle /api/posts/{id}")
+
+		span.Finish()
+
+		w.WriteHeader(http.StatusNoContent)
+	}).Methods("DELETE")
+}
+
+func (suite *TracingMiddlewareUnitTestSuite) TearDownTest() {
+	opentracing.SetGlobalTracer(opentracing.NoopTracer{})
+}
+
+func (suite *TracingMiddlewareUnitTestSuite) TestTracingMiddleware_WithIncomingTraceHeaders_ContinuesTrace() {
+	parent := suite.tracer.StartSpan("client")
+
+	req := httptest.NewRequest("DELETE", "/api/posts/1", nil)
+
+	suite.tracer.Inject(parent.Context(), opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(req.Header))
+
+	suite.router.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := suite.tracer.FinishedSpans()
+
+	assert.Equal(suite.T(), 2, len(spans), "Number of finished spans is not 2")
+
+	handler, server := spans[0], spans[1]
+	client := parent.(*mocktracer.MockSpan)
+
+	assert.Equal(suite.T(), "HTTP DELETE /api/posts/{id}", server.OperationName, "Operation name does not contain route template")
+	assert.Equal(suite.T(), client.SpanContext.TraceID, server.SpanContext.TraceID, "Trace id is not extracted")
+	assert.Equal(suite.T(), client.SpanContext.SpanID, server.ParentID, "Server span is not child of client span")
+	assert.Equal(suite.T(), server.SpanContext.SpanID, handler.ParentID, "Handler span is not child of server span")
+	assert.Equal(suite.T(), uint16(http.StatusNoContent), server.Tag("http.status_code"), "Status code is not tagged")
+}
+
+func (suite *TracingMiddlewareUnitTestSuite) TestTracingMiddleware_WithoutTraceHeaders_StartsNewTrace() {
+	suite.router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("DELETE", "/api/posts/1", nil))
+
+	spans := suite.tracer.FinishedSpans()
+
+	assert.Equal(suite.T(), 2, len(spans), "Number of finished spans is not 2")
+	assert.Equal(suite.T(), 0, spans[1].ParentID, "Server span has parent")
+}
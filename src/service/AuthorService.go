@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"posts-ms/src/client"
+	"posts-ms/src/dto/response"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/sirupsen/logrus"
+)
+
+type IAuthorService interface {
+	AddAuthorsToPosts([]*response.PostDto, context.Context)
+	AddAuthorsToComments([]*response.CommentDto, context.Context)
+}
+
+// AuthorService fills in author details with one users-ms request per list.
+// When users-ms is unavailable the lists are left with user ids only.
+type AuthorService struct {
+	UserRESTClient client.IUserRESTClient
+	Logger         *logrus.Entry
+}
+
+func (s AuthorService) AddAuthorsToPosts(posts []*response.PostDto, ctx context.Context) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Service - Add authors to posts")
+
+	defer span.Finish()
+
+	ids := []uint{}
+
+	for _, post := range posts {
+		ids = append(ids, post.UserId)
+
+		for _, comment := range post.Comments {
+			ids = append(ids, comment.UserId)
+		}
+	}
+
+	authors := s.getAuthors(ids, ctx)
+
+	for _, post := range posts {
+		post.Author = authors[post.UserId]
+
+		for i := range post.Comments {
+			post.Comments[i].Author = authors[post.Comments[i].UserId]
+		}
+	}
+}
+
+func (s AuthorService) AddAuthorsToComments(comments []*response.CommentDto, ctx context.Context) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Service - Add authors to comments")
+
+	defer span.Finish()
+
+	ids := []uint{}
+
+	for _, comment := range comments {
+		ids = append(ids, comment.UserId)
+	}
+
+	authors := s.getAuthors(ids, ctx)
+
+	for _, comment := range comments {
+		comment.Author = authors[comment.UserId]
+	}
+}
+
+func (s AuthorService) getAuthors(ids []uint, ctx context.Context) map[uint]*response.AuthorDto {
+	authors := map[uint]*response.AuthorDto{}
+
+	if len(ids) == 0 {
+		return authors
+	}
+
+	userIds := []int{}
+	seen := map[uint]bool{}
+
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			userIds = append(userIds, int(id))
+		}
+	}
+
+	s.Logger.WithContext(ctx).Info("Sending request on user-ms for fetching authors")
+
+	users, err := s.UserRESTClient.GetUsers(userIds, ctx)
+
+	if err != nil {
+		s.Logger.WithContext(ctx).WithError(err).Error("Error occured in fetching authors, returning user ids only")
+
+		return authors
+	}
+
+	for _, user := range users {
+		authors[uint(user.ID)] = &response.AuthorDto{
+			Id:        user.ID,
+			Username:  user.Username,
+			FirstName: user.FirstName,
+			LastName:  user.LastName,
+		}
+	}
+
+	return authors
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"posts-ms/src/client"
+	"posts-ms/src/dto/response"
+	"posts-ms/src/utils"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type AuthorServiceUnitTestSuite struct {
+	suite.Suite
+	userRestClientMock *client.UserRESTClientMock
+	service            AuthorService
+}
+
+func TestAuthorServiceUnitTestSuite(t *testing.T) {
+	suite.Run(t, new(AuthorServiceUnitTestSuite))
+}
+
+func (suite *AuthorServiceUnitTestSuite) SetupSuite() {
+	suite.userRestClientMock = new(client.UserRESTClientMock)
+
+	suite.service = AuthorService{UserRESTClient: suite.userRestClientMock, Logger: utils.Logger()}
+}
+
+func (suite *AuthorServiceUnitTestSuite) TestAuthorService_AddAuthorsToPosts_AddsPostAndCommentAuthors() {
+	posts := []*response.PostDto{
+		{Id: 1, UserId: 2, Comments: []response.CommentDto{{Id: 1, UserId: 3}}},
+		{Id: 2, UserId: 2},
+	}
+
+	suite.service.AddAuthorsToPosts(posts, context.TODO())
+
+	assert.NotNil(suite.T(), posts[0].Author, "Author is nil")
+	assert.Equal(suite.T(), 2, posts[0].Author.Id, "Author id is not 2")
+	assert.Equal(suite.T(), "Username", posts[0].Author.Username, "Username is not set")
+	assert.Equal(suite.T(), "First", posts[1].Author.FirstName, "First name is not set")
+	assert.Equal(suite.T(), 3, posts[0].Comments[0].Author.Id, "Comment author id is not 3")
+}
+
+func (suite *AuthorServiceUnitTestSuite) TestAuthorService_AddAuthorsToComments_UnknownUserHasNoAuthor() {
+	comments := []*response.CommentDto{{Id: 1, UserId: 404}, {Id: 2, UserId: 5}}
+
+	suite.service.AddAuthorsToComments(comments, context.TODO())
+
+	assert.Nil(suite.T(), comments[0].Author, "Author is not nil")
+	assert.Equal(suite.T(), "Last", comments[1].Author.LastName, "Last name is not set")
+}
+
+func (suite *AuthorServiceUnitTestSuite) TestAuthorService_AddAuthorsToComments_UserServiceUnavailable() {
+	comments := []*response.CommentDto{{Id: 1, UserId: 503}, {Id: 2, UserId: 5}}
+
+	assert.NotPanics(suite.T(), func() {
+		suite.service.AddAuthorsToComments(comments, context.TODO())
+	}, "Adding authors panics when users-ms is unavailable")
+
+	assert.Nil(suite.T(), comments[0].Author, "Author is not nil")
+	assert.Nil(suite.T(), comments[1].Author, "Author is not nil")
+	assert.Equal(suite.T(), uint(5), comments[1].UserId, "User id is changed")
+}
@@ -2,23 +2,20 @@ package service
 
 import (
 	"context"
-	"fmt"
 	"posts-ms/src/client"
 	"posts-ms/src/dto/request"
 	"posts-ms/src/dto/response"
 	"posts-ms/src/entity"
-	"posts-ms/src/rabbitmq"
 	"posts-ms/src/repository"
 
 	"github.com/opentracing/opentracing-go"
 	"github.com/sirupsen/logrus"
-	"github.com/streadway/amqp"
 )
 
 type ICommentService interface {
 	Create(request.CommentDto, context.Context) (*response.CommentDto, error)
-	Delete(uint, context.Context)
-	GetAllByPostId(uint, context.Context) []*response.CommentDto
+	Delete(uint, context.Context) error
+	GetAllByPostId(uint, uint, context.Context) []*response.CommentDto
 }
 
 type CommentService struct {
@@ -26,19 +23,21 @@ type CommentService struct {
 	Logger            *logrus.Entry
 	PostService       IPostService
 	UserRESTClient    client.IUserRESTClient
-	RabbitMQChannel   *amqp.Channel
+	Notifications     INotificationAggregator
+	Moderator         IModerator
 }
 
-func (s CommentService) GetAllByPostId(id uint, ctx context.Context) []*response.CommentDto {
+// GetAllByPostId returns the comments on a post that viewerId may see.
+func (s CommentService) GetAllByPostId(id uint, viewerId uint, ctx context.Context) []*response.CommentDto {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "Service - Get all comments for specific post")
 
 	defer span.Finish()
 
-	s.Logger.Info("Getting comments for post")
+	s.Logger.WithContext(ctx).Info("Getting comments for post")
 
 	comments := s.CommentRepository.GetAllByPostId(id, ctx)
 
-	return transformListOfDAOToListOfDTO(comments)
+	return transformListOfDAOToListOfDTO(comments, viewerId)
 }
 
 func (s CommentService) Create(dto request.CommentDto, ctx context.Context) (*response.CommentDto, error) {
@@ -46,33 +45,69 @@ func (s CommentService) Create(dto request.CommentDto, ctx context.Context) (*re
 
 	defer span.Finish()
 
-	s.Logger.Info("Creating comment")
+	s.Logger.WithContext(ctx).Info("Creating comment")
+
+	post, err := s.PostService.GetPostById(dto.PostId, ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !post.VisibleTo(dto.UserId) {
+		return nil, NewNotFoundError("post %d does not exist", dto.PostId)
+	}
+
+	decision, err := moderate(s.Moderator, ModerationContent{Entity: request.CommentEntity, AuthorId: dto.UserId, Text: dto.Content}, s.Logger, ctx)
+
+	if err != nil {
+		return nil, err
+	}
 
 	comment := entity.CreateComment(dto)
 
-	post, _ := s.PostService.GetPostById(dto.PostId, ctx)
+	comment.SetModeration(decision.Status(), decision.Reason)
 
 	newComment, err := s.CommentRepository.Create(comment, ctx)
 
-	s.AddNotification(int(dto.UserId), int(post.UserId), ctx)
+	if err != nil {
+		return nil, lookupError(err, "post %d does not exist", dto.PostId)
+	}
+
+	countCreated(request.CommentEntity)
+
+	if newComment.ModerationStatus != entity.ModerationHeld {
+		s.AddNotification(int(dto.UserId), int(post.UserId), dto.PostId, ctx)
+	}
 
-	return newComment.CreateDto(), err
+	return newComment.CreateDto(), nil
 }
 
-func (s CommentService) Delete(id uint, ctx context.Context) {
+func (s CommentService) Delete(id uint, ctx context.Context) error {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "Service - Delete comment by id")
 
 	defer span.Finish()
 
-	s.Logger.Info("Deleting comment")
+	s.Logger.WithContext(ctx).Info("Deleting comment")
 
-	s.CommentRepository.Delete(id, ctx)
+	if err := s.CommentRepository.Delete(id, ctx); err != nil {
+		return lookupError(err, "comment %d does not exist", id)
+	}
+
+	countDeleted(request.CommentEntity)
+
+	return nil
 }
 
-func transformListOfDAOToListOfDTO(comments []*entity.Comment) []*response.CommentDto {
+// transformListOfDAOToListOfDTO leaves out the comments that are held by
+// moderation, unless viewerId wrote them.
+func transformListOfDAOToListOfDTO(comments []*entity.Comment, viewerId uint) []*response.CommentDto {
 	var commentsDto = []*response.CommentDto{}
 
 	for _, value := range comments {
+		if !value.VisibleTo(viewerId) {
+			continue
+		}
+
 		commentDto := value.CreateDto()
 		commentsDto = append(commentsDto, commentDto)
 	}
@@ -80,16 +115,59 @@ func transformListOfDAOToListOfDTO(comments []*entity.Comment) []*response.Comme
 	return commentsDto
 }
 
-func (s CommentService) AddNotification(fromId int, toId int, ctx context.Context) {
-	span, _ := opentracing.StartSpanFromContext(ctx, "Service - Notify user about new comment")
+// visibleComments leaves out the comments that are held by moderation,
+// unless viewerId wrote them.
+func visibleComments(comments []entity.Comment, viewerId uint) []entity.Comment {
+	visible := []entity.Comment{}
+
+	for _, comment := range comments {
+		if comment.VisibleTo(viewerId) {
+			visible = append(visible, comment)
+		}
+	}
+
+	return visible
+}
+
+// AddNotification tells the owner of the post about the comment, unless
+// they commented on their own post or turned comment notifications off.
+func (s CommentService) AddNotification(fromId int, toId int, postId uint, ctx context.Context) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Service - Notify user about new comment")
 
 	defer span.Finish()
 
-	userFrom, _ := s.UserRESTClient.GetUser(fromId, ctx)
-	userTo, _ := s.UserRESTClient.GetUser(toId, ctx)
+	if fromId == toId {
+		return
+	}
+
+	userTo, err := s.UserRESTClient.GetUser(toId, ctx)
+
+	if err != nil {
+		s.Logger.WithContext(ctx).WithError(err).Error("Error occured in fetching owner of post, notification is not sent")
 
-	messageType := request.Comment
-	notification := request.NotificationDTO{Message: fmt.Sprintf("%s commented on your post.", userFrom.Username), UserAuth0ID: userTo.Auth0ID, NotificationType: &messageType}
+		return
+	}
+
+	if !notificationsEnabled(userTo.CommentNotifications) {
+		s.Logger.WithContext(ctx).Debug("Owner of post turned comment notifications off, notification is not sent")
+
+		return
+	}
+
+	userFrom, err := s.UserRESTClient.GetUser(fromId, ctx)
+
+	if err != nil {
+		s.Logger.WithContext(ctx).WithError(err).Error("Error occured in fetching user who commented on post, notification is not sent")
+
+		return
+	}
 
-	rabbitmq.AddNotification(&notification, s.RabbitMQChannel, ctx)
+	s.Notifications.Add(PendingNotification{
+		RecipientAuth0ID: userTo.Auth0ID,
+		RecipientLocale:  userTo.Locale,
+		ActorId:          uint(fromId),
+		ActorName:        userFrom.Username,
+		PostId:           postId,
+		Reaction:         CommentedReaction,
+	}, ctx)
 }
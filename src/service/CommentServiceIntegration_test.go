@@ -7,6 +7,7 @@ import (
 	"posts-ms/src/client"
 	"posts-ms/src/dto/request"
 	"posts-ms/src/entity"
+	"posts-ms/src/migration"
 	"posts-ms/src/rabbitmq"
 	"posts-ms/src/repository"
 	"posts-ms/src/utils"
@@ -45,16 +46,16 @@ func (suite *CommentServiceIntegrationTestSuite) SetupSuite() {
 
 	db, _ := gorm.Open(postgres.Open(connectionString), &gorm.Config{})
 
-	db.AutoMigrate(&entity.Comment{Tbl: "comments"})
-	db.AutoMigrate(&entity.Like{Tbl: "likes"})
-	db.AutoMigrate(&entity.Post{Tbl: "posts"})
+	migrator, _ := migration.NewMigrator(db, utils.Logger())
+
+	migrator.Up(context.Background())
 
 	commentRepository := repository.CommentRepository{Database: db}
 	postrepository := repository.PostRepository{Database: db}
 
 	postService := PostService{PostRepository: postrepository, Logger: utils.Logger()}
 
-	userRESTClient := client.UserRESTClient{}
+	userRESTClient := client.NewUserRESTClient(fmt.Sprintf("http://%s", os.Getenv("USER_SERVICE_DOMAIN")), 2*time.Second)
 
 	suite.db = db
 
@@ -66,11 +67,14 @@ func (suite *CommentServiceIntegrationTestSuite) SetupSuite() {
 
 	channel, _ := rabbit.StartRabbitMQ()
 
+	templates, _ := LoadNotificationTemplates("en")
+
 	suite.service = CommentService{
 		PostService:       postService,
 		UserRESTClient:    userRESTClient,
-		RabbitMQChannel:   channel,
+		Notifications:     NewNotificationAggregator(rabbitmq.NotificationPublisher{Channel: channel}, templates, NotificationAggregatorConfig{Window: time.Second, DedupeWindow: time.Minute}, utils.Logger()),
 		CommentRepository: commentRepository,
+		Moderator:         ModerationPipeline{},
 		Logger:            utils.Logger(),
 	}
 
@@ -151,7 +155,7 @@ func TestCommentServiceIntegrationTestSuite(t *testing.T) {
 func (suite *CommentServiceIntegrationTestSuite) TestIntegrationCommentService_GetAllByPostId_PostDoesNotExist() {
 	id := uint(10)
 
-	comments := suite.service.GetAllByPostId(id, context.TODO())
+	comments := suite.service.GetAllByPostId(id, 0, context.TODO())
 
 	assert.Equal(suite.T(), 0, len(comments))
 }
@@ -159,7 +163,7 @@ func (suite *CommentServiceIntegrationTestSuite) TestIntegrationCommentService_G
 func (suite *CommentServiceIntegrationTestSuite) TestIntegrationCommentService_GetAllByPostId_PostDoesExist() {
 	id := uint(100)
 
-	comments := suite.service.GetAllByPostId(id, context.TODO())
+	comments := suite.service.GetAllByPostId(id, 0, context.TODO())
 
 	assert.GreaterOrEqual(suite.T(), len(comments), 2)
 }
@@ -178,7 +182,7 @@ func (suite *CommentServiceIntegrationTestSuite) TestIntegrationCommentService_D
 
 	suite.service.Delete(commentId, context.TODO())
 
-	comments := suite.service.GetAllByPostId(postId, context.TODO())
+	comments := suite.service.GetAllByPostId(postId, 0, context.TODO())
 
 	assert.Equal(suite.T(), 0, len(comments))
 }
@@ -194,7 +198,7 @@ func (suite *CommentServiceIntegrationTestSuite) TestIntegrationCommentService_C
 
 	comment, err := suite.service.Create(commentDto, context.TODO())
 
-	comments := suite.service.GetAllByPostId(id, context.TODO())
+	comments := suite.service.GetAllByPostId(id, 0, context.TODO())
 
 	assert.Equal(suite.T(), len(comments), 3)
 	assert.Nil(suite.T(), err)
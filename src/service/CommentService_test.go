@@ -2,10 +2,14 @@ package service
 
 import (
 	"context"
+	"errors"
+	"posts-ms/src/client"
+	"posts-ms/src/dto/request"
 	"posts-ms/src/repository"
 	"posts-ms/src/utils"
 	"testing"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 )
@@ -13,6 +17,9 @@ import (
 type CommentServiceUnitTestSuite struct {
 	suite.Suite
 	commentRepositoryMock *repository.CommentRepositoryMock
+	postServiceMock       *PostServiceMock
+	userRestClientMock    *client.UserRESTClientMock
+	notificationsMock     *NotificationAggregatorMock
 	service               CommentService
 }
 
@@ -22,8 +29,15 @@ func TestCommentServiceUnitTestSuite(t *testing.T) {
 
 func (suite *CommentServiceUnitTestSuite) SetupSuite() {
 	suite.commentRepositoryMock = new(repository.CommentRepositoryMock)
+	suite.postServiceMock = new(PostServiceMock)
+	suite.userRestClientMock = new(client.UserRESTClientMock)
+	suite.notificationsMock = new(NotificationAggregatorMock)
 
-	suite.service = CommentService{CommentRepository: suite.commentRepositoryMock, Logger: utils.Logger()}
+	suite.service = CommentService{CommentRepository: suite.commentRepositoryMock, PostService: suite.postServiceMock, UserRESTClient: suite.userRestClientMock, Notifications: suite.notificationsMock, Moderator: testModerator(), Logger: utils.Logger()}
+}
+
+func (suite *CommentServiceUnitTestSuite) SetupTest() {
+	suite.notificationsMock.Added = nil
 }
 
 func (suite *CommentServiceUnitTestSuite) TestNewCommentService() {
@@ -31,21 +45,113 @@ func (suite *CommentServiceUnitTestSuite) TestNewCommentService() {
 }
 
 func (suite *CommentServiceUnitTestSuite) TestCommentService_GetAllByPostId_ReturnsEmptyList() {
-	comments := suite.service.GetAllByPostId(1, context.TODO())
+	comments := suite.service.GetAllByPostId(1, 0, context.TODO())
 
 	assert.NotNil(suite.T(), comments, "Comments are nil")
 	assert.Equal(suite.T(), 0, len(comments), "Length of comments is not 0")
 }
 
 func (suite *CommentServiceUnitTestSuite) TestCommentService_GetAllByPostId_ReturnsListOfComments() {
-	comments := suite.service.GetAllByPostId(2, context.TODO())
+	comments := suite.service.GetAllByPostId(2, 0, context.TODO())
 
 	assert.NotNil(suite.T(), comments, "Comments are nil")
 	assert.Equal(suite.T(), 2, len(comments), "Length of comments is not 2")
 }
 
 func (suite *CommentServiceUnitTestSuite) TestCommentService_Delete_CommentNotExist() {
+	err := suite.service.Delete(2, context.TODO())
+
+	assert.True(suite.T(), errors.Is(err, ErrNotFound), "Error is not not found")
+}
+
+func (suite *CommentServiceUnitTestSuite) TestCommentService_Delete_ReturnsNil() {
+	err := suite.service.Delete(1, context.TODO())
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+}
+
+func (suite *CommentServiceUnitTestSuite) TestCommentService_Delete_CountsOnlyDeletedComments() {
+	deleted := contentChanges.WithLabelValues(request.CommentEntity, request.DeletedAction)
+	before := testutil.ToFloat64(deleted)
+
+	suite.service.Delete(1, context.TODO())
 	suite.service.Delete(2, context.TODO())
 
-	assert.True(suite.T(), true, "")
+	assert.Equal(suite.T(), before+1, testutil.ToFloat64(deleted), "Deleted comments are not counted once")
+}
+
+func (suite *CommentServiceUnitTestSuite) TestCommentService_GetAllByPostId_HidesHeldCommentsFromOthers() {
+	assert.Equal(suite.T(), 1, len(suite.service.GetAllByPostId(3, 0, context.TODO())), "Held comment is shown to anonymous viewer")
+	assert.Equal(suite.T(), 1, len(suite.service.GetAllByPostId(3, 2, context.TODO())), "Held comment is shown to another user")
+	assert.Equal(suite.T(), 2, len(suite.service.GetAllByPostId(3, 5, context.TODO())), "Held comment is hidden from its author")
+}
+
+func (suite *CommentServiceUnitTestSuite) TestCommentService_Create_HeldByModeration_NotificationSkipped() {
+	comment, err := suite.service.Create(request.CommentDto{PostId: 2, UserId: 2, Content: "Total spam"}, context.TODO())
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+	assert.Equal(suite.T(), "held", comment.ModerationStatus, "Comment is not held")
+	assert.Empty(suite.T(), suite.notificationsMock.Added, "Notification about held comment is added")
+}
+
+func (suite *CommentServiceUnitTestSuite) TestCommentService_Create_RejectedByModeration() {
+	comment, err := suite.service.Create(request.CommentDto{PostId: 2, UserId: 2, Content: "Forbidden"}, context.TODO())
+
+	assert.Nil(suite.T(), comment, "Comment is not nil")
+	assert.True(suite.T(), errors.Is(err, ErrContentRejected), "Error is not content rejected")
+}
+
+func (suite *CommentServiceUnitTestSuite) TestCommentService_Create_HeldPost_OnlyAuthorCanComment() {
+	_, err := suite.service.Create(request.CommentDto{PostId: 4, UserId: 2, Content: "Some text"}, context.TODO())
+
+	assert.True(suite.T(), errors.Is(err, ErrNotFound), "Held post is found by another user")
+
+	comment, err := suite.service.Create(request.CommentDto{PostId: 4, UserId: 1, Content: "Some text"}, context.TODO())
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+	assert.Equal(suite.T(), "approved", comment.ModerationStatus, "Comment is not approved")
+}
+
+func (suite *CommentServiceUnitTestSuite) TestCommentService_Create_PostDoesNotExist_ReturnsNotFound() {
+	comment, err := suite.service.Create(request.CommentDto{PostId: 1, UserId: 2, Content: "Some text"}, context.TODO())
+
+	assert.True(suite.T(), errors.Is(err, ErrNotFound), "Error is not not found")
+	assert.Nil(suite.T(), comment, "Comment is not nil")
+}
+
+func (suite *CommentServiceUnitTestSuite) TestCommentService_AddNotification_UserServiceUnavailable_NotificationSkipped() {
+	assert.NotPanics(suite.T(), func() {
+		suite.service.AddNotification(503, 1, 3, context.TODO())
+	}, "Notification panics when users-ms is unavailable")
+	assert.Empty(suite.T(), suite.notificationsMock.Added, "Notification is added")
+}
+
+func (suite *CommentServiceUnitTestSuite) TestCommentService_AddNotification_AddsToAggregator() {
+	suite.service.AddNotification(2, 1, 3, context.TODO())
+
+	assert.Equal(suite.T(), []PendingNotification{{RecipientAuth0ID: "1", ActorId: 2, ActorName: "Username", PostId: 3, Reaction: CommentedReaction}}, suite.notificationsMock.Added, "Notification is not added")
+}
+
+func (suite *CommentServiceUnitTestSuite) TestCommentService_AddNotification_OwnPost_NotificationSkipped() {
+	suite.service.AddNotification(23, 23, 3, context.TODO())
+
+	assert.Empty(suite.T(), suite.notificationsMock.Added, "Notification about own post is added")
+}
+
+func (suite *CommentServiceUnitTestSuite) TestCommentService_AddNotification_CommentNotificationsOff_NotificationSkipped() {
+	suite.service.AddNotification(2, 22, 3, context.TODO())
+
+	assert.Empty(suite.T(), suite.notificationsMock.Added, "Notification is added when comment notifications are off")
+}
+
+func (suite *CommentServiceUnitTestSuite) TestCommentService_AddNotification_OnlyLikeNotificationsOff_AddsToAggregator() {
+	suite.service.AddNotification(2, 21, 3, context.TODO())
+
+	assert.Equal(suite.T(), []PendingNotification{{RecipientAuth0ID: "21", ActorId: 2, ActorName: "Username", PostId: 3, Reaction: CommentedReaction}}, suite.notificationsMock.Added, "Notification is not added")
+}
+
+func (suite *CommentServiceUnitTestSuite) TestCommentService_AddNotification_CommentNotificationsOn_AddsToAggregator() {
+	suite.service.AddNotification(2, 23, 3, context.TODO())
+
+	assert.Equal(suite.T(), []PendingNotification{{RecipientAuth0ID: "23", ActorId: 2, ActorName: "Username", PostId: 3, Reaction: CommentedReaction}}, suite.notificationsMock.Added, "Notification is not added")
 }
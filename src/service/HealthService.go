@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"posts-ms/src/dto/response"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	HealthUp       = "up"
+	HealthDegraded = "degraded"
+	HealthDown     = "down"
+)
+
+type IHealthService interface {
+	Live() response.HealthDto
+	Ready(context.Context) (response.HealthDto, bool)
+}
+
+// HealthCheck probes one dependency. Only critical checks make the service
+// unready, the others are reported so that a degraded dependency is visible.
+type HealthCheck struct {
+	Name     string
+	Critical bool
+	Check    func(context.Context) error
+}
+
+type HealthService struct {
+	Checks  []HealthCheck
+	Timeout time.Duration
+	Logger  *logrus.Entry
+}
+
+func (s HealthService) Live() response.HealthDto {
+	return response.HealthDto{Status: HealthUp}
+}
+
+// Ready runs every check concurrently, each bounded by Timeout, and reports
+// whether all critical dependencies are up.
+func (s HealthService) Ready(ctx context.Context) (response.HealthDto, bool) {
+	results := make([]response.DependencyHealthDto, len(s.Checks))
+
+	var wg sync.WaitGroup
+
+	for i, check := range s.Checks {
+		wg.Add(1)
+
+		go func(i int, check HealthCheck) {
+			defer wg.Done()
+
+			results[i] = s.run(check, ctx)
+		}(i, check)
+	}
+
+	wg.Wait()
+
+	health := response.HealthDto{
+		Status: HealthUp,
+		Checks: map[string]response.DependencyHealthDto{},
+	}
+
+	ready := true
+
+	for i, check := range s.Checks {
+		result := results[i]
+
+		health.Checks[check.Name] = result
+
+		if result.Status == HealthUp {
+			continue
+		}
+
+		if check.Critical {
+			ready = false
+			health.Status = HealthDown
+		} else if ready {
+			health.Status = HealthDegraded
+		}
+	}
+
+	return health, ready
+}
+
+func (s HealthService) run(check HealthCheck, ctx context.Context) response.DependencyHealthDto {
+	ctx, cancel := context.WithTimeout(ctx, s.Timeout)
+
+	defer cancel()
+
+	start := time.Now()
+
+	err := check.Check(ctx)
+
+	result := response.DependencyHealthDto{
+		Status:    HealthUp,
+		Critical:  check.Critical,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+
+	if err != nil {
+		s.Logger.WithContext(ctx).WithError(err).Errorf("Health check of %s failed", check.Name)
+
+		result.Status = HealthDown
+		result.Error = err.Error()
+	}
+
+	return result
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"posts-ms/src/utils"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type HealthServiceUnitTestSuite struct {
+	suite.Suite
+}
+
+func TestHealthServiceUnitTestSuite(t *testing.T) {
+	suite.Run(t, new(HealthServiceUnitTestSuite))
+}
+
+func healthyCheck(context.Context) error {
+	return nil
+}
+
+func unhealthyCheck(context.Context) error {
+	return errors.New("connection refused")
+}
+
+func (suite *HealthServiceUnitTestSuite) newService(checks ...HealthCheck) HealthService {
+	return HealthService{Checks: checks, Timeout: time.Second, Logger: utils.Logger()}
+}
+
+func (suite *HealthServiceUnitTestSuite) TestHealthService_Live_ReturnsUp() {
+	health := suite.newService(HealthCheck{Name: "database", Critical: true, Check: unhealthyCheck}).Live()
+
+	assert.Equal(suite.T(), HealthUp, health.Status, "Status is not up")
+	assert.Empty(suite.T(), health.Checks, "Liveness runs dependency checks")
+}
+
+func (suite *HealthServiceUnitTestSuite) TestHealthService_Ready_AllUp() {
+	health, ready := suite.newService(
+		HealthCheck{Name: "database", Critical: true, Check: healthyCheck},
+		HealthCheck{Name: "rabbitmq", Critical: true, Check: healthyCheck},
+	).Ready(context.TODO())
+
+	assert.True(suite.T(), ready, "Service is not ready")
+	assert.Equal(suite.T(), HealthUp, health.Status, "Status is not up")
+	assert.Equal(suite.T(), 2, len(health.Checks), "Length of checks not 2")
+	assert.Equal(suite.T(), HealthUp, health.Checks["database"].Status, "Database is not up")
+}
+
+func (suite *HealthServiceUnitTestSuite) TestHealthService_Ready_CriticalDown() {
+	health, ready := suite.newService(
+		HealthCheck{Name: "database", Critical: true, Check: healthyCheck},
+		HealthCheck{Name: "rabbitmq", Critical: true, Check: unhealthyCheck},
+	).Ready(context.TODO())
+
+	assert.False(suite.T(), ready, "Service is ready")
+	assert.Equal(suite.T(), HealthDown, health.Status, "Status is not down")
+	assert.Equal(suite.T(), HealthDown, health.Checks["rabbitmq"].Status, "RabbitMQ is not down")
+	assert.Equal(suite.T(), "connection refused", health.Checks["rabbitmq"].Error, "Error is not reported")
+}
+
+func (suite *HealthServiceUnitTestSuite) TestHealthService_Ready_OptionalDown() {
+	health, ready := suite.newService(
+		HealthCheck{Name: "database", Critical: true, Check: healthyCheck},
+		HealthCheck{Name: "users-ms", Check: unhealthyCheck},
+	).Ready(context.TODO())
+
+	assert.True(suite.T(), ready, "Service is not ready")
+	assert.Equal(suite.T(), HealthDegraded, health.Status, "Status is not degraded")
+	assert.False(suite.T(), health.Checks["users-ms"].Critical, "Users-ms is critical")
+}
+
+func (suite *HealthServiceUnitTestSuite) TestHealthService_Ready_CheckTimesOut() {
+	service := suite.newService(HealthCheck{Name: "database", Critical: true, Check: func(ctx context.Context) error {
+		<-ctx.Done()
+
+		return ctx.Err()
+	}})
+	service.Timeout = 10 * time.Millisecond
+
+	health, ready := service.Ready(context.TODO())
+
+	assert.False(suite.T(), ready, "Service is ready")
+	assert.Equal(suite.T(), context.DeadlineExceeded.Error(), health.Checks["database"].Error, "Timeout is not reported")
+}
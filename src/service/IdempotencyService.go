@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"posts-ms/src/entity"
+	"posts-ms/src/repository"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	ErrIdempotencyKeyInProgress = errors.New("request with the same idempotency key is still in progress")
+	ErrIdempotencyKeyReused     = errors.New("idempotency key was already used for a different request")
+)
+
+type IIdempotencyService interface {
+	Begin(string, string, context.Context) (*entity.IdempotencyKey, error)
+	Complete(string, int, string, []byte, context.Context) error
+	Release(string, context.Context) error
+	DeleteExpired(context.Context) error
+}
+
+// IdempotencyService keeps keys for TTL. A request in progress holds its key
+// for LockTimeout, after that the request is presumed dead, for example
+// because the instance crashed, and a retry may take the key over.
+type IdempotencyService struct {
+	IdempotencyKeyRepository repository.IIdempotencyKeyRepository
+	TTL                      time.Duration
+	LockTimeout              time.Duration
+	Logger                   *logrus.Entry
+}
+
+// Begin reserves the key for a new request. It returns the stored key when
+// the request was already completed, so its response can be replayed.
+func (s IdempotencyService) Begin(key string, requestHash string, ctx context.Context) (*entity.IdempotencyKey, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Service - Begin idempotent request")
+
+	defer span.Finish()
+
+	now := time.Now()
+
+	created, err := s.reserve(key, requestHash, now, ctx)
+
+	if err != nil || created {
+		return nil, err
+	}
+
+	existing, err := s.IdempotencyKeyRepository.GetByKey(key, ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if existing.IsExpired(now) {
+		s.Logger.WithContext(ctx).Info("Idempotency key expired, reserving it again")
+
+		if err := s.IdempotencyKeyRepository.Delete(key, ctx); err != nil {
+			return nil, err
+		}
+
+		created, err := s.reserve(key, requestHash, now, ctx)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if !created {
+			return nil, ErrIdempotencyKeyInProgress
+		}
+
+		return nil, nil
+	}
+
+	if existing.RequestHash != requestHash {
+		return nil, ErrIdempotencyKeyReused
+	}
+
+	if existing.IsLockExpired(now) {
+		takenOver, err := s.IdempotencyKeyRepository.TakeOver(key, requestHash, now, now.Add(s.LockTimeout), ctx)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if takenOver {
+			s.Logger.WithContext(ctx).Warn("Request holding idempotency key did not finish, taking the key over")
+
+			return nil, nil
+		}
+	}
+
+	if !existing.Completed {
+		return nil, ErrIdempotencyKeyInProgress
+	}
+
+	s.Logger.WithContext(ctx).Info("Replaying response for idempotency key")
+
+	return existing, nil
+}
+
+func (s IdempotencyService) Complete(key string, statusCode int, contentType string, body []byte, ctx context.Context) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Service - Complete idempotent request")
+
+	defer span.Finish()
+
+	existing, err := s.IdempotencyKeyRepository.GetByKey(key, ctx)
+
+	if err != nil {
+		return err
+	}
+
+	existing.Completed = true
+	existing.StatusCode = statusCode
+	existing.ContentType = contentType
+	existing.Body = body
+
+	return s.IdempotencyKeyRepository.Update(*existing, ctx)
+}
+
+func (s IdempotencyService) Release(key string, ctx context.Context) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Service - Release idempotency key")
+
+	defer span.Finish()
+
+	return s.IdempotencyKeyRepository.Delete(key, ctx)
+}
+
+func (s IdempotencyService) DeleteExpired(ctx context.Context) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Service - Delete expired idempotency keys")
+
+	defer span.Finish()
+
+	s.Logger.WithContext(ctx).Info("Deleting expired idempotency keys")
+
+	return s.IdempotencyKeyRepository.DeleteExpired(time.Now(), ctx)
+}
+
+func (s IdempotencyService) reserve(key string, requestHash string, now time.Time, ctx context.Context) (bool, error) {
+	return s.IdempotencyKeyRepository.CreateIfNotExists(entity.IdempotencyKey{
+		Key:         key,
+		RequestHash: requestHash,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(s.TTL),
+		LockedUntil: now.Add(s.LockTimeout),
+	}, ctx)
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"posts-ms/src/repository"
+	"posts-ms/src/utils"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type IdempotencyServiceUnitTestSuite struct {
+	suite.Suite
+	idempotencyKeyRepositoryMock *repository.IdempotencyKeyRepositoryMock
+	service                      IdempotencyService
+}
+
+func TestIdempotencyServiceUnitTestSuite(t *testing.T) {
+	suite.Run(t, new(IdempotencyServiceUnitTestSuite))
+}
+
+func (suite *IdempotencyServiceUnitTestSuite) SetupSuite() {
+	suite.idempotencyKeyRepositoryMock = new(repository.IdempotencyKeyRepositoryMock)
+
+	suite.service = IdempotencyService{
+		IdempotencyKeyRepository: suite.idempotencyKeyRepositoryMock,
+		TTL:                      time.Hour,
+		LockTimeout:              time.Minute,
+		Logger:                   utils.Logger(),
+	}
+}
+
+func (suite *IdempotencyServiceUnitTestSuite) TestIdempotencyService_Begin_NewKey() {
+	stored, err := suite.service.Begin("new", "hash", context.TODO())
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+	assert.Nil(suite.T(), stored, "Stored response is not nil")
+}
+
+func (suite *IdempotencyServiceUnitTestSuite) TestIdempotencyService_Begin_CompletedKeyReturnsStoredResponse() {
+	stored, err := suite.service.Begin("completed", "hash", context.TODO())
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+	assert.NotNil(suite.T(), stored, "Stored response is nil")
+	assert.Equal(suite.T(), 201, stored.StatusCode, "Status code is not 201")
+}
+
+func (suite *IdempotencyServiceUnitTestSuite) TestIdempotencyService_Begin_CompletedKeyWithDifferentRequest() {
+	stored, err := suite.service.Begin("completed", "other-hash", context.TODO())
+
+	assert.Equal(suite.T(), ErrIdempotencyKeyReused, err, "Error is not key reused")
+	assert.Nil(suite.T(), stored, "Stored response is not nil")
+}
+
+func (suite *IdempotencyServiceUnitTestSuite) TestIdempotencyService_Begin_StaleKeyIsTakenOver() {
+	stored, err := suite.service.Begin("stale", "hash", context.TODO())
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+	assert.Nil(suite.T(), stored, "Stored response is not nil")
+}
+
+func (suite *IdempotencyServiceUnitTestSuite) TestIdempotencyService_Begin_StaleKeyTakenOverByOtherRequest() {
+	_, err := suite.service.Begin("stale-taken", "hash", context.TODO())
+
+	assert.Equal(suite.T(), ErrIdempotencyKeyInProgress, err, "Error is not key in progress")
+}
+
+func (suite *IdempotencyServiceUnitTestSuite) TestIdempotencyService_Begin_StaleKeyWithDifferentRequest() {
+	_, err := suite.service.Begin("stale", "other-hash", context.TODO())
+
+	assert.Equal(suite.T(), ErrIdempotencyKeyReused, err, "Error is not key reused")
+}
+
+func (suite *IdempotencyServiceUnitTestSuite) TestIdempotencyService_Begin_PendingKey() {
+	stored, err := suite.service.Begin("pending", "hash", context.TODO())
+
+	assert.Equal(suite.T(), ErrIdempotencyKeyInProgress, err, "Error is not key in progress")
+	assert.Nil(suite.T(), stored, "Stored response is not nil")
+}
+
+func (suite *IdempotencyServiceUnitTestSuite) TestIdempotencyService_Begin_RepositoryError() {
+	stored, err := suite.service.Begin("broken", "hash", context.TODO())
+
+	assert.NotNil(suite.T(), err, "Error is nil")
+	assert.Nil(suite.T(), stored, "Stored response is not nil")
+}
+
+func (suite *IdempotencyServiceUnitTestSuite) TestIdempotencyService_Complete_KeyDoesNotExist() {
+	err := suite.service.Complete("missing", 201, "application/json", []byte(`{}`), context.TODO())
+
+	assert.NotNil(suite.T(), err, "Error is nil")
+}
+
+func (suite *IdempotencyServiceUnitTestSuite) TestIdempotencyService_Complete_PendingKey() {
+	err := suite.service.Complete("pending", 201, "application/json", []byte(`{}`), context.TODO())
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+}
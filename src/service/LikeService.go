@@ -2,35 +2,33 @@ package service
 
 import (
 	"context"
-	"fmt"
+	"errors"
 	"posts-ms/src/client"
 	"posts-ms/src/dto/request"
 	"posts-ms/src/dto/response"
 	"posts-ms/src/entity"
-	"posts-ms/src/rabbitmq"
 	"posts-ms/src/repository"
 
 	"github.com/opentracing/opentracing-go"
 	"github.com/sirupsen/logrus"
-	"github.com/streadway/amqp"
 )
 
 type ILikeService interface {
 	Create(request.LikeDto, context.Context) (*response.LikeDto, error)
-	Delete(uint, uint, context.Context)
+	Delete(uint, uint, context.Context) error
 	GetAllByPostId(uint, context.Context) []*response.LikeDto
 }
 
 type LikeService struct {
-	LikeRepository  repository.ILikeRepository
-	PostService     IPostService
-	Logger          *logrus.Entry
-	UserRESTClient  client.IUserRESTClient
-	RabbitMQChannel *amqp.Channel
+	LikeRepository repository.ILikeRepository
+	PostService    IPostService
+	Logger         *logrus.Entry
+	UserRESTClient client.IUserRESTClient
+	Notifications  INotificationAggregator
 }
 
 func (s LikeService) GetAllByPostId(id uint, ctx context.Context) []*response.LikeDto {
-	s.Logger.Info("Getting likes for post")
+	s.Logger.WithContext(ctx).Info("Getting likes for post")
 
 	span, ctx := opentracing.StartSpanFromContext(ctx, "Service - Get all likes for specific post")
 
@@ -46,19 +44,39 @@ func (s LikeService) Create(dto request.LikeDto, ctx context.Context) (*response
 
 	defer span.Finish()
 
-	s.Logger.Info("Creating like")
+	s.Logger.WithContext(ctx).Info("Creating like")
+
+	post, error := s.PostService.GetPostById(dto.PostId, ctx)
+
+	if error != nil {
+		return nil, error
+	}
+
+	if !post.VisibleTo(dto.UserId) {
+		return nil, NewNotFoundError("post %d does not exist", dto.PostId)
+	}
 
 	like, error := s.LikeRepository.GetByUserIdAndPostId(dto.UserId, dto.PostId, ctx)
 
+	created := error != nil
+
 	if error == nil {
 		like.LikeType = entity.TypeOfLike(dto.LikeType)
 	} else {
 		like = entity.CreateLike(dto)
 	}
 
-	newLike, _ := s.LikeRepository.Create(like, ctx)
+	newLike, error := s.LikeRepository.Create(like, ctx)
 
-	post, error := s.PostService.GetPostById(dto.PostId, ctx)
+	if errors.Is(error, repository.ErrDuplicateLike) {
+		return nil, NewConflictError("user %d already liked post %d", dto.UserId, dto.PostId)
+	}
+
+	if error != nil {
+		return nil, lookupError(error, "post %d does not exist", dto.PostId)
+	}
+
+	post, error = s.PostService.GetPostById(dto.PostId, ctx)
 
 	if error != nil {
 		return nil, error
@@ -78,32 +96,40 @@ func (s LikeService) Create(dto request.LikeDto, ctx context.Context) (*response
 	post.TotalLikes = totalPositive
 	post.TotalUnlikes = totalNegative
 
-	s.PostService.CreatePost(*post, ctx)
+	if _, error := s.PostService.CreatePost(*post, ctx); error != nil {
+		return nil, error
+	}
+
+	if created {
+		countCreated(request.LikeEntity)
+	}
 
-	s.AddNotification(int(dto.UserId), int(post.UserId), dto.LikeType, ctx)
+	s.AddNotification(int(dto.UserId), int(post.UserId), dto.PostId, dto.LikeType, ctx)
 
-	return newLike.CreateDto(), error
+	return newLike.CreateDto(), nil
 }
 
-func (s LikeService) Delete(userId uint, postId uint, ctx context.Context) {
+func (s LikeService) Delete(userId uint, postId uint, ctx context.Context) error {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "Service - Delete like for specific post from specific user")
 
 	defer span.Finish()
 
-	s.Logger.Info("Deleting like")
+	s.Logger.WithContext(ctx).Info("Deleting like")
 
 	like, error := s.LikeRepository.GetByUserIdAndPostId(userId, postId, ctx)
 
 	if error != nil {
-		return
+		return lookupError(error, "user %d has not liked post %d", userId, postId)
 	}
 
-	s.LikeRepository.Delete(like.ID, ctx)
+	if error := s.LikeRepository.Delete(like.ID, ctx); error != nil {
+		return lookupError(error, "user %d has not liked post %d", userId, postId)
+	}
 
 	post, error := s.PostService.GetPostById(postId, ctx)
 
 	if error != nil {
-		return
+		return error
 	}
 
 	if like.LikeType == 1 {
@@ -112,7 +138,11 @@ func (s LikeService) Delete(userId uint, postId uint, ctx context.Context) {
 		post.TotalUnlikes = post.TotalUnlikes - 1
 	}
 
-	s.PostService.CreatePost(*post, ctx)
+	countDeleted(request.LikeEntity)
+
+	_, error = s.PostService.CreatePost(*post, ctx)
+
+	return error
 }
 
 func (s LikeService) transformListOfDAOToListOfDTO(likes []*entity.Like) []*response.LikeDto {
@@ -127,21 +157,51 @@ func (s LikeService) transformListOfDAOToListOfDTO(likes []*entity.Like) []*resp
 	return likesDto
 }
 
-func (s LikeService) AddNotification(fromId int, toId int, likeType int, ctx context.Context) {
+// AddNotification tells the owner of the post about the reaction, unless
+// they reacted to their own post or turned like notifications off.
+func (s LikeService) AddNotification(fromId int, toId int, postId uint, likeType int, ctx context.Context) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "Service - Notify user about new post")
 
 	defer span.Finish()
 
-	userFrom, _ := s.UserRESTClient.GetUser(fromId, ctx)
-	userTo, _ := s.UserRESTClient.GetUser(toId, ctx)
+	if fromId == toId {
+		return
+	}
 
-	var notification request.NotificationDTO
-	messageType := request.Like
-	if likeType == 1 {
-		notification = request.NotificationDTO{Message: fmt.Sprintf("%s liked your post.", userFrom.Username), UserAuth0ID: userTo.Auth0ID, NotificationType: &messageType}
-	} else {
-		notification = request.NotificationDTO{Message: fmt.Sprintf("%s disliked your post.", userFrom.Username), UserAuth0ID: userTo.Auth0ID, NotificationType: &messageType}
+	userTo, err := s.UserRESTClient.GetUser(toId, ctx)
+
+	if err != nil {
+		s.Logger.WithContext(ctx).WithError(err).Error("Error occured in fetching owner of post, notification is not sent")
+
+		return
+	}
+
+	if !notificationsEnabled(userTo.LikeNotifications) {
+		s.Logger.WithContext(ctx).Debug("Owner of post turned like notifications off, notification is not sent")
+
+		return
+	}
+
+	userFrom, err := s.UserRESTClient.GetUser(fromId, ctx)
+
+	if err != nil {
+		s.Logger.WithContext(ctx).WithError(err).Error("Error occured in fetching user who liked post, notification is not sent")
+
+		return
+	}
+
+	reaction := LikedReaction
+
+	if likeType != 1 {
+		reaction = DislikedReaction
 	}
 
-	rabbitmq.AddNotification(&notification, s.RabbitMQChannel, ctx)
+	s.Notifications.Add(PendingNotification{
+		RecipientAuth0ID: userTo.Auth0ID,
+		RecipientLocale:  userTo.Locale,
+		ActorId:          uint(fromId),
+		ActorName:        userFrom.Username,
+		PostId:           postId,
+		Reaction:         reaction,
+	}, ctx)
 }
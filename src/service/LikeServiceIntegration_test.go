@@ -7,6 +7,7 @@ import (
 	"posts-ms/src/client"
 	"posts-ms/src/dto/request"
 	"posts-ms/src/entity"
+	"posts-ms/src/migration"
 	"posts-ms/src/rabbitmq"
 	"posts-ms/src/repository"
 	"posts-ms/src/utils"
@@ -45,14 +46,14 @@ func (suite *LikeServiceIntegrationTestSuite) SetupSuite() {
 
 	db, _ := gorm.Open(postgres.Open(connectionString), &gorm.Config{})
 
-	db.AutoMigrate(&entity.Like{Tbl: "likes"})
-	db.AutoMigrate(&entity.Comment{Tbl: "comments"})
-	db.AutoMigrate(&entity.Post{Tbl: "posts"})
+	migrator, _ := migration.NewMigrator(db, utils.Logger())
+
+	migrator.Up(context.Background())
 
 	likeRepository := repository.LikeRepository{Database: db}
 	postRepository := repository.PostRepository{Database: db}
 
-	userRESTClient := client.UserRESTClient{}
+	userRESTClient := client.NewUserRESTClient(fmt.Sprintf("http://%s", os.Getenv("USER_SERVICE_DOMAIN")), 2*time.Second)
 
 	suite.db = db
 
@@ -64,12 +65,14 @@ func (suite *LikeServiceIntegrationTestSuite) SetupSuite() {
 
 	channel, _ := rabbit.StartRabbitMQ()
 
+	templates, _ := LoadNotificationTemplates("en")
+
 	suite.service = LikeService{
-		PostService:     PostService{PostRepository: postRepository, Logger: utils.Logger()},
-		UserRESTClient:  userRESTClient,
-		RabbitMQChannel: channel,
-		LikeRepository:  likeRepository,
-		Logger:          utils.Logger(),
+		PostService:    PostService{PostRepository: postRepository, Logger: utils.Logger()},
+		UserRESTClient: userRESTClient,
+		Notifications:  NewNotificationAggregator(rabbitmq.NotificationPublisher{Channel: channel}, templates, NotificationAggregatorConfig{Window: time.Second, DedupeWindow: time.Minute}, utils.Logger()),
+		LikeRepository: likeRepository,
+		Logger:         utils.Logger(),
 	}
 
 	suite.posts = []entity.Post{
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"errors"
 	"posts-ms/src/client"
 	"posts-ms/src/dto/request"
 	"posts-ms/src/repository"
@@ -17,6 +18,7 @@ type LikeServiceUnitTestSuite struct {
 	likeRepositoryMock *repository.LikeRepositoryMock
 	postServiceMock    *PostServiceMock
 	userRestClientMock *client.UserRESTClientMock
+	notificationsMock  *NotificationAggregatorMock
 	service            LikeService
 }
 
@@ -28,14 +30,20 @@ func (suite *LikeServiceUnitTestSuite) SetupSuite() {
 	suite.likeRepositoryMock = new(repository.LikeRepositoryMock)
 	suite.postServiceMock = new(PostServiceMock)
 	suite.userRestClientMock = new(client.UserRESTClientMock)
+	suite.notificationsMock = new(NotificationAggregatorMock)
 
 	suite.service = LikeService{LikeRepository: suite.likeRepositoryMock,
 		PostService:    suite.postServiceMock,
 		Logger:         utils.Logger(),
 		UserRESTClient: suite.userRestClientMock,
+		Notifications:  suite.notificationsMock,
 	}
 }
 
+func (suite *LikeServiceUnitTestSuite) SetupTest() {
+	suite.notificationsMock.Added = nil
+}
+
 func (suite *LikeServiceUnitTestSuite) TestNewLikeService() {
 	assert.NotNil(suite.T(), suite.service, "Service is nil")
 }
@@ -58,6 +66,19 @@ func (suite *LikeServiceUnitTestSuite) TestLikeService_Delete_ReturnsNothing() {
 	assert.True(suite.T(), true, "Test failed")
 }
 
+func (suite *LikeServiceUnitTestSuite) TestLikeService_Delete_LikeDoesNotExist_ReturnsNotFound() {
+	err := suite.service.Delete(1, 1, context.TODO())
+
+	assert.True(suite.T(), errors.Is(err, ErrNotFound), "Error is not not found")
+}
+
+func (suite *LikeServiceUnitTestSuite) TestLikeService_Create_Duplicate_ReturnsConflict() {
+	newLike, err := suite.service.Create(request.LikeDto{PostId: 2, UserId: 7, LikeType: 1}, context.TODO())
+
+	assert.True(suite.T(), errors.Is(err, ErrConflict), "Error is not conflict")
+	assert.Nil(suite.T(), newLike, "Like is not nil")
+}
+
 func (suite *LikeServiceUnitTestSuite) TestLikeService_Create_WithNonExistPost_ReturnError() {
 	like := request.LikeDto{
 		PostId:   1,
@@ -68,9 +89,21 @@ func (suite *LikeServiceUnitTestSuite) TestLikeService_Create_WithNonExistPost_R
 	newLike, err := suite.service.Create(like, context.TODO())
 
 	assert.NotNil(suite.T(), err, "Error is nil")
+	assert.True(suite.T(), errors.Is(err, ErrNotFound), "Error is not not found")
 	assert.Nil(suite.T(), newLike, "Like is not nil")
 }
 
+func (suite *LikeServiceUnitTestSuite) TestLikeService_Create_HeldPost_OnlyAuthorCanLike() {
+	_, err := suite.service.Create(request.LikeDto{PostId: 4, UserId: 2, LikeType: 1}, context.TODO())
+
+	assert.True(suite.T(), errors.Is(err, ErrNotFound), "Held post is found by another user")
+
+	like, err := suite.service.Create(request.LikeDto{PostId: 4, UserId: 1, LikeType: 1}, context.TODO())
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+	assert.NotNil(suite.T(), like, "Like is nil")
+}
+
 func (suite *LikeServiceUnitTestSuite) TestLikeService_Create_WithNonExistPostAndUser_ReturnError() {
 	like := request.LikeDto{
 		PostId:   1,
@@ -83,3 +116,47 @@ func (suite *LikeServiceUnitTestSuite) TestLikeService_Create_WithNonExistPostAn
 	assert.NotNil(suite.T(), err, "Error is nil")
 	assert.Nil(suite.T(), newLike, "Like is not nil")
 }
+
+func (suite *LikeServiceUnitTestSuite) TestLikeService_AddNotification_UserNotFound_NotificationSkipped() {
+	assert.NotPanics(suite.T(), func() {
+		suite.service.AddNotification(404, 1, 3, 1, context.TODO())
+	}, "Notification panics when user is not found")
+	assert.Empty(suite.T(), suite.notificationsMock.Added, "Notification is added")
+}
+
+func (suite *LikeServiceUnitTestSuite) TestLikeService_AddNotification_UserServiceUnavailable_NotificationSkipped() {
+	assert.NotPanics(suite.T(), func() {
+		suite.service.AddNotification(1, 503, 3, 2, context.TODO())
+	}, "Notification panics when users-ms is unavailable")
+	assert.Empty(suite.T(), suite.notificationsMock.Added, "Notification is added")
+}
+
+func (suite *LikeServiceUnitTestSuite) TestLikeService_AddNotification_DislikeAddsDislikedReaction() {
+	suite.service.AddNotification(2, 1, 3, 2, context.TODO())
+
+	assert.Equal(suite.T(), []PendingNotification{{RecipientAuth0ID: "1", ActorId: 2, ActorName: "Username", PostId: 3, Reaction: DislikedReaction}}, suite.notificationsMock.Added, "Notification is not added")
+}
+
+func (suite *LikeServiceUnitTestSuite) TestLikeService_AddNotification_OwnPost_NotificationSkipped() {
+	suite.service.AddNotification(23, 23, 3, 1, context.TODO())
+
+	assert.Empty(suite.T(), suite.notificationsMock.Added, "Notification about own post is added")
+}
+
+func (suite *LikeServiceUnitTestSuite) TestLikeService_AddNotification_LikeNotificationsOff_NotificationSkipped() {
+	suite.service.AddNotification(2, 21, 3, 1, context.TODO())
+
+	assert.Empty(suite.T(), suite.notificationsMock.Added, "Notification is added when like notifications are off")
+}
+
+func (suite *LikeServiceUnitTestSuite) TestLikeService_AddNotification_OnlyCommentNotificationsOff_AddsToAggregator() {
+	suite.service.AddNotification(2, 22, 3, 1, context.TODO())
+
+	assert.Equal(suite.T(), []PendingNotification{{RecipientAuth0ID: "22", ActorId: 2, ActorName: "Username", PostId: 3, Reaction: LikedReaction}}, suite.notificationsMock.Added, "Notification is not added")
+}
+
+func (suite *LikeServiceUnitTestSuite) TestLikeService_AddNotification_LikeNotificationsOn_AddsToAggregator() {
+	suite.service.AddNotification(2, 23, 3, 1, context.TODO())
+
+	assert.Equal(suite.T(), []PendingNotification{{RecipientAuth0ID: "23", ActorId: 2, ActorName: "Username", PostId: 3, Reaction: LikedReaction}}, suite.notificationsMock.Added, "Notification is not added")
+}
@@ -0,0 +1,190 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"posts-ms/src/entity"
+	"regexp"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ModerationAction is what happens to content. Actions are ordered by
+// severity, when several moderators disagree the most severe one wins.
+type ModerationAction int
+
+const (
+	AllowContent ModerationAction = iota
+	HoldContent
+	RejectContent
+)
+
+func (a ModerationAction) String() string {
+	switch a {
+	case HoldContent:
+		return "hold"
+	case RejectContent:
+		return "reject"
+	}
+
+	return "allow"
+}
+
+// ModerationContent is the text a user wants to publish. Entity is post or
+// comment.
+type ModerationContent struct {
+	Entity   string
+	AuthorId uint
+	Text     string
+}
+
+// ModerationDecision is the action a moderator takes and, unless content is
+// allowed, why.
+type ModerationDecision struct {
+	Action ModerationAction
+	Reason string
+}
+
+// IModerator checks content before it is stored. Content that is held is
+// stored but only shown to its author until it is reviewed, rejected content
+// is not stored at all.
+type IModerator interface {
+	Moderate(ModerationContent, context.Context) (ModerationDecision, error)
+}
+
+// ModerationPipeline asks every moderator in turn and returns the most severe
+// decision. It stops at the first rejection. An empty pipeline allows
+// everything.
+type ModerationPipeline []IModerator
+
+func (p ModerationPipeline) Moderate(content ModerationContent, ctx context.Context) (ModerationDecision, error) {
+	decision := ModerationDecision{Action: AllowContent}
+
+	for _, moderator := range p {
+		next, err := moderator.Moderate(content, ctx)
+
+		if err != nil {
+			return ModerationDecision{}, err
+		}
+
+		if next.Action > decision.Action {
+			decision = next
+		}
+
+		if decision.Action == RejectContent {
+			break
+		}
+	}
+
+	return decision, nil
+}
+
+// FilterRules lists the words and regular expressions that hold or reject
+// content. Words match whole words regardless of case.
+type FilterRules struct {
+	HoldWords      []string
+	HoldPatterns   []string
+	RejectWords    []string
+	RejectPatterns []string
+}
+
+type filterRule struct {
+	pattern *regexp.Regexp
+	action  ModerationAction
+	reason  string
+}
+
+// FilterModerator is the built-in moderator. It matches content against a
+// word list and regular expressions.
+type FilterModerator struct {
+	rules []filterRule
+}
+
+func NewFilterModerator(rules FilterRules) (*FilterModerator, error) {
+	moderator := &FilterModerator{}
+
+	sources := []struct {
+		words    []string
+		patterns []string
+		action   ModerationAction
+	}{
+		{rules.RejectWords, rules.RejectPatterns, RejectContent},
+		{rules.HoldWords, rules.HoldPatterns, HoldContent},
+	}
+
+	for _, source := range sources {
+		for _, word := range source.words {
+			if word = strings.TrimSpace(word); word == "" {
+				continue
+			}
+
+			moderator.rules = append(moderator.rules, filterRule{
+				pattern: regexp.MustCompile(`(?i)(^|\P{L})` + regexp.QuoteMeta(word) + `($|\P{L})`),
+				action:  source.action,
+				reason:  fmt.Sprintf("contains %q", word),
+			})
+		}
+
+		for _, pattern := range source.patterns {
+			compiled, err := regexp.Compile(pattern)
+
+			if err != nil {
+				return nil, fmt.Errorf("moderation pattern %q: %w", pattern, err)
+			}
+
+			moderator.rules = append(moderator.rules, filterRule{
+				pattern: compiled,
+				action:  source.action,
+				reason:  fmt.Sprintf("matches %q", pattern),
+			})
+		}
+	}
+
+	return moderator, nil
+}
+
+func (m *FilterModerator) Moderate(content ModerationContent, ctx context.Context) (ModerationDecision, error) {
+	for _, rule := range m.rules {
+		if rule.pattern.MatchString(content.Text) {
+			return ModerationDecision{Action: rule.action, Reason: rule.reason}, nil
+		}
+	}
+
+	return ModerationDecision{Action: AllowContent}, nil
+}
+
+// moderate runs moderator on content and returns the status to store it
+// with, or a ContentRejected error. When the moderator fails content is held,
+// so that it is neither lost nor published unchecked.
+func moderate(moderator IModerator, content ModerationContent, logger *logrus.Entry, ctx context.Context) (ModerationDecision, error) {
+	decision, err := moderator.Moderate(content, ctx)
+
+	if err != nil {
+		logger.WithContext(ctx).WithError(err).Errorf("Error occured in moderating %s, holding it for review", content.Entity)
+
+		decision = ModerationDecision{Action: HoldContent, Reason: "moderation failed"}
+	}
+
+	moderationDecisions.WithLabelValues(content.Entity, decision.Action.String()).Inc()
+
+	switch decision.Action {
+	case RejectContent:
+		logger.WithContext(ctx).Infof("Moderation rejected %s, it %s", content.Entity, decision.Reason)
+
+		return decision, NewContentRejectedError("%s contains content that is not allowed", content.Entity)
+	case HoldContent:
+		logger.WithContext(ctx).Infof("Moderation held %s for review, it %s", content.Entity, decision.Reason)
+	}
+
+	return decision, nil
+}
+
+// Status is the moderation status content is stored with.
+func (d ModerationDecision) Status() entity.ModerationStatus {
+	if d.Action == HoldContent {
+		return entity.ModerationHeld
+	}
+
+	return entity.ModerationApproved
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"posts-ms/src/dto/request"
+	"posts-ms/src/entity"
+	"posts-ms/src/utils"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+// testModerator holds content containing "spam" and rejects content
+// containing "forbidden".
+func testModerator() IModerator {
+	filter, _ := NewFilterModerator(FilterRules{HoldWords: []string{"spam"}, RejectWords: []string{"forbidden"}})
+
+	return ModerationPipeline{filter}
+}
+
+type fixedModerator struct {
+	decision ModerationDecision
+	err      error
+	calls    *int
+}
+
+func (m fixedModerator) Moderate(content ModerationContent, ctx context.Context) (ModerationDecision, error) {
+	if m.calls != nil {
+		*m.calls++
+	}
+
+	return m.decision, m.err
+}
+
+type ModeratorUnitTestSuite struct {
+	suite.Suite
+}
+
+func TestModeratorUnitTestSuite(t *testing.T) {
+	suite.Run(t, new(ModeratorUnitTestSuite))
+}
+
+func (suite *ModeratorUnitTestSuite) decide(moderator IModerator, text string) ModerationDecision {
+	decision, err := moderator.Moderate(ModerationContent{Entity: request.PostEntity, Text: text}, context.TODO())
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+
+	return decision
+}
+
+func (suite *ModeratorUnitTestSuite) TestFilterModerator_Moderate_MatchesWholeWordsIgnoringCase() {
+	moderator, err := NewFilterModerator(FilterRules{HoldWords: []string{"spam"}, RejectWords: []string{"scam link"}})
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+	assert.Equal(suite.T(), HoldContent, suite.decide(moderator, "Buy SPAM now!").Action, "Word is not matched regardless of case")
+	assert.Equal(suite.T(), RejectContent, suite.decide(moderator, "a scam link here").Action, "Phrase is not matched")
+	assert.Equal(suite.T(), AllowContent, suite.decide(moderator, "spammer and antispam").Action, "Part of a word is matched")
+	assert.Equal(suite.T(), `contains "spam"`, suite.decide(moderator, "spam").Reason, "Reason is not set")
+}
+
+func (suite *ModeratorUnitTestSuite) TestFilterModerator_Moderate_MatchesPatterns() {
+	moderator, err := NewFilterModerator(FilterRules{HoldPatterns: []string{`https?://`}, RejectPatterns: []string{`(?i)free\s+money`}})
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+	assert.Equal(suite.T(), HoldContent, suite.decide(moderator, "see http://example.com").Action, "Hold pattern is not matched")
+	assert.Equal(suite.T(), RejectContent, suite.decide(moderator, "FREE   money at http://example.com").Action, "Reject does not win over hold")
+	assert.Equal(suite.T(), AllowContent, suite.decide(moderator, "Nice picture").Action, "Clean content is not allowed")
+}
+
+func (suite *ModeratorUnitTestSuite) TestFilterModerator_NewFilterModerator_ReportsInvalidPattern() {
+	_, err := NewFilterModerator(FilterRules{HoldPatterns: []string{"("}})
+
+	assert.NotNil(suite.T(), err, "Error is nil")
+	assert.Contains(suite.T(), err.Error(), `"("`, "Pattern is not reported")
+}
+
+func (suite *ModeratorUnitTestSuite) TestModerationPipeline_Moderate_MostSevereWins() {
+	calls := 0
+	pipeline := ModerationPipeline{
+		fixedModerator{decision: ModerationDecision{Action: HoldContent, Reason: "held"}},
+		fixedModerator{decision: ModerationDecision{Action: AllowContent}},
+		fixedModerator{decision: ModerationDecision{Action: RejectContent, Reason: "rejected"}},
+		fixedModerator{decision: ModerationDecision{Action: HoldContent}, calls: &calls},
+	}
+
+	assert.Equal(suite.T(), ModerationDecision{Action: RejectContent, Reason: "rejected"}, suite.decide(pipeline, "text"), "Most severe decision does not win")
+	assert.Equal(suite.T(), 0, calls, "Moderators after a rejection are asked")
+	assert.Equal(suite.T(), AllowContent, suite.decide(ModerationPipeline{}, "text").Action, "Empty pipeline does not allow")
+}
+
+func (suite *ModeratorUnitTestSuite) TestModerate_RejectedContentReturnsError() {
+	rejected := moderationDecisions.WithLabelValues(request.CommentEntity, "reject")
+	before := testutil.ToFloat64(rejected)
+
+	_, err := moderate(testModerator(), ModerationContent{Entity: request.CommentEntity, Text: "forbidden"}, utils.Logger(), context.TODO())
+
+	assert.True(suite.T(), errors.Is(err, ErrContentRejected), "Error is not content rejected")
+	assert.Equal(suite.T(), before+1, testutil.ToFloat64(rejected), "Rejection is not counted")
+}
+
+func (suite *ModeratorUnitTestSuite) TestModerate_FailingModeratorHoldsContent() {
+	decision, err := moderate(fixedModerator{err: errors.New("classifier unavailable")}, ModerationContent{Entity: request.PostEntity}, utils.Logger(), context.TODO())
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+	assert.Equal(suite.T(), entity.ModerationHeld, decision.Status(), "Content is not held")
+}
@@ -0,0 +1,316 @@
+package service
+
+import (
+	"context"
+	"posts-ms/src/dto/request"
+	"posts-ms/src/rabbitmq"
+	"sync"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/sirupsen/logrus"
+)
+
+type NotificationReaction int
+
+const (
+	LikedReaction NotificationReaction = iota
+	DislikedReaction
+	CommentedReaction
+)
+
+var notificationReactions = []NotificationReaction{LikedReaction, DislikedReaction, CommentedReaction}
+
+var notificationReactionNames = map[NotificationReaction]string{
+	LikedReaction:     "liked",
+	DislikedReaction:  "disliked",
+	CommentedReaction: "commented",
+}
+
+func (r NotificationReaction) String() string {
+	return notificationReactionNames[r]
+}
+
+func parseNotificationReaction(name string) (NotificationReaction, bool) {
+	for reaction, reactionName := range notificationReactionNames {
+		if reactionName == name {
+			return reaction, true
+		}
+	}
+
+	return 0, false
+}
+
+// PendingNotification tells the owner of a post that a user reacted to it.
+// The message is written in RecipientLocale.
+type PendingNotification struct {
+	RecipientAuth0ID string
+	RecipientLocale  string
+	ActorId          uint
+	ActorName        string
+	PostId           uint
+	Reaction         NotificationReaction
+}
+
+// notificationsEnabled reads a notification preference of a user. Users
+// without a stored preference get notifications.
+func notificationsEnabled(preference *bool) bool {
+	return preference == nil || *preference
+}
+
+type INotificationAggregator interface {
+	Add(PendingNotification, context.Context)
+}
+
+// NotificationAggregatorConfig sets how long reactions are collected before
+// they are sent, and for how long a user's repeated reactions to the same
+// post are not notified again.
+type NotificationAggregatorConfig struct {
+	Window       time.Duration
+	DedupeWindow time.Duration
+}
+
+// notificationGroup collects the reactions of one kind, likes or comments, to
+// one post. Actors keeps the order they reacted in, with their last reaction.
+type notificationGroup struct {
+	recipientAuth0ID string
+	locale           string
+	postId           uint
+	comments         bool
+	actors           []uint
+	names            map[uint]string
+	reactions        map[uint]NotificationReaction
+}
+
+type notificationGroupKey struct {
+	recipientAuth0ID string
+	postId           uint
+	comments         bool
+}
+
+type notificationActionKey struct {
+	actorId  uint
+	postId   uint
+	comments bool
+}
+
+// NotificationAggregator batches the reactions to a post that arrive within
+// Window into one notification per reaction, such as "Alice and 12 others
+// liked your post.". A user who likes, dislikes or comments on the same post
+// again, within the window or DedupeWindow after being notified about, does
+// not cause another notification.
+type NotificationAggregator struct {
+	Publisher rabbitmq.INotificationPublisher
+	Templates *NotificationTemplates
+	Config    NotificationAggregatorConfig
+	Logger    *logrus.Entry
+
+	mutex  sync.Mutex
+	groups map[notificationGroupKey]*notificationGroup
+	order  []notificationGroupKey
+	sent   map[notificationActionKey]time.Time
+	now    func() time.Time
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func NewNotificationAggregator(publisher rabbitmq.INotificationPublisher, templates *NotificationTemplates, config NotificationAggregatorConfig, logger *logrus.Entry) *NotificationAggregator {
+	return &NotificationAggregator{
+		Publisher: publisher,
+		Templates: templates,
+		Config:    config,
+		Logger:    logger,
+		groups:    map[notificationGroupKey]*notificationGroup{},
+		sent:      map[notificationActionKey]time.Time{},
+		now:       time.Now,
+	}
+}
+
+func (a *NotificationAggregator) Add(notification PendingNotification, ctx context.Context) {
+	comments := notification.Reaction == CommentedReaction
+	action := notificationActionKey{actorId: notification.ActorId, postId: notification.PostId, comments: comments}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if sentAt, ok := a.sent[action]; ok && a.now().Sub(sentAt) < a.Config.DedupeWindow {
+		a.Logger.WithContext(ctx).Debug("User was already notified about this reaction, notification is skipped")
+
+		return
+	}
+
+	key := notificationGroupKey{recipientAuth0ID: notification.RecipientAuth0ID, postId: notification.PostId, comments: comments}
+	group, ok := a.groups[key]
+
+	if !ok {
+		group = &notificationGroup{
+			recipientAuth0ID: notification.RecipientAuth0ID,
+			postId:           notification.PostId,
+			comments:         comments,
+			names:            map[uint]string{},
+			reactions:        map[uint]NotificationReaction{},
+		}
+		a.groups[key] = group
+		a.order = append(a.order, key)
+	}
+
+	if _, ok := group.reactions[notification.ActorId]; !ok {
+		group.actors = append(group.actors, notification.ActorId)
+	}
+
+	group.locale = notification.RecipientLocale
+	group.names[notification.ActorId] = notification.ActorName
+	group.reactions[notification.ActorId] = notification.Reaction
+}
+
+// Start flushes the collected notifications every Window until Close.
+func (a *NotificationAggregator) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	a.cancel = cancel
+	a.done = make(chan struct{})
+
+	go func() {
+		defer close(a.done)
+
+		ticker := time.NewTicker(a.Config.Window)
+
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				a.Flush(context.Background())
+			}
+		}
+	}()
+}
+
+// Close stops the timer and sends what has been collected so far.
+func (a *NotificationAggregator) Close() error {
+	if a.cancel != nil {
+		a.cancel()
+
+		<-a.done
+	}
+
+	a.Flush(context.Background())
+
+	return nil
+}
+
+// Flush sends one notification per post and reaction collected since the
+// last flush. Only the users in notifications that were published count as
+// notified, a failed publish does not hold back their next reaction.
+func (a *NotificationAggregator) Flush(ctx context.Context) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Service - Flush notifications")
+
+	defer span.Finish()
+
+	a.mutex.Lock()
+
+	groups := make([]*notificationGroup, 0, len(a.order))
+
+	for _, key := range a.order {
+		groups = append(groups, a.groups[key])
+	}
+
+	a.groups = map[notificationGroupKey]*notificationGroup{}
+	a.order = nil
+
+	a.mutex.Unlock()
+
+	published := map[*notificationGroup]map[string]bool{}
+
+	for _, group := range groups {
+		published[group] = map[string]bool{}
+
+		for _, notification := range a.notifications(group, ctx) {
+			if err := a.Publisher.AddNotification(notification, ctx); err != nil {
+				notificationPublishFailures.WithLabelValues(group.entity()).Inc()
+
+				a.Logger.WithContext(ctx).WithError(err).Errorf("Error occured in publishing %s notification", group.entity())
+
+				continue
+			}
+
+			published[group][notification.ReactionType] = true
+		}
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	now := a.now()
+
+	for action, sentAt := range a.sent {
+		if now.Sub(sentAt) >= a.Config.DedupeWindow {
+			delete(a.sent, action)
+		}
+	}
+
+	for _, group := range groups {
+		for _, actorId := range group.actors {
+			if published[group][group.reactions[actorId].String()] {
+				a.sent[notificationActionKey{actorId: actorId, postId: group.postId, comments: group.comments}] = now
+			}
+		}
+	}
+}
+
+func (g *notificationGroup) entity() string {
+	if g.comments {
+		return request.CommentEntity
+	}
+
+	return request.LikeEntity
+}
+
+// notifications builds one notification per reaction, naming the user who
+// reacted last. A message that cannot be rendered is left empty, receivers
+// can still write one from the other fields.
+func (a *NotificationAggregator) notifications(group *notificationGroup, ctx context.Context) []*request.NotificationDTO {
+	notificationType := request.Like
+
+	if group.comments {
+		notificationType = request.Comment
+	}
+
+	var notifications []*request.NotificationDTO
+
+	for _, reaction := range notificationReactions {
+		var actors []uint
+
+		for _, actorId := range group.actors {
+			if group.reactions[actorId] == reaction {
+				actors = append(actors, actorId)
+			}
+		}
+
+		if len(actors) == 0 {
+			continue
+		}
+
+		actorId := actors[len(actors)-1]
+
+		message, err := a.Templates.Render(reaction, group.locale, NotificationTemplateData{Actor: group.names[actorId], Others: len(actors) - 1})
+
+		if err != nil {
+			a.Logger.WithContext(ctx).WithError(err).Errorf("Error occured in rendering %s notification", reaction)
+		}
+
+		notifications = append(notifications, &request.NotificationDTO{
+			Message:          message,
+			UserAuth0ID:      group.recipientAuth0ID,
+			NotificationType: &notificationType,
+			ActorId:          actorId,
+			PostId:           group.postId,
+			ReactionType:     reaction.String(),
+			OtherActors:      len(actors) - 1,
+		})
+	}
+
+	return notifications
+}
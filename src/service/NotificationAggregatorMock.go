@@ -0,0 +1,16 @@
+package service
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type NotificationAggregatorMock struct {
+	mock.Mock
+	Added []PendingNotification
+}
+
+func (m *NotificationAggregatorMock) Add(notification PendingNotification, ctx context.Context) {
+	m.Added = append(m.Added, notification)
+}
@@ -0,0 +1,216 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"posts-ms/src/dto/request"
+	"posts-ms/src/rabbitmq"
+	"posts-ms/src/utils"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type NotificationAggregatorUnitTestSuite struct {
+	suite.Suite
+	publisherMock *rabbitmq.NotificationPublisherMock
+	aggregator    *NotificationAggregator
+	now           time.Time
+	mutex         sync.Mutex
+	published     []request.NotificationDTO
+}
+
+func TestNotificationAggregatorUnitTestSuite(t *testing.T) {
+	suite.Run(t, new(NotificationAggregatorUnitTestSuite))
+}
+
+func (suite *NotificationAggregatorUnitTestSuite) SetupTest() {
+	suite.published = nil
+	suite.now = time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+	suite.publisherMock = new(rabbitmq.NotificationPublisherMock)
+	suite.publishReturns(nil)
+
+	templates, _ := LoadNotificationTemplates("en")
+
+	suite.aggregator = NewNotificationAggregator(suite.publisherMock, templates, NotificationAggregatorConfig{Window: time.Hour, DedupeWindow: 10 * time.Minute}, utils.Logger())
+	suite.aggregator.now = func() time.Time { return suite.now }
+}
+
+func (suite *NotificationAggregatorUnitTestSuite) publishReturns(err error) {
+	suite.publisherMock.ExpectedCalls = nil
+	suite.publisherMock.On("AddNotification", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		suite.mutex.Lock()
+		defer suite.mutex.Unlock()
+
+		suite.published = append(suite.published, *args.Get(0).(*request.NotificationDTO))
+	}).Return(err)
+}
+
+func (suite *NotificationAggregatorUnitTestSuite) messages() []string {
+	suite.mutex.Lock()
+	defer suite.mutex.Unlock()
+
+	messages := []string{}
+
+	for _, notification := range suite.published {
+		messages = append(messages, notification.UserAuth0ID+": "+notification.Message)
+	}
+
+	return messages
+}
+
+func reacted(actorId uint, postId uint, reaction NotificationReaction) PendingNotification {
+	return PendingNotification{
+		RecipientAuth0ID: "owner",
+		ActorId:          actorId,
+		ActorName:        fmt.Sprintf("User%d", actorId),
+		PostId:           postId,
+		Reaction:         reaction,
+	}
+}
+
+func (suite *NotificationAggregatorUnitTestSuite) TestNotificationAggregator_Flush_BatchesBurst() {
+	for actorId := uint(1); actorId <= 13; actorId++ {
+		suite.aggregator.Add(reacted(actorId, 1, LikedReaction), context.TODO())
+	}
+
+	suite.aggregator.Flush(context.TODO())
+
+	assert.Equal(suite.T(), []string{"owner: User13 and 12 others liked your post."}, suite.messages(), "Burst is not batched")
+	assert.Equal(suite.T(), request.Like, *suite.published[0].NotificationType, "Notification type is not like")
+}
+
+func (suite *NotificationAggregatorUnitTestSuite) TestNotificationAggregator_Flush_SingleAndPairMessages() {
+	suite.aggregator.Add(reacted(1, 1, LikedReaction), context.TODO())
+	suite.aggregator.Add(reacted(2, 2, CommentedReaction), context.TODO())
+	suite.aggregator.Add(reacted(3, 2, CommentedReaction), context.TODO())
+
+	suite.aggregator.Flush(context.TODO())
+
+	assert.Equal(suite.T(), []string{
+		"owner: User1 liked your post.",
+		"owner: User3 and 1 other commented on your post.",
+	}, suite.messages(), "Messages are not built per post")
+}
+
+func (suite *NotificationAggregatorUnitTestSuite) TestNotificationAggregator_Flush_FillsStructuredFields() {
+	suite.aggregator.Add(reacted(1, 7, DislikedReaction), context.TODO())
+	suite.aggregator.Add(reacted(2, 7, DislikedReaction), context.TODO())
+
+	suite.aggregator.Flush(context.TODO())
+
+	assert.Len(suite.T(), suite.published, 1, "Notifications are not batched")
+	assert.Equal(suite.T(), uint(2), suite.published[0].ActorId, "Actor id is not the last actor")
+	assert.Equal(suite.T(), uint(7), suite.published[0].PostId, "Post id is not set")
+	assert.Equal(suite.T(), "disliked", suite.published[0].ReactionType, "Reaction type is not set")
+	assert.Equal(suite.T(), 1, suite.published[0].OtherActors, "Other actors are not counted")
+}
+
+func (suite *NotificationAggregatorUnitTestSuite) TestNotificationAggregator_Flush_RendersRecipientLocale() {
+	notification := reacted(1, 1, LikedReaction)
+	notification.RecipientLocale = "de_AT"
+
+	suite.aggregator.Add(notification, context.TODO())
+	suite.aggregator.Add(reacted(2, 2, CommentedReaction), context.TODO())
+
+	suite.aggregator.Flush(context.TODO())
+
+	assert.Equal(suite.T(), []string{
+		"owner: Dein Beitrag gefällt User1.",
+		"owner: User2 commented on your post.",
+	}, suite.messages(), "Messages are not rendered in the recipient's locale")
+}
+
+func (suite *NotificationAggregatorUnitTestSuite) TestNotificationAggregator_Add_TogglingKeepsLastReaction() {
+	suite.aggregator.Add(reacted(1, 1, LikedReaction), context.TODO())
+	suite.aggregator.Add(reacted(1, 1, DislikedReaction), context.TODO())
+	suite.aggregator.Add(reacted(1, 1, LikedReaction), context.TODO())
+	suite.aggregator.Add(reacted(1, 1, DislikedReaction), context.TODO())
+	suite.aggregator.Add(reacted(2, 1, LikedReaction), context.TODO())
+
+	suite.aggregator.Flush(context.TODO())
+
+	assert.Equal(suite.T(), []string{
+		"owner: User2 liked your post.",
+		"owner: User1 disliked your post.",
+	}, suite.messages(), "Toggling is not deduplicated")
+}
+
+func (suite *NotificationAggregatorUnitTestSuite) TestNotificationAggregator_Add_RepeatWithinDedupeWindowIsSkipped() {
+	suite.aggregator.Add(reacted(1, 1, LikedReaction), context.TODO())
+	suite.aggregator.Flush(context.TODO())
+
+	suite.now = suite.now.Add(5 * time.Minute)
+
+	suite.aggregator.Add(reacted(1, 1, DislikedReaction), context.TODO())
+	suite.aggregator.Add(reacted(1, 1, CommentedReaction), context.TODO())
+	suite.aggregator.Flush(context.TODO())
+
+	assert.Equal(suite.T(), []string{
+		"owner: User1 liked your post.",
+		"owner: User1 commented on your post.",
+	}, suite.messages(), "Repeated like is notified")
+
+	suite.now = suite.now.Add(10 * time.Minute)
+
+	suite.aggregator.Add(reacted(1, 1, DislikedReaction), context.TODO())
+	suite.aggregator.Flush(context.TODO())
+
+	assert.Equal(suite.T(), "owner: User1 disliked your post.", suite.messages()[2], "Reaction after the dedupe window is not notified")
+	assert.Len(suite.T(), suite.aggregator.sent, 1, "Expired reactions are kept")
+}
+
+func (suite *NotificationAggregatorUnitTestSuite) TestNotificationAggregator_Flush_CountsPublishFailures() {
+	failures := notificationPublishFailures.WithLabelValues(request.CommentEntity)
+	before := testutil.ToFloat64(failures)
+
+	suite.publishReturns(errors.New("channel closed"))
+
+	suite.aggregator.Add(reacted(1, 1, CommentedReaction), context.TODO())
+	suite.aggregator.Flush(context.TODO())
+
+	assert.Equal(suite.T(), before+1, testutil.ToFloat64(failures), "Publish failure is not counted")
+}
+
+func (suite *NotificationAggregatorUnitTestSuite) TestNotificationAggregator_Flush_FailedPublishIsNotDeduplicated() {
+	suite.publishReturns(errors.New("channel closed"))
+
+	suite.aggregator.Add(reacted(1, 1, LikedReaction), context.TODO())
+	suite.aggregator.Flush(context.TODO())
+
+	suite.publishReturns(nil)
+	suite.published = nil
+
+	suite.aggregator.Add(reacted(1, 1, LikedReaction), context.TODO())
+	suite.aggregator.Flush(context.TODO())
+
+	assert.Equal(suite.T(), []string{"owner: User1 liked your post."}, suite.messages(), "Reaction after a failed publish is skipped")
+}
+
+func (suite *NotificationAggregatorUnitTestSuite) TestNotificationAggregator_Start_FlushesOnTimer() {
+	suite.aggregator.Config.Window = 10 * time.Millisecond
+
+	suite.aggregator.Start()
+
+	defer suite.aggregator.Close()
+
+	suite.aggregator.Add(reacted(1, 1, LikedReaction), context.TODO())
+
+	assert.Eventually(suite.T(), func() bool { return len(suite.messages()) == 1 }, time.Second, time.Millisecond, "Notification is not flushed on timer")
+}
+
+func (suite *NotificationAggregatorUnitTestSuite) TestNotificationAggregator_Close_FlushesPending() {
+	suite.aggregator.Start()
+
+	suite.aggregator.Add(reacted(1, 1, LikedReaction), context.TODO())
+
+	suite.aggregator.Close()
+
+	assert.Equal(suite.T(), []string{"owner: User1 liked your post."}, suite.messages(), "Pending notification is not flushed on close")
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed templates/notifications/*.yaml
+var embeddedNotificationTemplates embed.FS
+
+// NotificationTemplateData is what notification templates are rendered with:
+// the name of the user who reacted last and how many other users reacted the
+// same way.
+type NotificationTemplateData struct {
+	Actor  string
+	Others int
+}
+
+// NotificationTemplates keeps the notification messages of every locale, one
+// template per reaction. Messages a locale does not have are taken from
+// DefaultLocale.
+type NotificationTemplates struct {
+	DefaultLocale string
+	templates     map[string]map[NotificationReaction]*template.Template
+}
+
+// LoadNotificationTemplates reads the templates embedded in the binary, one
+// <locale>.yaml file per locale. The default locale must have a template for
+// every reaction.
+func LoadNotificationTemplates(defaultLocale string) (*NotificationTemplates, error) {
+	return loadNotificationTemplates(embeddedNotificationTemplates, "templates/notifications", defaultLocale)
+}
+
+func loadNotificationTemplates(files fs.FS, dir string, defaultLocale string) (*NotificationTemplates, error) {
+	entries, err := fs.ReadDir(files, dir)
+
+	if err != nil {
+		return nil, err
+	}
+
+	templates := &NotificationTemplates{
+		DefaultLocale: normalizeLocale(defaultLocale),
+		templates:     map[string]map[NotificationReaction]*template.Template{},
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != ".yaml" {
+			continue
+		}
+
+		locale := normalizeLocale(strings.TrimSuffix(entry.Name(), ".yaml"))
+
+		content, err := fs.ReadFile(files, path.Join(dir, entry.Name()))
+
+		if err != nil {
+			return nil, err
+		}
+
+		if templates.templates[locale], err = parseNotificationTemplates(locale, content); err != nil {
+			return nil, err
+		}
+	}
+
+	defaults, ok := templates.templates[templates.DefaultLocale]
+
+	if !ok {
+		return nil, fmt.Errorf("no notification templates for default locale %q", defaultLocale)
+	}
+
+	for _, reaction := range notificationReactions {
+		if _, ok := defaults[reaction]; !ok {
+			return nil, fmt.Errorf("notification template %q is missing for default locale %q", reaction, defaultLocale)
+		}
+	}
+
+	return templates, nil
+}
+
+func parseNotificationTemplates(locale string, content []byte) (map[NotificationReaction]*template.Template, error) {
+	var sources map[string]string
+
+	if err := yaml.Unmarshal(content, &sources); err != nil {
+		return nil, fmt.Errorf("notification templates for locale %q: %w", locale, err)
+	}
+
+	parsed := map[NotificationReaction]*template.Template{}
+
+	for name, source := range sources {
+		reaction, ok := parseNotificationReaction(name)
+
+		if !ok {
+			return nil, fmt.Errorf("notification templates for locale %q: unknown reaction %q", locale, name)
+		}
+
+		tmpl, err := template.New(locale + "/" + name).Option("missingkey=error").Parse(source)
+
+		if err != nil {
+			return nil, fmt.Errorf("notification templates for locale %q: %w", locale, err)
+		}
+
+		if err := tmpl.Execute(&bytes.Buffer{}, NotificationTemplateData{Actor: "Actor", Others: 2}); err != nil {
+			return nil, fmt.Errorf("notification templates for locale %q: %w", locale, err)
+		}
+
+		parsed[reaction] = tmpl
+	}
+
+	return parsed, nil
+}
+
+// Render writes the message for a reaction in locale. A regional locale such
+// as de-AT falls back to its language, de, and then to the default locale.
+func (t *NotificationTemplates) Render(reaction NotificationReaction, locale string, data NotificationTemplateData) (string, error) {
+	tmpl := t.lookup(reaction, locale)
+
+	if tmpl == nil {
+		return "", fmt.Errorf("no notification template for reaction %q", reaction)
+	}
+
+	var message bytes.Buffer
+
+	if err := tmpl.Execute(&message, data); err != nil {
+		return "", err
+	}
+
+	return message.String(), nil
+}
+
+func (t *NotificationTemplates) lookup(reaction NotificationReaction, locale string) *template.Template {
+	locale = normalizeLocale(locale)
+	candidates := []string{locale}
+
+	if i := strings.Index(locale, "-"); i > 0 {
+		candidates = append(candidates, locale[:i])
+	}
+
+	for _, candidate := range append(candidates, t.DefaultLocale) {
+		if tmpl, ok := t.templates[candidate][reaction]; ok {
+			return tmpl
+		}
+	}
+
+	return nil
+}
+
+func normalizeLocale(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type NotificationTemplatesUnitTestSuite struct {
+	suite.Suite
+	templates *NotificationTemplates
+}
+
+func TestNotificationTemplatesUnitTestSuite(t *testing.T) {
+	suite.Run(t, new(NotificationTemplatesUnitTestSuite))
+}
+
+func (suite *NotificationTemplatesUnitTestSuite) SetupTest() {
+	suite.templates, _ = loadNotificationTemplates(fstest.MapFS{
+		"notifications/en.yaml":    {Data: []byte("liked: \"{{.Actor}} liked\"\ndisliked: \"{{.Actor}} disliked\"\ncommented: \"{{.Actor}} commented\"\n")},
+		"notifications/de.yaml":    {Data: []byte("liked: \"{{.Actor}} gefällt\"\n")},
+		"notifications/de-ch.yaml": {Data: []byte("liked: \"{{.Actor}} gfallt\"\n")},
+	}, "notifications", "en")
+}
+
+func (suite *NotificationTemplatesUnitTestSuite) render(reaction NotificationReaction, locale string) string {
+	message, err := suite.templates.Render(reaction, locale, NotificationTemplateData{Actor: "Alice"})
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+
+	return message
+}
+
+func (suite *NotificationTemplatesUnitTestSuite) TestNotificationTemplates_LoadNotificationTemplates_LoadsEmbeddedTemplates() {
+	templates, err := LoadNotificationTemplates("en")
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+
+	message, err := templates.Render(CommentedReaction, "en", NotificationTemplateData{Actor: "Alice", Others: 12})
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+	assert.Equal(suite.T(), "Alice and 12 others commented on your post.", message, "Message is not rendered")
+}
+
+func (suite *NotificationTemplatesUnitTestSuite) TestNotificationTemplates_Render_UsesLocale() {
+	assert.Equal(suite.T(), "Alice gefällt", suite.render(LikedReaction, "de"), "Locale is not used")
+	assert.Equal(suite.T(), "Alice gfallt", suite.render(LikedReaction, "de_CH"), "Regional locale is not used")
+}
+
+func (suite *NotificationTemplatesUnitTestSuite) TestNotificationTemplates_Render_FallsBack() {
+	assert.Equal(suite.T(), "Alice gefällt", suite.render(LikedReaction, "de-AT"), "Regional locale does not fall back to its language")
+	assert.Equal(suite.T(), "Alice disliked", suite.render(DislikedReaction, "de-CH"), "Missing template does not fall back to default locale")
+	assert.Equal(suite.T(), "Alice liked", suite.render(LikedReaction, "fr"), "Unknown locale does not fall back to default locale")
+	assert.Equal(suite.T(), "Alice liked", suite.render(LikedReaction, ""), "Empty locale does not fall back to default locale")
+}
+
+func (suite *NotificationTemplatesUnitTestSuite) TestNotificationTemplates_LoadNotificationTemplates_ReportsInvalidTemplates() {
+	files := []struct {
+		content string
+		problem string
+	}{
+		{"liked: \"{{.Actor}}\"\n", "missing for default locale"},
+		{"liked: \"{{.Actor}}\"\ndisliked: \"x\"\ncommented: \"x\"\nshared: \"x\"\n", "unknown reaction"},
+		{"liked: \"{{.Actor\"\ndisliked: \"x\"\ncommented: \"x\"\n", "liked"},
+		{"liked: \"{{.Name}}\"\ndisliked: \"x\"\ncommented: \"x\"\n", "Name"},
+	}
+
+	for _, file := range files {
+		_, err := loadNotificationTemplates(fstest.MapFS{"notifications/en.yaml": {Data: []byte(file.content)}}, "notifications", "en")
+
+		assert.NotNil(suite.T(), err, "Error is nil")
+		assert.Contains(suite.T(), err.Error(), file.problem, "Problem is not reported")
+	}
+
+	_, err := LoadNotificationTemplates("fr")
+
+	assert.NotNil(suite.T(), err, "Missing default locale is not reported")
+}
@@ -2,6 +2,8 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"mime/multipart"
 	"posts-ms/src/client"
 	"posts-ms/src/dto/request"
@@ -12,17 +14,17 @@ import (
 
 	"github.com/opentracing/opentracing-go"
 	"github.com/sirupsen/logrus"
-	"github.com/streadway/amqp"
 )
 
 type IPostService interface {
 	Create(request.PostDto, []*multipart.FileHeader, context.Context) (*response.PostDto, error)
 	CreatePost(entity.Post, context.Context) (*entity.Post, error)
-	Delete(uint, context.Context)
+	Delete(uint, context.Context) error
 	GetById(uint, context.Context) (*response.PostDto, error)
 	GetPostById(uint, context.Context) (*entity.Post, error)
-	GetAllByUserId(uint, context.Context) []*response.PostDto
-	GetAllByUserIds([]uint, context.Context) []*response.PostDto
+	GetAllByUserId(uint, uint, context.Context) []*response.PostDto
+	GetAllByUserIds([]uint, uint, context.Context) []*response.PostDto
+	HandleMediaProcessed(response.MediaProcessedDto, context.Context) error
 }
 
 type PostService struct {
@@ -30,7 +32,9 @@ type PostService struct {
 	LikeRepository    repository.ILikeRepository
 	CommentRepository repository.ICommentRepository
 	MediaClient       client.IMediaClient
-	RabbitMQChannel   *amqp.Channel
+	MediaPublisher    rabbitmq.IMediaPublisher
+	Moderator         IModerator
+	AsyncMediaUpload  bool
 	Logger            *logrus.Entry
 }
 
@@ -39,15 +43,15 @@ func (s PostService) GetById(id uint, ctx context.Context) (*response.PostDto, e
 
 	defer span.Finish()
 
-	s.Logger.Info("Getting post by id")
+	s.Logger.WithContext(ctx).Info("Getting post by id")
 
 	post, err := s.PostRepository.GetById(id, ctx)
 
 	if err != nil {
-		return nil, err
+		return nil, lookupError(err, "post %d does not exist", id)
 	}
 
-	return post.CreateDto(), err
+	return post.CreateDto(), nil
 }
 
 func (s PostService) GetPostById(id uint, ctx context.Context) (*entity.Post, error) {
@@ -55,33 +59,41 @@ func (s PostService) GetPostById(id uint, ctx context.Context) (*entity.Post, er
 
 	defer span.Finish()
 
-	s.Logger.Info("Getting post by id")
+	s.Logger.WithContext(ctx).Info("Getting post by id")
+
+	post, err := s.PostRepository.GetById(id, ctx)
 
-	return s.PostRepository.GetById(id, ctx)
+	if err != nil {
+		return nil, lookupError(err, "post %d does not exist", id)
+	}
+
+	return post, nil
 }
 
-func (s PostService) GetAllByUserId(id uint, ctx context.Context) []*response.PostDto {
+// GetAllByUserId returns the posts of a user that viewerId may see.
+func (s PostService) GetAllByUserId(id uint, viewerId uint, ctx context.Context) []*response.PostDto {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "Service - Get all posts by user id")
 
 	defer span.Finish()
 
-	s.Logger.Info("Getting posts by user")
+	s.Logger.WithContext(ctx).Info("Getting posts by user")
 
 	posts := s.PostRepository.GetAllByUserId(id, ctx)
 
-	return s.transformListOfDAOToListOfDTO(posts)
+	return s.transformListOfDAOToListOfDTO(posts, viewerId)
 }
 
-func (s PostService) GetAllByUserIds(ids []uint, ctx context.Context) []*response.PostDto {
+// GetAllByUserIds returns the posts of the users that viewerId may see.
+func (s PostService) GetAllByUserIds(ids []uint, viewerId uint, ctx context.Context) []*response.PostDto {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "Service - Get posts by user ids")
 
 	defer span.Finish()
 
-	s.Logger.Info("Getting posts by users")
+	s.Logger.WithContext(ctx).Info("Getting posts by users")
 
 	posts := s.PostRepository.GetAllByUserIds(ids, ctx)
 
-	return s.transformListOfDAOToListOfDTO(posts)
+	return s.transformListOfDAOToListOfDTO(posts, viewerId)
 }
 
 func (s PostService) Create(dto request.PostDto, images []*multipart.FileHeader, ctx context.Context) (*response.PostDto, error) {
@@ -89,24 +101,127 @@ func (s PostService) Create(dto request.PostDto, images []*multipart.FileHeader,
 
 	defer span.Finish()
 
-	s.Logger.Info("Creating post")
+	s.Logger.WithContext(ctx).Info("Creating post")
+
+	if len(images) == 0 {
+		return nil, NewValidationError("post requires an image")
+	}
+
+	decision, err := moderate(s.Moderator, ModerationContent{Entity: request.PostEntity, AuthorId: dto.UserId, Text: dto.Description}, s.Logger, ctx)
+
+	if err != nil {
+		return nil, err
+	}
 
 	post := entity.CreatePost(dto)
 
-	file, _ := images[0].Open()
+	post.SetModeration(decision.Status(), decision.Reason)
 
-	s.Logger.Info("Sending request on media-ms for creating media")
-	imageId, err := s.MediaClient.Upload(file, ctx)
+	if s.AsyncMediaUpload {
+		return s.createWithPendingMedia(post, images[0], ctx)
+	}
+
+	s.Logger.WithContext(ctx).Info("Sending request on media-ms for creating media")
+	imageId, err := s.MediaClient.Upload(images[0], ctx)
 
 	if err != nil {
-		return nil, err
+		return nil, clientError(err, "media-ms")
 	}
 
 	post.SetImageId(imageId)
 
 	newPost, err := s.PostRepository.Create(post, ctx)
 
-	return newPost.CreateDto(), err
+	if err != nil {
+		s.Logger.WithContext(ctx).Error("Error occured in saving post, compensating media upload")
+
+		s.deleteImage(imageId, ctx)
+
+		return nil, err
+	}
+
+	countCreated(request.PostEntity)
+
+	return newPost.CreateDto(), nil
+}
+
+// deleteImage asks media-ms to delete an image. A failure is only logged, the
+// image stays orphaned on media-ms and the caller's outcome does not change.
+func (s PostService) deleteImage(imageId uint, ctx context.Context) {
+	s.Logger.WithContext(ctx).Info("Sending request on media-ms for deleting media")
+
+	if err := s.MediaPublisher.DeleteImage(imageId, ctx); err != nil {
+		s.Logger.WithContext(ctx).WithError(err).Error("Error occured in sending request on media-ms for deleting media")
+	}
+}
+
+// createWithPendingMedia saves the post as processing and hands the image to
+// media-ms, which reports the outcome with a MediaProcessed message. When
+// media-ms does not take the image the post is deleted again, a post whose
+// image never arrives would stay processing forever.
+func (s PostService) createWithPendingMedia(post entity.Post, image *multipart.FileHeader, ctx context.Context) (*response.PostDto, error) {
+	post.SetMediaStatus(entity.MediaProcessing)
+
+	newPost, err := s.PostRepository.Create(post, ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	s.Logger.WithContext(ctx).Info("Sending request on media-ms for processing media")
+
+	if err := s.MediaClient.Submit(newPost.ID, image, ctx); err != nil {
+		s.Logger.WithContext(ctx).WithError(err).Error("Error occured in sending media for processing, deleting post")
+
+		if err := s.PostRepository.Delete(newPost.ID, ctx); err != nil {
+			s.Logger.WithContext(ctx).WithError(err).Error("Error occured in deleting post without media")
+		}
+
+		return nil, clientError(err, "media-ms")
+	}
+
+	countCreated(request.PostEntity)
+
+	return newPost.CreateDto(), nil
+}
+
+func (s PostService) HandleMediaProcessed(result response.MediaProcessedDto, ctx context.Context) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Service - Handle media processing result")
+
+	defer span.Finish()
+
+	s.Logger.WithContext(ctx).Info("Updating media status of post")
+
+	post, err := s.PostRepository.GetById(result.PostId, ctx)
+
+	if err != nil {
+		err = lookupError(err, "post %d does not exist", result.PostId)
+
+		if !errors.Is(err, ErrNotFound) {
+			return err
+		}
+
+		if result.Success {
+			s.Logger.WithContext(ctx).Info("Post no longer exists, discarding processed media")
+
+			s.deleteImage(result.ImageId, ctx)
+		}
+
+		return fmt.Errorf("%w: %v", rabbitmq.ErrUnprocessable, err)
+	}
+
+	if result.Success {
+		post.SetImageId(result.ImageId)
+		post.SetMediaStatus(entity.MediaReady)
+	} else {
+		s.Logger.WithContext(ctx).Error("Media processing failed on media-ms")
+
+		post.SetMediaStatus(entity.MediaFailed)
+	}
+
+	_, err = s.PostRepository.Create(*post, ctx)
+
+	return err
 }
 
 func (s PostService) CreatePost(post entity.Post, ctx context.Context) (*entity.Post, error) {
@@ -119,35 +234,56 @@ func (s PostService) CreatePost(post entity.Post, ctx context.Context) (*entity.
 	return &post, err
 }
 
-func (s PostService) Delete(id uint, ctx context.Context) {
+func (s PostService) Delete(id uint, ctx context.Context) error {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "Service - Delete post by id")
 
 	defer span.Finish()
 
-	s.Logger.Info("Deleting post")
+	s.Logger.WithContext(ctx).Info("Deleting post")
+
+	post, err := s.PostRepository.GetById(id, ctx)
+
+	if err != nil {
+		return lookupError(err, "post %d does not exist", id)
+	}
 
-	post, error := s.PostRepository.GetById(id, ctx)
+	s.Logger.WithContext(ctx).Info("Deleting likes for post")
 
-	if error != nil {
-		return
+	if err := s.LikeRepository.DeleteByPostId(id, ctx); err != nil {
+		return err
 	}
 
-	s.Logger.Info("Sending request on media-ms for deleting media")
-	rabbitmq.DeleteImage(post.ImageId, s.RabbitMQChannel, ctx)
+	s.Logger.WithContext(ctx).Info("Deleting comments for post")
 
-	s.Logger.Info("Deleting likes for post")
-	s.LikeRepository.DeleteByPostId(id, ctx)
+	if err := s.CommentRepository.DeleteByPostId(id, ctx); err != nil {
+		return err
+	}
+
+	if err := s.PostRepository.Delete(id, ctx); err != nil {
+		return lookupError(err, "post %d does not exist", id)
+	}
 
-	s.Logger.Info("Deleting comments for post")
-	s.CommentRepository.DeleteByPostId(id, ctx)
+	countDeleted(request.PostEntity)
 
-	s.PostRepository.Delete(id, ctx)
+	if post.ImageId != 0 {
+		s.deleteImage(post.ImageId, ctx)
+	}
+
+	return nil
 }
 
-func (s PostService) transformListOfDAOToListOfDTO(posts []*entity.Post) []*response.PostDto {
+// transformListOfDAOToListOfDTO leaves out the posts and comments that are
+// held by moderation, unless viewerId wrote them.
+func (s PostService) transformListOfDAOToListOfDTO(posts []*entity.Post, viewerId uint) []*response.PostDto {
 	var postsDto = []*response.PostDto{}
 
 	for _, value := range posts {
+		if !value.VisibleTo(viewerId) {
+			continue
+		}
+
+		value.Comments = visibleComments(value.Comments, viewerId)
+
 		postDto := value.CreateDto()
 
 		postsDto = append(postsDto, postDto)
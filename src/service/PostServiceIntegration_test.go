@@ -9,6 +9,7 @@ import (
 	"posts-ms/src/client"
 	"posts-ms/src/dto/request"
 	"posts-ms/src/entity"
+	"posts-ms/src/migration"
 	"posts-ms/src/rabbitmq"
 	"posts-ms/src/repository"
 	"posts-ms/src/utils"
@@ -48,9 +49,9 @@ func (suite *PostServiceIntegrationTestSuite) SetupSuite() {
 
 	db, _ := gorm.Open(postgres.Open(connectionString), &gorm.Config{})
 
-	db.AutoMigrate(&entity.Like{Tbl: "likes"})
-	db.AutoMigrate(&entity.Comment{Tbl: "comments"})
-	db.AutoMigrate(&entity.Post{Tbl: "posts"})
+	migrator, _ := migration.NewMigrator(db, utils.Logger())
+
+	migrator.Up(context.Background())
 
 	likeRepository := repository.LikeRepository{Database: db}
 	commentRepository := repository.CommentRepository{Database: db}
@@ -72,8 +73,9 @@ func (suite *PostServiceIntegrationTestSuite) SetupSuite() {
 		LikeRepository:    likeRepository,
 		CommentRepository: commentRepository,
 		MediaClient:       mediaClient,
-		RabbitMQChannel:   channel,
+		MediaPublisher:    rabbitmq.MediaPublisher{Channel: channel},
 		PostRepository:    postRepository,
+		Moderator:         ModerationPipeline{},
 		Logger:            utils.Logger(),
 	}
 
@@ -209,7 +211,7 @@ func (suite *PostServiceIntegrationTestSuite) TestIntegrationPostService_GetPost
 func (suite *PostServiceIntegrationTestSuite) TestIntegrationPostService_GetAllByUserId_PostDoesNotExist() {
 	id := uint(99)
 
-	posts := suite.service.GetAllByUserId(id, context.TODO())
+	posts := suite.service.GetAllByUserId(id, 0, context.TODO())
 
 	assert.Equal(suite.T(), 0, len(posts))
 }
@@ -217,7 +219,7 @@ func (suite *PostServiceIntegrationTestSuite) TestIntegrationPostService_GetAllB
 func (suite *PostServiceIntegrationTestSuite) TestIntegrationPostService_GetAllByUserId_PostDoesExist() {
 	id := uint(8)
 
-	posts := suite.service.GetAllByUserId(id, context.TODO())
+	posts := suite.service.GetAllByUserId(id, 0, context.TODO())
 
 	assert.Equal(suite.T(), 1, len(posts))
 }
@@ -225,7 +227,7 @@ func (suite *PostServiceIntegrationTestSuite) TestIntegrationPostService_GetAllB
 func (suite *PostServiceIntegrationTestSuite) TestIntegrationPostService_GetAllByUserIds_PostDoesNotExist() {
 	ids := []uint{5, 6}
 
-	posts := suite.service.GetAllByUserIds(ids, context.TODO())
+	posts := suite.service.GetAllByUserIds(ids, 0, context.TODO())
 
 	assert.Equal(suite.T(), 0, len(posts))
 }
@@ -233,7 +235,7 @@ func (suite *PostServiceIntegrationTestSuite) TestIntegrationPostService_GetAllB
 func (suite *PostServiceIntegrationTestSuite) TestIntegrationPostService_GetAllByUserIds_PostDoesExist() {
 	ids := []uint{8, 6}
 
-	posts := suite.service.GetAllByUserIds(ids, context.TODO())
+	posts := suite.service.GetAllByUserIds(ids, 0, context.TODO())
 
 	assert.Equal(suite.T(), 1, len(posts))
 }
@@ -252,7 +254,7 @@ func (suite *PostServiceIntegrationTestSuite) TestIntegrationPostService_Delete_
 
 	suite.service.Delete(id, context.TODO())
 
-	posts := suite.service.GetAllByUserId(userId, context.TODO())
+	posts := suite.service.GetAllByUserId(userId, 0, context.TODO())
 
 	assert.Equal(suite.T(), 0, len(posts))
 	assert.True(suite.T(), true)
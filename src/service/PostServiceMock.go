@@ -2,7 +2,6 @@ package service
 
 import (
 	"context"
-	"errors"
 	"mime/multipart"
 	"posts-ms/src/dto/request"
 	"posts-ms/src/dto/response"
@@ -24,14 +23,14 @@ func (p PostServiceMock) CreatePost(entity.Post, context.Context) (*entity.Post,
 	return nil, nil
 }
 
-func (p PostServiceMock) Delete(uint, context.Context) {
-
+func (p PostServiceMock) Delete(uint, context.Context) error {
+	return nil
 }
 
 func (p PostServiceMock) GetById(id uint, ctx context.Context) (*response.PostDto, error) {
 	switch id {
 	case 1:
-		return nil, errors.New("")
+		return nil, NewNotFoundError("post %d does not exist", id)
 	}
 	return &response.PostDto{
 		Id:           1,
@@ -46,7 +45,16 @@ func (p PostServiceMock) GetById(id uint, ctx context.Context) (*response.PostDt
 func (p PostServiceMock) GetPostById(id uint, ctx context.Context) (*entity.Post, error) {
 	switch id {
 	case 1:
-		return nil, errors.New("")
+		return nil, NewNotFoundError("post %d does not exist", id)
+	case 4:
+		return &entity.Post{
+			Model: gorm.Model{
+				ID: 4,
+			},
+			Description:      "Some text",
+			UserId:           1,
+			ModerationStatus: entity.ModerationHeld,
+		}, nil
 	}
 	return &entity.Post{
 		Model: gorm.Model{
@@ -60,10 +68,14 @@ func (p PostServiceMock) GetPostById(id uint, ctx context.Context) (*entity.Post
 	}, nil
 }
 
-func (p PostServiceMock) GetAllByUserId(uint, context.Context) []*response.PostDto {
+func (p PostServiceMock) GetAllByUserId(uint, uint, context.Context) []*response.PostDto {
+	return nil
+}
+
+func (p PostServiceMock) GetAllByUserIds([]uint, uint, context.Context) []*response.PostDto {
 	return nil
 }
 
-func (p PostServiceMock) GetAllByUserIds([]uint, context.Context) []*response.PostDto {
+func (p PostServiceMock) HandleMediaProcessed(response.MediaProcessedDto, context.Context) error {
 	return nil
 }
@@ -2,23 +2,51 @@ package service
 
 import (
 	"context"
+	"errors"
 	"mime/multipart"
 	"net/textproto"
 	"posts-ms/src/client"
 	"posts-ms/src/dto/request"
+	"posts-ms/src/dto/response"
 	"posts-ms/src/entity"
+	"posts-ms/src/rabbitmq"
 	"posts-ms/src/repository"
 	"posts-ms/src/utils"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 )
 
+// recordingPostRepository remembers the posts saved and deleted through it.
+type recordingPostRepository struct {
+	*repository.PostRepositoryMock
+	saved   []entity.Post
+	deleted []uint
+}
+
+func (r *recordingPostRepository) Create(post entity.Post, ctx context.Context) (entity.Post, error) {
+	post, err := r.PostRepositoryMock.Create(post, ctx)
+
+	r.saved = append(r.saved, post)
+
+	return post, err
+}
+
+func (r *recordingPostRepository) Delete(id uint, ctx context.Context) error {
+	r.deleted = append(r.deleted, id)
+
+	return nil
+}
+
 type PostServiceUnitTestSuite struct {
 	suite.Suite
 	postRepositoryMock  *repository.PostRepositoryMock
+	likeRepositoryMock  *repository.LikeRepositoryMock
+	commentRepository   *repository.CommentRepositoryMock
 	mediaRestClientMock *client.MediaRestClientMock
+	mediaPublisherMock  *rabbitmq.MediaPublisherMock
 	service             PostService
 }
 
@@ -28,14 +56,24 @@ func TestPostServiceUnitTestSuite(t *testing.T) {
 
 func (suite *PostServiceUnitTestSuite) SetupSuite() {
 	suite.postRepositoryMock = new(repository.PostRepositoryMock)
+	suite.likeRepositoryMock = new(repository.LikeRepositoryMock)
+	suite.commentRepository = new(repository.CommentRepositoryMock)
 	suite.mediaRestClientMock = new(client.MediaRestClientMock)
 
 	suite.service = PostService{PostRepository: suite.postRepositoryMock,
-		MediaClient: suite.mediaRestClientMock,
-		Logger:      utils.Logger(),
+		LikeRepository:    suite.likeRepositoryMock,
+		CommentRepository: suite.commentRepository,
+		MediaClient:       suite.mediaRestClientMock,
+		Moderator:         testModerator(),
+		Logger:            utils.Logger(),
 	}
 }
 
+func (suite *PostServiceUnitTestSuite) SetupTest() {
+	suite.mediaPublisherMock = new(rabbitmq.MediaPublisherMock)
+	suite.service.MediaPublisher = suite.mediaPublisherMock
+}
+
 func (suite *PostServiceUnitTestSuite) TestNewPostService() {
 	assert.NotNil(suite.T(), suite.service, "Service is nil")
 }
@@ -54,32 +92,53 @@ func (suite *PostServiceUnitTestSuite) TestPostService_GetById_ReturnError() {
 	post, err := suite.service.GetById(1, context.TODO())
 
 	assert.NotNil(suite.T(), err, "Error is nil")
+	assert.True(suite.T(), errors.Is(err, ErrNotFound), "Error is not not found")
 	assert.Nil(suite.T(), post, "Post is not nil")
 }
 
 func (suite *PostServiceUnitTestSuite) TestPostService_GetAllByUserId_ReturnEmptyList() {
-	posts := suite.service.GetAllByUserId(1, context.TODO())
+	posts := suite.service.GetAllByUserId(1, 0, context.TODO())
 
 	assert.NotNil(suite.T(), posts, "Posts are nil")
 	assert.Equal(suite.T(), 0, len(posts), "Length of posts not 0")
 }
 
 func (suite *PostServiceUnitTestSuite) TestPostService_GetAllByUserId_ReturnListOfPosts() {
-	posts := suite.service.GetAllByUserId(2, context.TODO())
+	posts := suite.service.GetAllByUserId(2, 0, context.TODO())
 
 	assert.NotNil(suite.T(), posts, "Posts are nil")
 	assert.Equal(suite.T(), 2, len(posts), "Length of posts not 2")
 }
 
+func (suite *PostServiceUnitTestSuite) TestPostService_GetAllByUserId_HidesHeldContentFromOthers() {
+	posts := suite.service.GetAllByUserId(4, 0, context.TODO())
+
+	assert.Equal(suite.T(), 1, len(posts), "Held post is shown to anonymous viewer")
+	assert.Equal(suite.T(), 1, len(posts[0].Comments), "Held comment is shown to anonymous viewer")
+	assert.Equal(suite.T(), uint(6), posts[0].Comments[0].UserId, "Approved comment is hidden")
+}
+
+func (suite *PostServiceUnitTestSuite) TestPostService_GetAllByUserId_ShowsHeldContentToAuthor() {
+	posts := suite.service.GetAllByUserId(4, 4, context.TODO())
+
+	assert.Equal(suite.T(), 2, len(posts), "Held post is hidden from its author")
+	assert.Equal(suite.T(), "held", posts[1].ModerationStatus, "Moderation status is not held")
+
+	posts = suite.service.GetAllByUserId(4, 5, context.TODO())
+
+	assert.Equal(suite.T(), 1, len(posts), "Held post is shown to another user")
+	assert.Equal(suite.T(), 2, len(posts[0].Comments), "Held comment is hidden from its author")
+}
+
 func (suite *PostServiceUnitTestSuite) TestPostService_GetAllByUsersId_ReturnEmptyList() {
-	posts := suite.service.GetAllByUserIds([]uint{1, 2}, context.TODO())
+	posts := suite.service.GetAllByUserIds([]uint{1, 2}, 0, context.TODO())
 
 	assert.NotNil(suite.T(), posts, "Posts are nil")
 	assert.Equal(suite.T(), 0, len(posts), "Length of posts not 0")
 }
 
 func (suite *PostServiceUnitTestSuite) TestPostService_GetAllByUsersId_ReturnListOfPosts() {
-	posts := suite.service.GetAllByUserIds([]uint{2, 6}, context.TODO())
+	posts := suite.service.GetAllByUserIds([]uint{2, 6}, 0, context.TODO())
 
 	assert.NotNil(suite.T(), posts, "Posts are nil")
 	assert.Equal(suite.T(), 2, len(posts), "Length of posts not 2")
@@ -122,10 +181,174 @@ func (suite *PostServiceUnitTestSuite) TestPostService_Create_ReturnPost() {
 	assert.NotNil(suite.T(), newPost, "Posts are nil")
 	assert.Nil(suite.T(), err, "Error is not nil")
 	assert.Equal(suite.T(), id, newPost.Id, "Post id is not 2")
+	suite.mediaPublisherMock.AssertNotCalled(suite.T(), "DeleteImage", mock.Anything, mock.Anything)
+}
+
+func (suite *PostServiceUnitTestSuite) TestPostService_Create_HeldByModeration() {
+	newPost, err := suite.service.Create(request.PostDto{Description: "Cheap spam here", UserId: 1}, []*multipart.FileHeader{
+		{Header: textproto.MIMEHeader{}},
+	}, context.TODO())
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+	assert.Equal(suite.T(), "held", newPost.ModerationStatus, "Post is not held")
+}
+
+func (suite *PostServiceUnitTestSuite) TestPostService_Create_RejectedByModeration() {
+	newPost, err := suite.service.Create(request.PostDto{Description: "Forbidden text", UserId: 1}, []*multipart.FileHeader{
+		{Header: textproto.MIMEHeader{}},
+	}, context.TODO())
+
+	assert.Nil(suite.T(), newPost, "Post is not nil")
+	assert.True(suite.T(), errors.Is(err, ErrContentRejected), "Error is not content rejected")
+}
+
+func (suite *PostServiceUnitTestSuite) TestPostService_Create_DeletesImageWhenSavingFails() {
+	suite.mediaPublisherMock.On("DeleteImage", uint(1), mock.Anything).Return(nil)
+
+	post := request.PostDto{
+		Description: "Some text",
+		UserId:      3,
+	}
+
+	newPost, err := suite.service.Create(post, []*multipart.FileHeader{
+		{
+			Filename: "",
+			Header:   textproto.MIMEHeader{},
+			Size:     0,
+		},
+	}, context.TODO())
+
+	assert.NotNil(suite.T(), err, "Error is nil")
+	assert.Nil(suite.T(), newPost, "Post is not nil")
+	suite.mediaPublisherMock.AssertCalled(suite.T(), "DeleteImage", uint(1), mock.Anything)
+}
+
+func (suite *PostServiceUnitTestSuite) TestPostService_Create_ReturnsErrorWhenCompensationFails() {
+	suite.mediaPublisherMock.On("DeleteImage", uint(1), mock.Anything).Return(errors.New(""))
+
+	post := request.PostDto{
+		Description: "Some text",
+		UserId:      3,
+	}
+
+	newPost, err := suite.service.Create(post, []*multipart.FileHeader{
+		{
+			Filename: "",
+			Header:   textproto.MIMEHeader{},
+			Size:     0,
+		},
+	}, context.TODO())
+
+	assert.NotNil(suite.T(), err, "Error is nil")
+	assert.Nil(suite.T(), newPost, "Post is not nil")
+	suite.mediaPublisherMock.AssertNumberOfCalls(suite.T(), "DeleteImage", 1)
+}
+
+func (suite *PostServiceUnitTestSuite) TestPostService_Create_WithoutImage_ReturnsValidationError() {
+	newPost, err := suite.service.Create(request.PostDto{Description: "Some text", UserId: 1}, nil, context.TODO())
+
+	assert.True(suite.T(), errors.Is(err, ErrValidation), "Error is not validation error")
+	assert.Nil(suite.T(), newPost, "Post is not nil")
+}
+
+func (suite *PostServiceUnitTestSuite) TestPostService_Create_MediaUnavailable_ReturnsDependencyUnavailable() {
+	newPost, err := suite.service.Create(request.PostDto{Description: "Some text", UserId: 1}, []*multipart.FileHeader{
+		{
+			Filename: "unavailable",
+			Header:   textproto.MIMEHeader{},
+		},
+	}, context.TODO())
+
+	assert.True(suite.T(), errors.Is(err, ErrDependencyUnavailable), "Error is not dependency unavailable")
+	assert.Nil(suite.T(), newPost, "Post is not nil")
+}
+
+func (suite *PostServiceUnitTestSuite) TestPostService_Create_MediaEndpointMissing_ReturnsDependencyUnavailable() {
+	newPost, err := suite.service.Create(request.PostDto{Description: "Some text", UserId: 1}, []*multipart.FileHeader{
+		{
+			Filename: "missing",
+			Header:   textproto.MIMEHeader{},
+		},
+	}, context.TODO())
+
+	assert.True(suite.T(), errors.Is(err, ErrDependencyUnavailable), "Error is not dependency unavailable")
+	assert.False(suite.T(), errors.Is(err, ErrValidation), "Error is a validation error")
+	assert.Nil(suite.T(), newPost, "Post is not nil")
+}
+
+func (suite *PostServiceUnitTestSuite) asyncService() (PostService, *recordingPostRepository) {
+	posts := &recordingPostRepository{PostRepositoryMock: suite.postRepositoryMock}
+	service := suite.service
+
+	service.PostRepository = posts
+	service.AsyncMediaUpload = true
+
+	return service, posts
+}
+
+func (suite *PostServiceUnitTestSuite) TestPostService_CreateAsync_SavesPostAsProcessing() {
+	service, posts := suite.asyncService()
+
+	newPost, err := service.Create(request.PostDto{Description: "Some text", UserId: 1}, []*multipart.FileHeader{
+		{Header: textproto.MIMEHeader{}},
+	}, context.TODO())
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+	assert.Equal(suite.T(), "processing", newPost.Status, "Returned post is not processing")
+	assert.Equal(suite.T(), 1, len(posts.saved), "Post is not saved once")
+	assert.Equal(suite.T(), entity.MediaProcessing, posts.saved[0].MediaStatus, "Saved post is not processing")
+	assert.Equal(suite.T(), uint(0), posts.saved[0].ImageId, "Saved post has an image before media-ms processed it")
+	assert.Empty(suite.T(), posts.deleted, "Post is deleted")
+}
+
+func (suite *PostServiceUnitTestSuite) TestPostService_CreateAsync_MediaUnavailable_DeletesPost() {
+	service, posts := suite.asyncService()
+
+	newPost, err := service.Create(request.PostDto{Description: "Some text", UserId: 1}, []*multipart.FileHeader{
+		{Filename: "unavailable", Header: textproto.MIMEHeader{}},
+	}, context.TODO())
+
+	assert.True(suite.T(), errors.Is(err, ErrDependencyUnavailable), "Error is not dependency unavailable")
+	assert.Nil(suite.T(), newPost, "Post is not nil")
+	assert.Equal(suite.T(), []uint{1}, posts.deleted, "Post without media is not deleted")
+}
+
+func (suite *PostServiceUnitTestSuite) TestPostService_CreateAsync_SavingFails_ReturnsError() {
+	service, _ := suite.asyncService()
+
+	newPost, err := service.Create(request.PostDto{Description: "Some text", UserId: 3}, []*multipart.FileHeader{
+		{Header: textproto.MIMEHeader{}},
+	}, context.TODO())
+
+	assert.NotNil(suite.T(), err, "Error is nil")
+	assert.Nil(suite.T(), newPost, "Post is not nil")
+}
+
+func (suite *PostServiceUnitTestSuite) TestPostService_Delete_PostDoesNotExist_ReturnsNotFound() {
+	err := suite.service.Delete(1, context.TODO())
+
+	assert.True(suite.T(), errors.Is(err, ErrNotFound), "Error is not not found")
+	suite.mediaPublisherMock.AssertNotCalled(suite.T(), "DeleteImage", mock.Anything, mock.Anything)
+}
+
+func (suite *PostServiceUnitTestSuite) TestPostService_Delete_DeletesImage() {
+	suite.mediaPublisherMock.On("DeleteImage", uint(1), mock.Anything).Return(nil)
+
+	err := suite.service.Delete(3, context.TODO())
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+	suite.mediaPublisherMock.AssertCalled(suite.T(), "DeleteImage", uint(1), mock.Anything)
+}
+
+func (suite *PostServiceUnitTestSuite) TestPostService_Delete_DeletingCommentsFails_KeepsImage() {
+	err := suite.service.Delete(2, context.TODO())
+
+	assert.NotNil(suite.T(), err, "Error is nil")
+	suite.mediaPublisherMock.AssertNotCalled(suite.T(), "DeleteImage", mock.Anything, mock.Anything)
 }
 
 func (suite *PostServiceUnitTestSuite) TestPostService_TransformListOfDAOToListOfDTO_ReturnEmptyList() {
-	posts := suite.service.transformListOfDAOToListOfDTO([]*entity.Post{})
+	posts := suite.service.transformListOfDAOToListOfDTO([]*entity.Post{}, 0)
 
 	assert.NotNil(suite.T(), posts, "Posts are nil")
 	assert.Equal(suite.T(), 0, len(posts), "Length of posts not 0")
@@ -138,8 +361,40 @@ func (suite *PostServiceUnitTestSuite) TestPostService_TransformListOfDAOToListO
 		UserId:       1,
 		TotalLikes:   0,
 		TotalUnlikes: 0,
-	}})
+	}}, 0)
 
 	assert.NotNil(suite.T(), posts, "Posts are nil")
 	assert.Equal(suite.T(), 1, len(posts), "Length of posts not 1")
 }
+
+func (suite *PostServiceUnitTestSuite) TestPostService_HandleMediaProcessed_Successfully() {
+	err := suite.service.HandleMediaProcessed(response.MediaProcessedDto{
+		PostId:  2,
+		ImageId: 5,
+		Success: true,
+	}, context.TODO())
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+}
+
+func (suite *PostServiceUnitTestSuite) TestPostService_HandleMediaProcessed_PostDoesNotExist() {
+	err := suite.service.HandleMediaProcessed(response.MediaProcessedDto{
+		PostId:  1,
+		Success: false,
+		Error:   "Unsupported media type",
+	}, context.TODO())
+
+	assert.NotNil(suite.T(), err, "Error is nil")
+	assert.True(suite.T(), errors.Is(err, rabbitmq.ErrUnprocessable), "Message of a deleted post is not unprocessable")
+}
+
+func (suite *PostServiceUnitTestSuite) TestPostService_TransformListOfDAOToListOfDTO_ReturnMediaStatus() {
+	posts := suite.service.transformListOfDAOToListOfDTO([]*entity.Post{{
+		Description: "Some text",
+		UserId:      1,
+		MediaStatus: entity.MediaProcessing,
+	}}, 0)
+
+	assert.Equal(suite.T(), 1, len(posts), "Length of posts not 1")
+	assert.Equal(suite.T(), "processing", posts[0].Status, "Status is not processing")
+}
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RateLimit allows Requests requests per Per. Bursts of up to Requests
+// requests are allowed, after that tokens come back at an even pace. The
+// zero value does not limit anything.
+type RateLimit struct {
+	Requests int
+	Per      time.Duration
+}
+
+// RouteRateLimit limits a route separately per user and per client address.
+type RouteRateLimit struct {
+	User RateLimit
+	IP   RateLimit
+}
+
+// ParseRateLimit reads a limit written as <requests>/<duration>, for example
+// 30/1m.
+func ParseRateLimit(value string) (RateLimit, error) {
+	parts := strings.SplitN(value, "/", 2)
+
+	if len(parts) != 2 {
+		return RateLimit{}, fmt.Errorf("rate limit %q is not <requests>/<duration>", value)
+	}
+
+	requests, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+
+	if err != nil || requests <= 0 {
+		return RateLimit{}, fmt.Errorf("rate limit %q needs a positive number of requests", value)
+	}
+
+	per, err := time.ParseDuration(strings.TrimSpace(parts[1]))
+
+	if err != nil || per <= 0 {
+		return RateLimit{}, fmt.Errorf("rate limit %q needs a positive duration", value)
+	}
+
+	return RateLimit{Requests: requests, Per: per}, nil
+}
+
+func (l RateLimit) Enabled() bool {
+	return l.Requests > 0 && l.Per > 0
+}
+
+func (l RateLimit) String() string {
+	return fmt.Sprintf("%d/%s", l.Requests, l.Per)
+}
+
+// IRateLimiter keeps one token bucket per key. Take spends a token of the
+// bucket of key and, when none is left, returns false and how long the
+// caller has to wait for the next one. Implementations backed by a shared
+// store let several instances enforce the same limits.
+type IRateLimiter interface {
+	Take(string, RateLimit, context.Context) (bool, time.Duration, error)
+}
+
+type tokenBucket struct {
+	tokens  float64
+	updated time.Time
+	full    time.Time
+}
+
+// InMemoryRateLimiter keeps the buckets of a single instance. Buckets that
+// have filled up again are dropped, so memory follows the number of
+// recently active clients.
+type InMemoryRateLimiter struct {
+	mutex     sync.Mutex
+	buckets   map[string]*tokenBucket
+	now       func() time.Time
+	lastSweep time.Time
+}
+
+const rateLimiterSweepInterval = time.Minute
+
+func NewInMemoryRateLimiter() *InMemoryRateLimiter {
+	return &InMemoryRateLimiter{buckets: map[string]*tokenBucket{}, now: time.Now, lastSweep: time.Now()}
+}
+
+func (l *InMemoryRateLimiter) Take(key string, limit RateLimit, ctx context.Context) (bool, time.Duration, error) {
+	if !limit.Enabled() {
+		return true, 0, nil
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := l.now()
+
+	l.sweep(now)
+
+	capacity := float64(limit.Requests)
+	interval := limit.Per / time.Duration(limit.Requests)
+
+	bucket, ok := l.buckets[key]
+
+	if !ok {
+		bucket = &tokenBucket{tokens: capacity, updated: now}
+		l.buckets[key] = bucket
+	}
+
+	bucket.tokens = math.Min(capacity, bucket.tokens+float64(now.Sub(bucket.updated))/float64(interval))
+	bucket.updated = now
+
+	if bucket.tokens < 1 {
+		return false, time.Duration((1 - bucket.tokens) * float64(interval)), nil
+	}
+
+	bucket.tokens--
+	bucket.full = now.Add(time.Duration((capacity - bucket.tokens) * float64(interval)))
+
+	return true, 0, nil
+}
+
+func (l *InMemoryRateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < rateLimiterSweepInterval {
+		return
+	}
+
+	for key, bucket := range l.buckets {
+		if !now.Before(bucket.full) {
+			delete(l.buckets, key)
+		}
+	}
+
+	l.lastSweep = now
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type RateLimiterUnitTestSuite struct {
+	suite.Suite
+	now     time.Time
+	limiter *InMemoryRateLimiter
+}
+
+func TestRateLimiterUnitTestSuite(t *testing.T) {
+	suite.Run(t, new(RateLimiterUnitTestSuite))
+}
+
+func (suite *RateLimiterUnitTestSuite) SetupTest() {
+	suite.now = time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+	suite.limiter = NewInMemoryRateLimiter()
+	suite.limiter.now = func() time.Time { return suite.now }
+	suite.limiter.lastSweep = suite.now
+}
+
+func (suite *RateLimiterUnitTestSuite) take(key string, limit RateLimit) (bool, time.Duration) {
+	allowed, retryAfter, err := suite.limiter.Take(key, limit, context.TODO())
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+
+	return allowed, retryAfter
+}
+
+func (suite *RateLimiterUnitTestSuite) TestRateLimiter_Take_AllowsBurstThenRejects() {
+	limit := RateLimit{Requests: 3, Per: 3 * time.Second}
+
+	for i := 0; i < 3; i++ {
+		allowed, _ := suite.take("key", limit)
+
+		assert.True(suite.T(), allowed, "Request %d of the burst is rejected", i+1)
+	}
+
+	allowed, retryAfter := suite.take("key", limit)
+
+	assert.False(suite.T(), allowed, "Request over the limit is allowed")
+	assert.Equal(suite.T(), time.Second, retryAfter, "Retry after is not the time to the next token")
+}
+
+func (suite *RateLimiterUnitTestSuite) TestRateLimiter_Take_RefillsOverTime() {
+	limit := RateLimit{Requests: 2, Per: 2 * time.Second}
+
+	suite.take("key", limit)
+	suite.take("key", limit)
+
+	suite.now = suite.now.Add(500 * time.Millisecond)
+
+	allowed, retryAfter := suite.take("key", limit)
+
+	assert.False(suite.T(), allowed, "Request is allowed before a token is back")
+	assert.Equal(suite.T(), 500*time.Millisecond, retryAfter, "Retry after does not count the elapsed time")
+
+	suite.now = suite.now.Add(500 * time.Millisecond)
+
+	allowed, _ = suite.take("key", limit)
+
+	assert.True(suite.T(), allowed, "Request is rejected after a token is back")
+}
+
+func (suite *RateLimiterUnitTestSuite) TestRateLimiter_Take_KeysAreIndependent() {
+	limit := RateLimit{Requests: 1, Per: time.Minute}
+
+	suite.take("first", limit)
+
+	allowed, _ := suite.take("second", limit)
+
+	assert.True(suite.T(), allowed, "Bucket is shared between keys")
+}
+
+func (suite *RateLimiterUnitTestSuite) TestRateLimiter_Take_ZeroLimitAllowsEverything() {
+	for i := 0; i < 100; i++ {
+		allowed, _ := suite.take("key", RateLimit{})
+
+		assert.True(suite.T(), allowed, "Request without limit is rejected")
+	}
+
+	assert.Empty(suite.T(), suite.limiter.buckets, "Bucket is kept without limit")
+}
+
+func (suite *RateLimiterUnitTestSuite) TestRateLimiter_Take_DropsRefilledBuckets() {
+	limit := RateLimit{Requests: 2, Per: time.Minute}
+
+	suite.take("idle", limit)
+
+	suite.now = suite.now.Add(2 * time.Minute)
+
+	suite.take("active", limit)
+
+	assert.NotContains(suite.T(), suite.limiter.buckets, "idle", "Refilled bucket is not dropped")
+	assert.Contains(suite.T(), suite.limiter.buckets, "active", "Active bucket is dropped")
+}
+
+func (suite *RateLimiterUnitTestSuite) TestRateLimiter_ParseRateLimit() {
+	limit, err := ParseRateLimit("30/1m")
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+	assert.Equal(suite.T(), RateLimit{Requests: 30, Per: time.Minute}, limit, "Limit is not parsed")
+
+	for _, value := range []string{"30", "0/1m", "30/0s", "many/1m", "30/often"} {
+		_, err := ParseRateLimit(value)
+
+		assert.NotNil(suite.T(), err, "Rate limit %q is accepted", value)
+	}
+}
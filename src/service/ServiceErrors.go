@@ -0,0 +1,88 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"posts-ms/src/client"
+	"posts-ms/src/repository"
+
+	"gorm.io/gorm"
+)
+
+// Kinds of domain errors. Services return them wrapped in a DomainError and
+// callers test for them with errors.Is.
+var (
+	ErrNotFound              = errors.New("not found")
+	ErrConflict              = errors.New("conflict")
+	ErrValidation            = errors.New("validation failed")
+	ErrContentRejected       = errors.New("content rejected")
+	ErrDependencyUnavailable = errors.New("dependency unavailable")
+)
+
+// DomainError describes why a request could not be served. Detail is meant
+// for clients, Err keeps the underlying cause for logs.
+type DomainError struct {
+	Kind   error
+	Detail string
+	Err    error
+}
+
+func (e *DomainError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %s: %v", e.Kind, e.Detail, e.Err)
+	}
+
+	return fmt.Sprintf("%s: %s", e.Kind, e.Detail)
+}
+
+func (e *DomainError) Is(target error) bool {
+	return target == e.Kind
+}
+
+func (e *DomainError) Unwrap() error {
+	return e.Err
+}
+
+func NewNotFoundError(format string, args ...interface{}) error {
+	return &DomainError{Kind: ErrNotFound, Detail: fmt.Sprintf(format, args...)}
+}
+
+func NewConflictError(format string, args ...interface{}) error {
+	return &DomainError{Kind: ErrConflict, Detail: fmt.Sprintf(format, args...)}
+}
+
+func NewValidationError(format string, args ...interface{}) error {
+	return &DomainError{Kind: ErrValidation, Detail: fmt.Sprintf(format, args...)}
+}
+
+func NewContentRejectedError(format string, args ...interface{}) error {
+	return &DomainError{Kind: ErrContentRejected, Detail: fmt.Sprintf(format, args...)}
+}
+
+func NewDependencyUnavailableError(err error, format string, args ...interface{}) error {
+	return &DomainError{Kind: ErrDependencyUnavailable, Detail: fmt.Sprintf(format, args...), Err: err}
+}
+
+// lookupError turns a missing record into a NotFound error described by
+// format and leaves any other error as it is.
+func lookupError(err error, format string, args ...interface{}) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, repository.ErrPostDoesNotExist) {
+		return NewNotFoundError(format, args...)
+	}
+
+	return err
+}
+
+// clientError translates an error of a REST client of another service. A
+// 404 means the endpoint called is missing on the other service, which is not
+// the caller's fault.
+func clientError(err error, name string) error {
+	switch {
+	case errors.Is(err, client.ErrUnavailable), errors.Is(err, client.ErrNotFound):
+		return NewDependencyUnavailableError(err, "%s is unavailable", name)
+	case errors.Is(err, client.ErrRejected):
+		return &DomainError{Kind: ErrValidation, Detail: fmt.Sprintf("%s rejected the request", name), Err: err}
+	}
+
+	return err
+}
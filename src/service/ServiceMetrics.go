@@ -0,0 +1,31 @@
+package service
+
+import (
+	"posts-ms/src/dto/request"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var contentChanges = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "content_changes_total",
+	Help: "Total number of posts, likes and comments created and deleted.",
+}, []string{"entity", "action"})
+
+var notificationPublishFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "notification_publish_failures_total",
+	Help: "Total number of notifications that could not be published to RabbitMQ.",
+}, []string{"entity"})
+
+var moderationDecisions = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "moderation_decisions_total",
+	Help: "Total number of posts and comments allowed, held or rejected by moderation.",
+}, []string{"entity", "action"})
+
+func countCreated(entity string) {
+	contentChanges.WithLabelValues(entity, request.CreatedAction).Inc()
+}
+
+func countDeleted(entity string) {
+	contentChanges.WithLabelValues(entity, request.DeletedAction).Inc()
+}
@@ -1,25 +1,190 @@
 package utils
 
 import (
+	"context"
+	"fmt"
+	"io"
 	"os"
+	"sync"
 
 	"github.com/sirupsen/logrus"
 )
 
-func Logger() *logrus.Entry {
-	os.Mkdir("./logs", os.ModePerm)
+const serviceName = "posts-ms"
+
+const (
+	StdoutLogOutput = "stdout"
+	FileLogOutput   = "file"
+)
+
+// LogConfig selects the level and destination of the shared logger. A log
+// file is rotated once it grows past MaxSizeMB, keeping MaxBackups old files.
+type LogConfig struct {
+	Level      string `yaml:"level"`
+	Output     string `yaml:"output"`
+	File       string `yaml:"file"`
+	MaxSizeMB  int    `yaml:"maxSizeMb"`
+	MaxBackups int    `yaml:"maxBackups"`
+}
+
+func DefaultLogConfig() LogConfig {
+	return LogConfig{
+		Level:      "info",
+		Output:     StdoutLogOutput,
+		File:       "./logs/logs.log",
+		MaxSizeMB:  100,
+		MaxBackups: 5,
+	}
+}
 
-	file, _ := os.OpenFile("./logs/logs.log", os.O_RDWR|os.O_CREATE|os.O_APPEND, 0666)
+// shared is the logger every component writes to. It logs to stdout until
+// ConfigureLogger is called at startup.
+var shared = newLogger()
 
+func newLogger() *logrus.Logger {
 	logger := logrus.New()
 
 	logger.Formatter = &logrus.JSONFormatter{}
+	logger.Out = os.Stdout
+	logger.AddHook(contextHook{})
+
+	return logger
+}
+
+// ConfigureLogger applies cfg to the shared logger. The returned closer
+// switches the logger back to stdout and releases the log file.
+func ConfigureLogger(cfg LogConfig) (io.Closer, error) {
+	level, err := logrus.ParseLevel(cfg.Level)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var out io.WriteCloser
+
+	switch cfg.Output {
+	case StdoutLogOutput:
+		out = nopCloser{os.Stdout}
+	case FileLogOutput:
+		out, err = newRotatingFile(cfg.File, int64(cfg.MaxSizeMB)*1024*1024, cfg.MaxBackups)
+
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown log output %q", cfg.Output)
+	}
+
+	shared.SetOutput(out)
+	shared.SetLevel(level)
+
+	return closerFunc(func() error {
+		shared.SetOutput(os.Stdout)
+
+		return out.Close()
+	}), nil
+}
+
+// Logger returns an entry of the shared logger. Entries created with
+// WithContext are enriched with the request, trace and user ids of the
+// context.
+func Logger() *logrus.Entry {
+	return shared.WithField("service", serviceName)
+}
+
+type closerFunc func() error
+
+func (f closerFunc) Close() error {
+	return f()
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error {
+	return nil
+}
+
+// RequestIdHeader carries the id of a request between services.
+const RequestIdHeader = "X-Request-ID"
+
+type requestInfoKey struct{}
+
+// RequestInfo describes the request a context belongs to. It is stored by
+// pointer so that a handler can add the user id once it has read the body.
+type RequestInfo struct {
+	RequestId string
+	Route     string
+	mutex     sync.Mutex
+	userId    uint
+}
+
+func (i *RequestInfo) SetUserId(userId uint) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	i.userId = userId
+}
+
+func (i *RequestInfo) UserId() uint {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	return i.userId
+}
+
+func WithRequestInfo(ctx context.Context, info *RequestInfo) context.Context {
+	return context.WithValue(ctx, requestInfoKey{}, info)
+}
+
+// RequestInfoFrom returns the request info stored in ctx, or nil.
+func RequestInfoFrom(ctx context.Context) *RequestInfo {
+	info, _ := ctx.Value(requestInfoKey{}).(*RequestInfo)
+
+	return info
+}
+
+// SetUserId records the user a request acts for, when ctx belongs to one.
+func SetUserId(ctx context.Context, userId uint) {
+	if info := RequestInfoFrom(ctx); info != nil {
+		info.SetUserId(userId)
+	}
+}
+
+// RequestId returns the id of the request ctx belongs to, or an empty string.
+func RequestId(ctx context.Context) string {
+	if info := RequestInfoFrom(ctx); info != nil {
+		return info.RequestId
+	}
+
+	return ""
+}
+
+// contextHook adds the ids found in the context of an entry to its fields.
+type contextHook struct{}
+
+func (contextHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (contextHook) Fire(entry *logrus.Entry) error {
+	if entry.Context == nil {
+		return nil
+	}
+
+	if info := RequestInfoFrom(entry.Context); info != nil {
+		entry.Data["requestId"] = info.RequestId
+		entry.Data["route"] = info.Route
 
-	logger.Out = file
+		if userId := info.UserId(); userId != 0 {
+			entry.Data["userId"] = userId
+		}
+	}
 
-	contextLogger := logger.WithFields(logrus.Fields{
-		"service": os.Getenv("DATABASE_SCHEMA"),
-	})
+	if traceId := TraceId(entry.Context); traceId != "" {
+		entry.Data["traceId"] = traceId
+	}
 
-	return contextLogger
+	return nil
 }
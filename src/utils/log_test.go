@@ -0,0 +1,125 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/uber/jaeger-client-go"
+)
+
+type LogUnitTestSuite struct {
+	suite.Suite
+	file string
+}
+
+func TestLogUnitTestSuite(t *testing.T) {
+	suite.Run(t, new(LogUnitTestSuite))
+}
+
+func (suite *LogUnitTestSuite) SetupTest() {
+	suite.file = filepath.Join(suite.T().TempDir(), "logs", "logs.log")
+}
+
+func (suite *LogUnitTestSuite) readLines(path string) []map[string]interface{} {
+	content, err := ioutil.ReadFile(path)
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+
+	lines := []map[string]interface{}{}
+
+	for _, line := range strings.Split(strings.TrimSpace(string(content)), "\n") {
+		fields := map[string]interface{}{}
+
+		json.Unmarshal([]byte(line), &fields)
+
+		lines = append(lines, fields)
+	}
+
+	return lines
+}
+
+func (suite *LogUnitTestSuite) TestLog_ContextFieldsAreAdded() {
+	closer, err := ConfigureLogger(LogConfig{Level: "info", Output: FileLogOutput, File: suite.file, MaxSizeMB: 1})
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+
+	tracer, tracerCloser := jaeger.NewTracer("posts-ms", jaeger.NewConstSampler(true), jaeger.NewNullReporter())
+
+	defer tracerCloser.Close()
+
+	span := tracer.StartSpan("HTTP POST /api/likes")
+
+	defer span.Finish()
+
+	info := &RequestInfo{RequestId: "request-1", Route: "/api/likes"}
+	ctx := opentracing.ContextWithSpan(WithRequestInfo(context.Background(), info), span)
+
+	SetUserId(ctx, 42)
+
+	Logger().WithContext(ctx).Info("Creating like")
+
+	closer.Close()
+
+	lines := suite.readLines(suite.file)
+
+	assert.Equal(suite.T(), 1, len(lines), "Length of lines not 1")
+	assert.Equal(suite.T(), "request-1", lines[0]["requestId"], "Request id is not logged")
+	assert.Equal(suite.T(), "/api/likes", lines[0]["route"], "Route is not logged")
+	assert.Equal(suite.T(), float64(42), lines[0]["userId"], "User id is not logged")
+	assert.Equal(suite.T(), span.Context().(jaeger.SpanContext).TraceID().String(), lines[0]["traceId"], "Trace id is not logged")
+	assert.Equal(suite.T(), "posts-ms", lines[0]["service"], "Service is not logged")
+}
+
+func (suite *LogUnitTestSuite) TestLog_LevelIsApplied() {
+	closer, err := ConfigureLogger(LogConfig{Level: "warn", Output: FileLogOutput, File: suite.file, MaxSizeMB: 1})
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+
+	Logger().Info("Creating like")
+	Logger().Warn("Like already exists")
+
+	closer.Close()
+
+	lines := suite.readLines(suite.file)
+
+	assert.Equal(suite.T(), 1, len(lines), "Length of lines not 1")
+	assert.Equal(suite.T(), "Like already exists", lines[0]["msg"], "Info line is logged")
+}
+
+func (suite *LogUnitTestSuite) TestLog_UnknownOutput() {
+	_, err := ConfigureLogger(LogConfig{Level: "info", Output: "syslog"})
+
+	assert.NotNil(suite.T(), err, "Error is nil")
+}
+
+func (suite *LogUnitTestSuite) TestRotatingFile_RotatesAndKeepsBackups() {
+	file, err := newRotatingFile(suite.file, 10, 2)
+
+	assert.Nil(suite.T(), err, "Error is not nil")
+
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		file.Write([]byte(line))
+	}
+
+	file.Close()
+
+	current, _ := ioutil.ReadFile(suite.file)
+	first, _ := ioutil.ReadFile(suite.file + ".1")
+	second, _ := ioutil.ReadFile(suite.file + ".2")
+
+	assert.Equal(suite.T(), "fourth\n", string(current), "Current file does not match")
+	assert.Equal(suite.T(), "third\n", string(first), "First backup does not match")
+	assert.Equal(suite.T(), "second\n", string(second), "Second backup does not match")
+
+	_, err = os.Stat(suite.file + ".3")
+
+	assert.True(suite.T(), os.IsNotExist(err), "Too many backups are kept")
+}
@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/uber/jaeger-client-go"
+)
+
+// TraceId returns the id of the trace the span in ctx belongs to, or an empty
+// string when ctx carries no span. Tracers other than Jaeger are asked to
+// inject their context and the id is read from the uber-trace-id or
+// traceparent header.
+func TraceId(ctx context.Context) string {
+	span := opentracing.SpanFromContext(ctx)
+
+	if span == nil {
+		return ""
+	}
+
+	if spanContext, ok := span.Context().(jaeger.SpanContext); ok {
+		return spanContext.TraceID().String()
+	}
+
+	headers := http.Header{}
+
+	if err := span.Tracer().Inject(span.Context(), opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(headers)); err != nil {
+		return ""
+	}
+
+	if value, err := url.QueryUnescape(headers.Get("uber-trace-id")); err == nil && value != "" {
+		return strings.Split(value, ":")[0]
+	}
+
+	if parts := strings.Split(headers.Get("traceparent"), "-"); len(parts) == 4 {
+		return parts[1]
+	}
+
+	return ""
+}